@@ -3,7 +3,6 @@ package agent
 import (
 	"context"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
@@ -12,6 +11,7 @@ import (
 
 	"github.com/trento-project/trento/agent/discovery"
 	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/agent/metrics"
 	"github.com/trento-project/trento/internal"
 )
 
@@ -20,54 +20,108 @@ const trentoAgentCheckId = "trentoAgent"
 type Agent struct {
 	config          *Config
 	collectorClient collector.Client
-	discoveries     []discovery.Discovery
-	ctx             context.Context
-	ctxCancel       context.CancelFunc
+	commandsClient  collector.CommandsClient
+	discoveries     discovery.Registry
+	status          *discoveriesStatus
+	// Guards the configuration, the clients and the discoveries replaced when reloading
+	mutex sync.RWMutex
+	// Restarts the discovery loops with the reloaded discoveries
+	rescheduled chan struct{}
+	ctx         context.Context
+	ctxCancel   context.CancelFunc
 }
 
 type Config struct {
-	InstanceName    string
-	SSHAddress      string
-	DiscoveryPeriod time.Duration
-	CollectorConfig *collector.Config
+	InstanceName      string
+	SSHAddress        string
+	DiscoveriesConfig discovery.DiscoveriesConfig
+	CollectorConfig   *collector.Config
+	// Interval after which unchanged discovery payloads are published anyway
+	RefreshInterval time.Duration
+	// Directory where the data the collector could not receive is spooled, an empty one disables the spool
+	SpoolDir string
+	// Maximum size in bytes of the spool
+	SpoolMaxSize int64
+	// Systemd units whose state is discovered
+	SystemdUnits []string
+	// Address of the local listener serving the agent metrics and status, an empty one disables it
+	StatusAddress string
 }
 
 // NewAgent returns a new instance of Agent with the given configuration
 func NewAgent(config *Config) (*Agent, error) {
-	collectorClient, err := collector.NewCollectorClient(config.CollectorConfig)
+	collectorClient, commandsClient, err := newCollectorClients(config)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not create a collector client")
+		return nil, err
 	}
 
+	agent := NewAgentWithCollectorClient(config, collectorClient)
+	agent.commandsClient = commandsClient
+	if len(agent.discoveries) == 0 {
+		log.Warn("No discovery is enabled, the agent will only send heartbeats")
+	}
+
+	return agent, nil
+}
+
+// newCollectorClients returns the client publishing the discovered data, spooling and deduplicating it,
+// and the one picking up the commands from the collector
+func newCollectorClients(config *Config) (collector.Client, collector.CommandsClient, error) {
+	httpCollectorClient, err := collector.NewCollectorClient(config.CollectorConfig)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not create a collector client")
+	}
+
+	var publishingClient collector.Client = httpCollectorClient
+	if config.SpoolDir != "" {
+		spoolingClient, err := collector.NewSpoolingClient(httpCollectorClient, config.SpoolDir, config.SpoolMaxSize)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not create the spool")
+		}
+		publishingClient = spoolingClient
+	}
+
+	collectorClient := collector.NewDeduplicatingClient(publishingClient, config.RefreshInterval)
+
+	return collectorClient, httpCollectorClient, nil
+}
+
+// NewAgentWithCollectorClient returns a new instance of Agent sending the discovered data to the given client.
+// The collector configuration is ignored
+func NewAgentWithCollectorClient(config *Config, collectorClient collector.Client) *Agent {
 	ctx, ctxCancel := context.WithCancel(context.Background())
-	agent := &Agent{
+	discoveries := discovery.NewRegistry(collectorClient, config.SSHAddress, config.SystemdUnits, config.DiscoveriesConfig)
+	return &Agent{
 		config:          config,
 		collectorClient: collectorClient,
 		ctx:             ctx,
 		ctxCancel:       ctxCancel,
-		discoveries: []discovery.Discovery{
-			discovery.NewClusterDiscovery(collectorClient),
-			discovery.NewSAPSystemsDiscovery(collectorClient),
-			discovery.NewCloudDiscovery(collectorClient),
-			discovery.NewSubscriptionDiscovery(collectorClient),
-			discovery.NewHostDiscovery(config.SSHAddress, collectorClient),
-		},
+		discoveries:     discoveries,
+		status:          newDiscoveriesStatus(discoveries),
+		rescheduled:     make(chan struct{}, 1),
 	}
-	return agent, nil
 }
 
-// Start the Agent. This will start the discovery ticker and the heartbeat ticker
+// Start the Agent. This will start one discovery ticker per enabled discovery and the heartbeat ticker
 func (a *Agent) Start() error {
 	var wg sync.WaitGroup
 
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
-		log.Info("Starting Discover loop...")
 		defer wg.Done()
-		a.startDiscoverTicker()
-		log.Info("Discover loop stopped.")
+		a.startDiscoveries()
 	}(&wg)
 
+	if statusAddress := a.getConfig().StatusAddress; statusAddress != "" {
+		wg.Add(1)
+		go func(wg *sync.WaitGroup) {
+			log.Infof("Serving the agent metrics and status on %s...", statusAddress)
+			defer wg.Done()
+			a.startStatusServer()
+			log.Info("status listener stopped.")
+		}(&wg)
+	}
+
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
 		log.Info("Starting heartbeat loop...")
@@ -85,33 +139,118 @@ func (a *Agent) Stop() {
 	a.ctxCancel()
 }
 
-// Start a Ticker loop that will iterate over the hardcoded list of Discovery backends and execute them.
-func (a *Agent) startDiscoverTicker() {
+// DiscoverOnce executes every enabled discovery once, one after the other.
+// It returns an error if any of them fails
+func (a *Agent) DiscoverOnce() error {
+	failed := 0
+	for _, d := range a.discoveries {
+		result, err := discovery.RunWithTimeout(a.ctx, d)
+		if err != nil {
+			log.Errorf("Error while running discovery '%s': %s", d.GetId(), err)
+			failed++
+			continue
+		}
+		log.Infof("Discovery %s output: %s", d.GetId(), result)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d discoveries failed", failed, len(a.discoveries))
+	}
+
+	return nil
+}
+
+// startDiscoveries runs the loops of the enabled discoveries until the agent is stopped,
+// restarting them when the configuration is reloaded
+func (a *Agent) startDiscoveries() {
+	for {
+		var wg sync.WaitGroup
+		ctx, cancel := context.WithCancel(a.ctx)
+
+		for _, d := range a.getDiscoveries() {
+			wg.Add(1)
+			go func(wg *sync.WaitGroup, d discovery.Discovery) {
+				log.Infof("Starting %s loop...", d.GetId())
+				defer wg.Done()
+				a.startDiscoverTicker(ctx, d)
+				log.Infof("%s loop stopped.", d.GetId())
+			}(&wg, d)
+		}
+
+		select {
+		case <-a.ctx.Done():
+			cancel()
+			wg.Wait()
+			return
+		case <-a.rescheduled:
+			cancel()
+			wg.Wait()
+		}
+	}
+}
+
+// Start a Ticker loop that will execute the given Discovery backend at its own interval.
+// Every discovery runs in its own loop, so a slow discovery doesn't delay the others.
+func (a *Agent) startDiscoverTicker(ctx context.Context, d discovery.Discovery) {
 	tick := func() {
-		var output []string
-		for _, d := range a.discoveries {
-			result, err := d.Discover()
-			if err != nil {
-				result = fmt.Sprintf("Error while running discovery '%s': %s", d.GetId(), err)
-
-				log.Errorln(result)
-			}
-			output = append(output, result)
+		startedAt := time.Now()
+		result, err := discovery.RunWithTimeout(ctx, d)
+		if ctx.Err() == context.Canceled {
+			// Interrupted by a reload or by the agent stopping, it's not an outcome of the discovery
+			return
+		}
+		a.status.record(d.GetId(), startedAt, result, err)
+		if errors.Is(err, discovery.ErrDiscoveryTimeout) {
+			log.Errorf("Discovery '%s' timed out: %s", d.GetId(), err)
+			return
+		}
+		if err != nil {
+			log.Errorf("Error while running discovery '%s': %s", d.GetId(), err)
+			return
 		}
-		log.Infof("Discovery tick output: %s", strings.Join(output, "\n\n"))
+		log.Infof("Discovery %s tick output: %s", d.GetId(), result)
 	}
 
-	interval := a.config.DiscoveryPeriod
-	internal.Repeat("agent.discovery", tick, interval, a.ctx)
+	operation := fmt.Sprintf("agent.discovery.%s", d.GetId())
+	internal.Repeat(operation, tick, d.GetInterval(), ctx)
 }
 
 func (a *Agent) startHeartbeatTicker() {
 	tick := func() {
-		err := a.collectorClient.Heartbeat()
+		collectorClient, commandsClient := a.getCollectorClients()
+
+		err := collectorClient.Heartbeat(a.ctx)
 		if err != nil {
+			metrics.HeartbeatFailures.Inc()
 			log.Errorf("Error while sending the heartbeat to the server: %s", err)
+			return
+		}
+
+		if commandsClient != nil {
+			a.pickUpCommands(commandsClient)
 		}
 	}
 
 	internal.Repeat("agent.heartbeat", tick, internal.HeartbeatInterval, a.ctx)
 }
+
+func (a *Agent) getDiscoveries() discovery.Registry {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.discoveries
+}
+
+func (a *Agent) getCollectorClients() (collector.Client, collector.CommandsClient) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.collectorClient, a.commandsClient
+}
+
+func (a *Agent) getConfig() *Config {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.config
+}
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/agent/discovery/collector"
+)
+
+// pickUpCommands runs the commands queued by the server, each one in its own goroutine
+// so a slow discovery doesn't delay the heartbeat
+func (a *Agent) pickUpCommands(commandsClient collector.CommandsClient) {
+	commands, err := commandsClient.PickUpCommands(a.ctx)
+	if err != nil {
+		log.Errorf("Error while picking up the commands from the server: %s", err)
+		return
+	}
+
+	for _, command := range commands {
+		go a.runCommand(commandsClient, command)
+	}
+}
+
+// runCommand executes the command and reports its outcome to the server
+func (a *Agent) runCommand(commandsClient collector.CommandsClient, command *collector.Command) {
+	log.Infof("Running command %s: %s %s", command.ID, command.Type, command.DiscoveryType)
+
+	var err error
+	switch command.Type {
+	case collector.RunDiscoveryCommand:
+		err = a.runDiscoveryNow(command.DiscoveryType)
+	default:
+		err = fmt.Errorf("unknown command type %s", command.Type)
+	}
+
+	if err != nil {
+		log.Errorf("Command %s failed: %s", command.ID, err)
+	}
+
+	if err := commandsClient.CompleteCommand(a.ctx, command.ID, err); err != nil {
+		log.Errorf("Error while completing the command %s: %s", command.ID, err)
+	}
+}
+
+// runDiscoveryNow runs an enabled discovery out of its schedule
+func (a *Agent) runDiscoveryNow(discoveryID string) error {
+	for _, d := range a.getDiscoveries() {
+		if d.GetId() != discoveryID {
+			continue
+		}
+
+		startedAt := time.Now()
+		result, err := discovery.RunWithTimeout(a.ctx, d)
+		a.status.record(d.GetId(), startedAt, result, err)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Discovery %s on demand output: %s", d.GetId(), result)
+		return nil
+	}
+
+	return fmt.Errorf("discovery %s is not enabled", discoveryID)
+}
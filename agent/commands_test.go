@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/agent/discovery/collector"
+)
+
+func newCommandsTestAgent(commandsClient collector.CommandsClient) *Agent {
+	discoveries := discovery.Registry{
+		fakeDiscovery{id: "host_discovery"},
+		fakeDiscovery{id: "cloud_discovery", err: errors.New("cloud metadata unreachable")},
+	}
+
+	return &Agent{
+		config:         &Config{},
+		commandsClient: commandsClient,
+		discoveries:    discoveries,
+		status:         newDiscoveriesStatus(discoveries),
+		ctx:            context.Background(),
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	commandsClient := new(collector.MockCommandsClient)
+	commandsClient.On("CompleteCommand", mock.Anything, "succeeding", nil).Return(nil)
+	commandsClient.On("CompleteCommand", mock.Anything, "failing", errorWithMessage("cloud metadata unreachable")).Return(nil)
+	commandsClient.On("CompleteCommand", mock.Anything, "disabled", errorWithMessage("discovery sap_system_discovery is not enabled")).Return(nil)
+	commandsClient.On("CompleteCommand", mock.Anything, "unknown", errorWithMessage("unknown command type reboot")).Return(nil)
+
+	a := newCommandsTestAgent(commandsClient)
+
+	a.runCommand(commandsClient, &collector.Command{ID: "succeeding", Type: collector.RunDiscoveryCommand, DiscoveryType: "host_discovery"})
+	a.runCommand(commandsClient, &collector.Command{ID: "failing", Type: collector.RunDiscoveryCommand, DiscoveryType: "cloud_discovery"})
+	a.runCommand(commandsClient, &collector.Command{ID: "disabled", Type: collector.RunDiscoveryCommand, DiscoveryType: "sap_system_discovery"})
+	a.runCommand(commandsClient, &collector.Command{ID: "unknown", Type: "reboot"})
+
+	commandsClient.AssertExpectations(t)
+
+	statuses := a.status.list()
+	assert.Equal(t, "cloud_discovery", statuses[0].ID)
+	assert.Equal(t, "cloud metadata unreachable", statuses[0].LastError)
+	assert.Equal(t, "host_discovery", statuses[1].ID)
+	assert.Equal(t, "discovered", statuses[1].LastResult)
+}
+
+func TestPickUpCommandsFailure(t *testing.T) {
+	commandsClient := new(collector.MockCommandsClient)
+	commandsClient.On("PickUpCommands", mock.Anything).Return(nil, errors.New("connection refused"))
+
+	a := newCommandsTestAgent(commandsClient)
+	a.pickUpCommands(commandsClient)
+
+	commandsClient.AssertExpectations(t)
+	commandsClient.AssertNotCalled(t, "CompleteCommand", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func errorWithMessage(message string) interface{} {
+	return mock.MatchedBy(func(err error) bool {
+		return err != nil && err.Error() == message
+	})
+}
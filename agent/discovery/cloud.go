@@ -1,7 +1,9 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/agent/discovery/collector"
@@ -15,10 +17,10 @@ type CloudDiscovery struct {
 	discovery BaseDiscovery
 }
 
-func NewCloudDiscovery(collectorClient collector.Client) CloudDiscovery {
+func NewCloudDiscovery(collectorClient collector.Client, config DiscoveryConfig) CloudDiscovery {
 	r := CloudDiscovery{}
 	r.id = CloudDiscoveryId
-	r.discovery = NewDiscovery(collectorClient)
+	r.discovery = NewDiscovery(collectorClient, config)
 	return r
 }
 
@@ -26,13 +28,21 @@ func (d CloudDiscovery) GetId() string {
 	return d.id
 }
 
-func (d CloudDiscovery) Discover() (string, error) {
-	cloudData, err := cloud.NewCloudInstance()
+func (d CloudDiscovery) GetInterval() time.Duration {
+	return d.discovery.interval
+}
+
+func (d CloudDiscovery) GetTimeout() time.Duration {
+	return d.discovery.timeout
+}
+
+func (d CloudDiscovery) Discover(ctx context.Context) (string, error) {
+	cloudData, err := cloud.NewCloudInstance(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	err = d.discovery.collectorClient.Publish(d.id, cloudData)
+	err = d.discovery.collectorClient.Publish(ctx, d.id, cloudData)
 	if err != nil {
 		log.Debugf("Error while sending cloud discovery to data collector: %s", err)
 		return "", err
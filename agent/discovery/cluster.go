@@ -1,7 +1,9 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/agent/discovery/collector"
@@ -16,10 +18,10 @@ type ClusterDiscovery struct {
 	discovery BaseDiscovery
 }
 
-func NewClusterDiscovery(collectorClient collector.Client) ClusterDiscovery {
+func NewClusterDiscovery(collectorClient collector.Client, config DiscoveryConfig) ClusterDiscovery {
 	d := ClusterDiscovery{}
 	d.id = ClusterDiscoveryId
-	d.discovery = NewDiscovery(collectorClient)
+	d.discovery = NewDiscovery(collectorClient, config)
 	return d
 }
 
@@ -27,14 +29,22 @@ func (c ClusterDiscovery) GetId() string {
 	return c.id
 }
 
+func (c ClusterDiscovery) GetInterval() time.Duration {
+	return c.discovery.interval
+}
+
+func (c ClusterDiscovery) GetTimeout() time.Duration {
+	return c.discovery.timeout
+}
+
 // Execute one iteration of a discovery and publish the results to the collector
-func (d ClusterDiscovery) Discover() (string, error) {
-	cluster, err := cluster.NewCluster()
+func (d ClusterDiscovery) Discover(ctx context.Context) (string, error) {
+	cluster, err := cluster.NewCluster(ctx)
 	if err != nil {
 		return "No HA cluster discovered on this host", nil
 	}
 
-	err = d.discovery.collectorClient.Publish(d.id, cluster)
+	err = d.discovery.collectorClient.Publish(ctx, d.id, cluster)
 	if err != nil {
 		log.Debugf("Error while sending cluster discovery to data collector: %s", err)
 		return "", err
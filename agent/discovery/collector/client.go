@@ -2,143 +2,535 @@ package collector
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/agent/metrics"
 	"github.com/trento-project/trento/internal"
 
 	"github.com/spf13/afero"
 )
 
+//go:generate mockery --name=Client --inpackage --filename=client_mock.go
 type Client interface {
-	Publish(discoveryType string, payload interface{}) error
-	Heartbeat() error
+	Publish(ctx context.Context, discoveryType string, payload interface{}) error
+	Heartbeat(ctx context.Context) error
+}
+
+//go:generate mockery --name=TimestampedClient --inpackage --filename=timestamped_client_mock.go
+
+// TimestampedClient is a Client able to deliver data collected in the past,
+// letting the server know when it was originally collected
+type TimestampedClient interface {
+	Client
+	PublishAt(ctx context.Context, discoveryType string, payload interface{}, discoveredAt time.Time) error
+	HeartbeatAt(ctx context.Context, timestamp time.Time) error
+}
+
+//go:generate mockery --name=CommandsClient --inpackage --filename=commands_client_mock.go
+
+// CommandsClient picks up the commands the server queued for the agent, reporting their outcome
+type CommandsClient interface {
+	PickUpCommands(ctx context.Context) ([]*Command, error)
+	CompleteCommand(ctx context.Context, id string, commandErr error) error
+}
+
+// RunDiscoveryCommand asks the agent to run a discovery right away
+const RunDiscoveryCommand = "run_discovery"
+
+// Command is queued by the server for the agent, which picks it up after its heartbeat
+type Command struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	DiscoveryType string `json:"discovery_type"`
 }
 
 type client struct {
 	config     *Config
 	agentID    string
 	httpClient *http.Client
+	// Set to 1 when the collector accepts gzip compressed requests
+	compressionSupported int32
+	// The credential the agent authenticates with, obtained by enrolling
+	credential string
+	// Set when the collector rejected the credential, until the agent enrolls again or the credential is accepted
+	credentialRejected bool
+	credentialMu       sync.Mutex
 }
 
 type Config struct {
 	CollectorHost string
 	CollectorPort int
 	EnablemTLS    bool
-	Cert          string
-	Key           string
-	CA            string
+	// Connect over TLS verifying the collector certificate with the CA, without a client certificate
+	CollectorTLS bool
+	Cert         string
+	Key          string
+	CA           string
+	// Token exchanged for the agent credential when the agent is not enrolled yet
+	EnrollmentToken string
+	// File where the agent credential is kept, an empty one keeps it in memory only
+	CredentialFile string
+	// Agent ID overriding the one derived from the machine ID, e.g. on hosts cloned with the same machine ID
+	AgentID string
 }
 
 const machineIdPath = "/etc/machine-id"
 
+// requestTimeout bounds every request to the collector, so a collector accepting the connection
+// without ever responding can't stall the agent
+const requestTimeout = time.Minute
+
 var fileSystem = afero.NewOsFs()
 
 func NewCollectorClient(config *Config) (*client, error) {
 	var tlsConfig *tls.Config
 	var err error
 
-	if config.EnablemTLS {
+	switch {
+	case config.EnablemTLS:
 		tlsConfig, err = getTLSConfig(config.Cert, config.Key, config.CA)
 		if err != nil {
 			return nil, err
 		}
+	case config.CollectorTLS:
+		tlsConfig, err = getServerTLSConfig(config.CA)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	httpClient := &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: tlsConfig,
 		},
+		Timeout: requestTimeout,
 	}
 
-	machineIDBytes, err := afero.ReadFile(fileSystem, machineIdPath)
-
+	agentID, err := getAgentID(config)
 	if err != nil {
 		return nil, err
 	}
 
-	machineID := strings.TrimSpace(string(machineIDBytes))
+	var credential string
+	if config.CredentialFile != "" {
+		credentialBytes, err := afero.ReadFile(fileSystem, config.CredentialFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		credential = strings.TrimSpace(string(credentialBytes))
+	}
 
-	agentID := uuid.NewSHA1(internal.TrentoNamespace, []byte(machineID))
+	if credential != "" && !config.EnablemTLS && !config.CollectorTLS {
+		return nil, fmt.Errorf("the agent credential in %s can't be sent over plain HTTP, enable collector-tls or mTLS", config.CredentialFile)
+	}
 
 	return &client{
 		config:     config,
 		httpClient: httpClient,
-		agentID:    agentID.String(),
+		agentID:    agentID,
+		credential: credential,
 	}, nil
 }
 
-func (c *client) Publish(discoveryType string, payload interface{}) error {
+// getAgentID returns the configured agent ID, or the one derived from the machine ID when none is set
+func getAgentID(config *Config) (string, error) {
+	if config.AgentID != "" {
+		return config.AgentID, nil
+	}
+
+	machineIDBytes, err := afero.ReadFile(fileSystem, machineIdPath)
+	if err != nil {
+		return "", err
+	}
+
+	machineID := strings.TrimSpace(string(machineIDBytes))
+
+	return uuid.NewSHA1(internal.TrentoNamespace, []byte(machineID)).String(), nil
+}
+
+func (c *client) Publish(ctx context.Context, discoveryType string, payload interface{}) error {
+	return c.publish(ctx, discoveryType, payload, nil)
+}
+
+// PublishAt publishes a payload discovered at the given time,
+// the server records it as the discovery time instead of the reception time
+func (c *client) PublishAt(ctx context.Context, discoveryType string, payload interface{}, discoveredAt time.Time) error {
+	return c.publish(ctx, discoveryType, payload, &discoveredAt)
+}
+
+func (c *client) publish(ctx context.Context, discoveryType string, payload interface{}, discoveredAt *time.Time) error {
 	log.Debugf("Sending %s to data collector", discoveryType)
 
-	requestBody, err := json.Marshal(map[string]interface{}{
+	body := map[string]interface{}{
 		"agent_id":       c.agentID,
 		"discovery_type": discoveryType,
 		"payload":        payload,
-	})
+	}
+	if discoveredAt != nil {
+		body["discovered_at"] = discoveredAt.UTC()
+	}
+
+	requestBody, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
 
+	metrics.PayloadSize.WithLabelValues(discoveryType).Observe(float64(len(requestBody)))
+
 	url := fmt.Sprintf("%s/api/collect", c.getBaseURL())
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(requestBody))
+	resp, err := c.post(ctx, url, requestBody)
 	if err != nil {
+		metrics.PublishErrors.WithLabelValues(discoveryType).Inc()
 		return err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusAccepted {
-		return fmt.Errorf(
-			"something wrong happened while publishing data to the collector. Status: %d, Agent: %s, discovery: %s",
-			resp.StatusCode, c.agentID, discoveryType)
+		metrics.PublishErrors.WithLabelValues(discoveryType).Inc()
+		return &statusError{
+			statusCode: resp.StatusCode,
+			message: fmt.Sprintf(
+				"something wrong happened while publishing data to the collector. Status: %d, Agent: %s, discovery: %s",
+				resp.StatusCode, c.agentID, discoveryType),
+		}
 	}
 
 	return nil
 }
 
-func (c *client) Heartbeat() error {
+// statusError is returned when the collector responds with an unexpected status code
+type statusError struct {
+	statusCode int
+	message    string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+// isRejected tells whether the collector refused the request for good, sending it again would fail the same way.
+// Authentication failures, timeouts and rate limiting are transient, as the agent can enroll again or wait
+func isRejected(err error) bool {
+	var statusErr *statusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+
+	switch statusErr.statusCode {
+	case http.StatusUnauthorized, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+
+	return statusErr.statusCode >= 400 && statusErr.statusCode < 500
+}
+
+func (c *client) Heartbeat(ctx context.Context) error {
+	return c.heartbeat(ctx, nil)
+}
+
+// HeartbeatAt sends a heartbeat that happened at the given time
+func (c *client) HeartbeatAt(ctx context.Context, timestamp time.Time) error {
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"timestamp": timestamp.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.heartbeat(ctx, requestBody)
+}
+
+func (c *client) heartbeat(ctx context.Context, body []byte) error {
 	url := fmt.Sprintf("%s/api/hosts/%s/heartbeat", c.getBaseURL(), c.agentID)
-	resp, err := c.httpClient.Post(url, "application/json", nil)
+	resp, err := c.post(ctx, url, body)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("server responded with status code %d while sending heartbeat", resp.StatusCode)
+		return &statusError{
+			statusCode: resp.StatusCode,
+			message:    fmt.Sprintf("server responded with status code %d while sending heartbeat", resp.StatusCode),
+		}
 	}
 
 	return nil
 }
 
+// PickUpCommands returns the commands queued for the agent, each of them is delivered only once
+func (c *client) PickUpCommands(ctx context.Context) ([]*Command, error) {
+	url := fmt.Sprintf("%s/api/hosts/%s/commands/pickup", c.getBaseURL(), c.agentID)
+	resp, err := c.post(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server responded with status code %d while picking up the commands", resp.StatusCode)
+	}
+
+	var commands []*Command
+	if err := json.NewDecoder(resp.Body).Decode(&commands); err != nil {
+		return nil, err
+	}
+
+	return commands, nil
+}
+
+// CompleteCommand lets the server know the command was executed, failing with the given error if any
+func (c *client) CompleteCommand(ctx context.Context, id string, commandErr error) error {
+	completion := map[string]string{}
+	if commandErr != nil {
+		completion["error"] = commandErr.Error()
+	}
+
+	requestBody, err := json.Marshal(completion)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/hosts/%s/commands/%s/complete", c.getBaseURL(), c.agentID, id)
+	resp, err := c.post(ctx, url, requestBody)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("server responded with status code %d while completing the command %s", resp.StatusCode, id)
+	}
+
+	return nil
+}
+
+// post sends the body to the collector, compressing it when the collector advertised the support
+// with the Accept-Encoding header in a previous response
+func (c *client) post(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	if err := c.enroll(ctx); err != nil {
+		return nil, err
+	}
+
+	compress := len(body) > 0 && atomic.LoadInt32(&c.compressionSupported) == 1
+	credential := c.getCredential()
+
+	resp, err := c.doPost(ctx, url, body, compress, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	if compress && resp.StatusCode == http.StatusUnsupportedMediaType {
+		log.Debugf("The collector doesn't accept compressed requests anymore, sending the request uncompressed")
+		resp.Body.Close()
+		resp, err = c.doPost(ctx, url, body, false, credential)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.checkCredential(credential, resp.StatusCode == http.StatusUnauthorized)
+
+	return resp, nil
+}
+
+func (c *client) doPost(ctx context.Context, url string, body []byte, compress bool, credential string) (*http.Response, error) {
+	var requestBody io.Reader
+	if body != nil {
+		requestBody = bytes.NewReader(body)
+	}
+
+	if compress {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write(body); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		requestBody = &compressed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, requestBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if credential != "" {
+		req.SetBasicAuth(c.agentID, credential)
+	}
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	supported := int32(0)
+	if strings.Contains(resp.Header.Get("Accept-Encoding"), "gzip") {
+		supported = 1
+	}
+	atomic.StoreInt32(&c.compressionSupported, supported)
+
+	return resp, nil
+}
+
+// enroll exchanges the enrollment token for the agent credential, unless the agent already has one
+// the collector didn't reject. The credential is stored in the credential file, so the token is needed only once.
+// The rejected credential is kept until the agent enrolls again, as the rejection might be temporary
+// while the collector refuses to enroll an agent already enrolled
+func (c *client) enroll(ctx context.Context) error {
+	c.credentialMu.Lock()
+	defer c.credentialMu.Unlock()
+
+	if c.config.EnrollmentToken == "" || (c.credential != "" && !c.credentialRejected) {
+		return nil
+	}
+
+	credential, err := c.requestCredential(ctx)
+	if err != nil && c.credential != "" {
+		log.Errorf("Could not enroll agent %s again, keeping its rejected credential: %s", c.agentID, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.config.CredentialFile != "" {
+		if err := fileSystem.MkdirAll(filepath.Dir(c.config.CredentialFile), 0700); err != nil {
+			return err
+		}
+		if err := afero.WriteFile(fileSystem, c.config.CredentialFile, []byte(credential), 0600); err != nil {
+			return err
+		}
+	}
+
+	c.credential = credential
+	c.credentialRejected = false
+	log.Infof("Agent %s enrolled to the collector", c.agentID)
+
+	return nil
+}
+
+// requestCredential exchanges the enrollment token for a new agent credential
+func (c *client) requestCredential(ctx context.Context) (string, error) {
+	requestBody, err := json.Marshal(map[string]string{
+		"agent_id": c.agentID,
+		"token":    c.config.EnrollmentToken,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/api/enroll", c.getBaseURL())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(requestBody))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("server responded with status code %d while enrolling the agent", resp.StatusCode)
+	}
+
+	var enrollment struct {
+		Credential string `json:"credential"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&enrollment); err != nil {
+		return "", err
+	}
+
+	return enrollment.Credential, nil
+}
+
+// checkCredential records whether the collector rejected the credential a request was sent with,
+// so that the agent tries to enroll again with the configured enrollment token before the next request
+func (c *client) checkCredential(credential string, rejected bool) {
+	c.credentialMu.Lock()
+	defer c.credentialMu.Unlock()
+
+	// Another request could have enrolled the agent again in the meantime
+	if credential == "" || credential != c.credential || rejected == c.credentialRejected {
+		return
+	}
+
+	c.credentialRejected = rejected
+	switch {
+	case !rejected:
+		log.Infof("The collector accepts the credential of agent %s again", c.agentID)
+	case c.config.EnrollmentToken == "":
+		log.Errorf("The collector rejected the credential of agent %s, reset it on the server and set an enrollment token", c.agentID)
+	default:
+		log.Errorf("The collector rejected the credential of agent %s, enrolling it again", c.agentID)
+	}
+}
+
+func (c *client) getCredential() string {
+	c.credentialMu.Lock()
+	defer c.credentialMu.Unlock()
+
+	return c.credential
+}
+
 func (c *client) getBaseURL() string {
 	protocol := "http"
-	if c.config.EnablemTLS {
+	if c.config.EnablemTLS || c.config.CollectorTLS {
 		protocol = "https"
 	}
 	return fmt.Sprintf("%s://%s:%d", protocol, c.config.CollectorHost, c.config.CollectorPort)
 }
 
 func getTLSConfig(cert, key, ca string) (*tls.Config, error) {
-	caCert, err := ioutil.ReadFile(ca)
+	tlsConfig, err := getServerTLSConfig(ca)
 	if err != nil {
 		return nil, err
 	}
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
 
 	certificate, err := tls.LoadX509KeyPair(cert, key)
 	if err != nil {
 		return nil, err
 	}
+	tlsConfig.Certificates = []tls.Certificate{certificate}
+
+	return tlsConfig, nil
+}
+
+// getServerTLSConfig verifies the collector certificate with the CA, the agent doesn't present any certificate
+func getServerTLSConfig(ca string) (*tls.Config, error) {
+	caCert, err := ioutil.ReadFile(ca)
+	if err != nil {
+		return nil, err
+	}
+	caCertPool := x509.NewCertPool()
+	caCertPool.AppendCertsFromPEM(caCert)
 
 	return &tls.Config{
-		RootCAs:      caCertPool,
-		Certificates: []tls.Certificate{certificate},
+		RootCAs: caCertPool,
 	}, nil
 }
@@ -1,12 +1,17 @@
 package collector
 
 import (
+	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/suite"
@@ -50,6 +55,23 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_NewClientWithTLS() {
 	suite.Equal(1, len(transport.TLSClientConfig.Certificates))
 }
 
+func (suite *CollectorClientTestSuite) TestCollectorClient_NewClientWithCollectorTLS() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorTLS:  true,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		CA:            "./test/certs/ca-cert.pem",
+	})
+
+	suite.NoError(err)
+
+	transport, _ := (collectorClient.httpClient.Transport).(*http.Transport)
+
+	suite.NotNil(transport.TLSClientConfig.RootCAs)
+	suite.Empty(transport.TLSClientConfig.Certificates)
+	suite.Equal("https://localhost:8081", collectorClient.getBaseURL())
+}
+
 func (suite *CollectorClientTestSuite) TestCollectorClient_NewClientWithoutTLS() {
 	collectorClient, err := NewCollectorClient(&Config{
 		EnablemTLS:    false,
@@ -67,6 +89,27 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_NewClientWithoutTLS()
 	suite.Equal((*tls.Config)(nil), transport.TLSClientConfig)
 }
 
+func (suite *CollectorClientTestSuite) TestCollectorClient_AgentIDFromMachineID() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+	suite.Equal(DummyAgentID, collectorClient.agentID)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_AgentIDOverride() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+		AgentID:       "0b3fd7a6-3f5c-4c68-9d4f-6b9a0f5a1c2e",
+	})
+
+	suite.NoError(err)
+	suite.Equal("0b3fd7a6-3f5c-4c68-9d4f-6b9a0f5a1c2e", collectorClient.agentID)
+}
+
 func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingSuccess() {
 	collectorClient, err := NewCollectorClient(&Config{
 		EnablemTLS:    true,
@@ -104,7 +147,7 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingSuccess() {
 		}
 	})
 
-	err = collectorClient.Publish(discoveryType, discoveredDataPayload)
+	err = collectorClient.Publish(context.Background(), discoveryType, discoveredDataPayload)
 
 	suite.NoError(err)
 }
@@ -125,11 +168,34 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingFailure() {
 		}
 	})
 
-	err = collectorClient.Publish("some_discovery_type", struct{}{})
+	err = collectorClient.Publish(context.Background(), "some_discovery_type", struct{}{})
 
 	suite.Error(err)
 }
 
+func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingCancelled() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    false,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+	suite.Equal(requestTimeout, collectorClient.httpClient.Timeout)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.ErrorIs(req.Context().Err(), context.Canceled)
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	collectorClient.Publish(ctx, "some_discovery_type", struct{}{})
+}
+
 func (suite *CollectorClientTestSuite) TestCollectorClient_Heartbeat() {
 	collectorClient, err := NewCollectorClient(&Config{
 		EnablemTLS:    true,
@@ -148,7 +214,464 @@ func (suite *CollectorClientTestSuite) TestCollectorClient_Heartbeat() {
 			StatusCode: 204,
 		}
 	})
-	err = collectorClient.Heartbeat()
+	err = collectorClient.Heartbeat(context.Background())
+
+	suite.NoError(err)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingAt() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    false,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+
+	discoveredAt := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+
+		suite.JSONEq(fmt.Sprintf(`{
+			"agent_id": "%s",
+			"discovery_type": "the_discovery_type",
+			"discovered_at": "2022-04-01T10:00:00Z",
+			"payload": {"FieldA": "some discovered field"}
+		}`, DummyAgentID), string(bodyBytes))
+
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	err = collectorClient.PublishAt(context.Background(), "the_discovery_type", json.RawMessage(`{"FieldA": "some discovered field"}`), discoveredAt)
+
+	suite.NoError(err)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_HeartbeatAt() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    false,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+
+		suite.Equal(req.URL.String(), fmt.Sprintf("http://localhost:8081/api/hosts/%s/heartbeat", DummyAgentID))
+		suite.JSONEq(`{"timestamp": "2022-04-01T10:00:00Z"}`, string(bodyBytes))
+
+		return &http.Response{
+			StatusCode: 204,
+		}
+	})
+
+	err = collectorClient.HeartbeatAt(context.Background(), time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC))
+
+	suite.NoError(err)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingCompressed() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    false,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+
+	var contentEncodings []string
+	var bodies []string
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		contentEncodings = append(contentEncodings, req.Header.Get("Content-Encoding"))
+
+		var body io.Reader = req.Body
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			body, _ = gzip.NewReader(req.Body)
+		}
+		bodyBytes, _ := ioutil.ReadAll(body)
+		bodies = append(bodies, string(bodyBytes))
+
+		return &http.Response{
+			StatusCode: 202,
+			Header:     http.Header{"Accept-Encoding": []string{"gzip"}},
+		}
+	})
+
+	payload := map[string]string{"FieldA": "some discovered field"}
+	suite.NoError(collectorClient.Publish(context.Background(), "the_discovery_type", payload))
+	suite.NoError(collectorClient.Publish(context.Background(), "the_discovery_type", payload))
+
+	suite.Equal([]string{"", "gzip"}, contentEncodings)
+	suite.Equal(bodies[0], bodies[1])
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_PublishingCompressionNotSupportedAnymore() {
+	collectorClient, err := NewCollectorClient(&Config{
+		EnablemTLS:    false,
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+
+	collectorClient.compressionSupported = 1
+
+	var contentEncodings []string
+	rejectedBody := &closeRecorder{Reader: strings.NewReader("")}
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		contentEncodings = append(contentEncodings, req.Header.Get("Content-Encoding"))
+
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			return &http.Response{
+				StatusCode: 415,
+				Body:       rejectedBody,
+			}
+		}
+
+		return &http.Response{
+			StatusCode: 202,
+		}
+	})
+
+	suite.NoError(collectorClient.Publish(context.Background(), "the_discovery_type", struct{}{}))
+
+	suite.Equal([]string{"gzip", ""}, contentEncodings)
+	suite.True(rejectedBody.closed)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_Enroll() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost:   "localhost",
+		CollectorPort:   8081,
+		CollectorTLS:    true,
+		CA:              "./test/certs/ca-cert.pem",
+		EnrollmentToken: "enrollment_token",
+		CredentialFile:  "/var/lib/trento/agent-credential",
+	})
+
+	suite.NoError(err)
+
+	enrollments := 0
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		if req.URL.String() == "https://localhost:8081/api/enroll" {
+			enrollments++
+
+			bodyBytes, _ := ioutil.ReadAll(req.Body)
+			suite.JSONEq(fmt.Sprintf(`{"agent_id": "%s", "token": "enrollment_token"}`, DummyAgentID), string(bodyBytes))
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"credential": "agent_secret"}`)),
+			}
+		}
+
+		agentID, secret, ok := req.BasicAuth()
+		suite.True(ok)
+		suite.Equal(DummyAgentID, agentID)
+		suite.Equal("agent_secret", secret)
+
+		return &http.Response{
+			StatusCode: 204,
+		}
+	})
+
+	suite.NoError(collectorClient.Heartbeat(context.Background()))
+	suite.NoError(collectorClient.Heartbeat(context.Background()))
+	suite.Equal(1, enrollments)
+
+	credential, _ := afero.ReadFile(fileSystem, "/var/lib/trento/agent-credential")
+	suite.Equal("agent_secret", string(credential))
+
+	// The stored credential is used after a restart, without enrolling again
+	restartedClient, err := NewCollectorClient(&Config{
+		CollectorHost:   "localhost",
+		CollectorPort:   8081,
+		CollectorTLS:    true,
+		CA:              "./test/certs/ca-cert.pem",
+		EnrollmentToken: "enrollment_token",
+		CredentialFile:  "/var/lib/trento/agent-credential",
+	})
+
+	suite.NoError(err)
+	suite.Equal("agent_secret", restartedClient.credential)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_EnrollFailure() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost:   "localhost",
+		CollectorPort:   8081,
+		CollectorTLS:    true,
+		CA:              "./test/certs/ca-cert.pem",
+		EnrollmentToken: "expired_token",
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal("https://localhost:8081/api/enroll", req.URL.String())
+		return &http.Response{
+			StatusCode: 401,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+	})
+
+	err = collectorClient.Publish(context.Background(), "some_discovery_type", struct{}{})
+
+	suite.EqualError(err, "server responded with status code 401 while enrolling the agent")
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_EnrollAgainOnUnauthorized() {
+	afero.WriteFile(fileSystem, "/var/lib/trento/reset-credential", []byte("reset_secret"), 0600)
+
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost:   "localhost",
+		CollectorPort:   8081,
+		CollectorTLS:    true,
+		CA:              "./test/certs/ca-cert.pem",
+		EnrollmentToken: "enrollment_token",
+		CredentialFile:  "/var/lib/trento/reset-credential",
+	})
+
+	suite.NoError(err)
+
+	enrollments := 0
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		if req.URL.String() == "https://localhost:8081/api/enroll" {
+			enrollments++
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"credential": "new_secret"}`)),
+			}
+		}
+
+		_, secret, _ := req.BasicAuth()
+		if secret != "new_secret" {
+			return &http.Response{
+				StatusCode: 401,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+		}
+
+		return &http.Response{
+			StatusCode: 204,
+		}
+	})
+
+	suite.Error(collectorClient.Heartbeat(context.Background()))
+	suite.Equal(0, enrollments)
+
+	credential, _ := afero.ReadFile(fileSystem, "/var/lib/trento/reset-credential")
+	suite.Equal("reset_secret", string(credential))
+
+	suite.NoError(collectorClient.Heartbeat(context.Background()))
+	suite.Equal(1, enrollments)
+
+	credential, _ = afero.ReadFile(fileSystem, "/var/lib/trento/reset-credential")
+	suite.Equal("new_secret", string(credential))
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_KeepCredentialWhenEnrollingAgainFails() {
+	afero.WriteFile(fileSystem, "/var/lib/trento/enrolled-credential", []byte("enrolled_secret"), 0600)
+
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost:   "localhost",
+		CollectorPort:   8081,
+		CollectorTLS:    true,
+		CA:              "./test/certs/ca-cert.pem",
+		EnrollmentToken: "used_token",
+		CredentialFile:  "/var/lib/trento/enrolled-credential",
+	})
+
+	suite.NoError(err)
+
+	// The collector rejects the credential once, e.g. while its database is unavailable
+	rejections := 1
+	enrollments := 0
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		if req.URL.String() == "https://localhost:8081/api/enroll" {
+			enrollments++
+			return &http.Response{
+				StatusCode: 409,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+		}
+
+		_, secret, _ := req.BasicAuth()
+		suite.Equal("enrolled_secret", secret)
+
+		if rejections > 0 {
+			rejections--
+			return &http.Response{
+				StatusCode: 401,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+		}
+
+		return &http.Response{
+			StatusCode: 204,
+		}
+	})
+
+	suite.Error(collectorClient.Heartbeat(context.Background()))
+	suite.NoError(collectorClient.Heartbeat(context.Background()))
+	suite.Equal(1, enrollments)
+
+	credential, _ := afero.ReadFile(fileSystem, "/var/lib/trento/enrolled-credential")
+	suite.Equal("enrolled_secret", string(credential))
+
+	// Once accepted again, the credential is not replaced anymore
+	suite.NoError(collectorClient.Heartbeat(context.Background()))
+	suite.Equal(1, enrollments)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_KeepCredentialWithoutToken() {
+	afero.WriteFile(fileSystem, "/var/lib/trento/kept-credential", []byte("kept_secret"), 0600)
+
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost:  "localhost",
+		CollectorPort:  8081,
+		CollectorTLS:   true,
+		CA:             "./test/certs/ca-cert.pem",
+		CredentialFile: "/var/lib/trento/kept-credential",
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 401,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+	})
+
+	suite.Error(collectorClient.Heartbeat(context.Background()))
+	suite.Equal("kept_secret", collectorClient.getCredential())
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_CredentialOverPlainHTTP() {
+	afero.WriteFile(fileSystem, "/var/lib/trento/plain-credential", []byte("plain_secret"), 0600)
+
+	_, err := NewCollectorClient(&Config{
+		CollectorHost:  "localhost",
+		CollectorPort:  8081,
+		CredentialFile: "/var/lib/trento/plain-credential",
+	})
+
+	suite.EqualError(err, "the agent credential in /var/lib/trento/plain-credential can't be sent over plain HTTP, enable collector-tls or mTLS")
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_PickUpCommands() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal(http.MethodPost, req.Method)
+		suite.Equal(fmt.Sprintf("http://localhost:8081/api/hosts/%s/commands/pickup", DummyAgentID), req.URL.String())
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(strings.NewReader(
+				`[{"id": "command_id", "type": "run_discovery", "discovery_type": "host_discovery"}]`,
+			)),
+		}
+	})
+
+	commands, err := collectorClient.PickUpCommands(context.Background())
 
 	suite.NoError(err)
+	suite.Equal([]*Command{
+		{ID: "command_id", Type: RunDiscoveryCommand, DiscoveryType: "host_discovery"},
+	}, commands)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_CompleteCommand() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+
+	var completions []string
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		suite.Equal(fmt.Sprintf("http://localhost:8081/api/hosts/%s/commands/command_id/complete", DummyAgentID), req.URL.String())
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+		completions = append(completions, string(bodyBytes))
+		return &http.Response{
+			StatusCode: 204,
+		}
+	})
+
+	suite.NoError(collectorClient.CompleteCommand(context.Background(), "command_id", nil))
+	suite.NoError(collectorClient.CompleteCommand(context.Background(), "command_id", fmt.Errorf("discovery failed")))
+
+	suite.JSONEq(`{}`, completions[0])
+	suite.JSONEq(`{"error": "discovery failed"}`, completions[1])
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_ResponseBodiesClosed() {
+	collectorClient, err := NewCollectorClient(&Config{
+		CollectorHost: "localhost",
+		CollectorPort: 8081,
+	})
+
+	suite.NoError(err)
+
+	var bodies []*closeRecorder
+	statusCode := 0
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		body := &closeRecorder{Reader: strings.NewReader("")}
+		bodies = append(bodies, body)
+		return &http.Response{
+			StatusCode: statusCode,
+			Body:       body,
+		}
+	})
+
+	statusCode = 202
+	suite.NoError(collectorClient.Publish(context.Background(), "some_discovery_type", struct{}{}))
+	statusCode = 204
+	suite.NoError(collectorClient.Heartbeat(context.Background()))
+	suite.NoError(collectorClient.CompleteCommand(context.Background(), "command_id", nil))
+	statusCode = 500
+	suite.Error(collectorClient.Publish(context.Background(), "some_discovery_type", struct{}{}))
+	suite.Error(collectorClient.Heartbeat(context.Background()))
+	suite.Error(collectorClient.CompleteCommand(context.Background(), "command_id", nil))
+
+	suite.Len(bodies, 6)
+	for _, body := range bodies {
+		suite.True(body.closed)
+	}
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClient_isRejected() {
+	suite.True(isRejected(&statusError{statusCode: 400}))
+	suite.True(isRejected(&statusError{statusCode: 403}))
+	suite.True(isRejected(&statusError{statusCode: 413}))
+
+	suite.False(isRejected(&statusError{statusCode: 401}))
+	suite.False(isRejected(&statusError{statusCode: 429}))
+	suite.False(isRejected(&statusError{statusCode: 500}))
+	suite.False(isRejected(&statusError{statusCode: 503}))
+	suite.False(isRejected(fmt.Errorf("connection refused")))
 }
@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// deduplicatingClient is a Client that skips publishing a payload when it matches
+// the last one accepted by the collector for the same discovery type.
+// The payload is published anyway once the refresh interval has elapsed since it was last accepted,
+// so the server can recover if it loses its state.
+// A spooled payload is not recorded, yet it is reported as published to the discoveries.
+type deduplicatingClient struct {
+	Client
+	refreshInterval time.Duration
+	published       map[string]publishedPayload
+	mutex           sync.Mutex
+	now             func() time.Time
+}
+
+type publishedPayload struct {
+	fingerprint string
+	publishedAt time.Time
+}
+
+// NewDeduplicatingClient wraps the given Client skipping unchanged payloads.
+// A zero refresh interval disables the deduplication, publishing every payload
+func NewDeduplicatingClient(client Client, refreshInterval time.Duration) *deduplicatingClient {
+	return &deduplicatingClient{
+		Client:          client,
+		refreshInterval: refreshInterval,
+		published:       make(map[string]publishedPayload),
+		now:             time.Now,
+	}
+}
+
+func (c *deduplicatingClient) Publish(ctx context.Context, discoveryType string, payload interface{}) error {
+	fingerprint, err := fingerprintPayload(payload)
+	if err != nil {
+		return err
+	}
+
+	if !c.shouldPublish(discoveryType, fingerprint) {
+		log.Debugf("%s payload unchanged since it was last published, skipping", discoveryType)
+		return nil
+	}
+
+	err = c.Client.Publish(ctx, discoveryType, payload)
+	if errors.Is(err, ErrSpooled) {
+		// The spooled payload will be delivered later, so it is published again until the collector accepts it
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.published[discoveryType] = publishedPayload{
+		fingerprint: fingerprint,
+		publishedAt: c.now(),
+	}
+
+	return nil
+}
+
+func (c *deduplicatingClient) shouldPublish(discoveryType, fingerprint string) bool {
+	if c.refreshInterval == 0 {
+		return true
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	last, found := c.published[discoveryType]
+	if !found || last.fingerprint != fingerprint {
+		return true
+	}
+
+	return c.now().Sub(last.publishedAt) >= c.refreshInterval
+}
+
+func fingerprintPayload(payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DeduplicatingClientTestSuite struct {
+	suite.Suite
+	mockClient *MockClient
+	now        time.Time
+	client     *deduplicatingClient
+}
+
+func TestDeduplicatingClientTestSuite(t *testing.T) {
+	suite.Run(t, new(DeduplicatingClientTestSuite))
+}
+
+func (suite *DeduplicatingClientTestSuite) SetupTest() {
+	suite.mockClient = new(MockClient)
+	suite.now = time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	suite.client = NewDeduplicatingClient(suite.mockClient, time.Hour)
+	suite.client.now = func() time.Time {
+		return suite.now
+	}
+}
+
+func (suite *DeduplicatingClientTestSuite) TestPublishUnchangedPayload() {
+	payload := map[string]string{"name": "host1"}
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(nil).Once()
+
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}))
+
+	suite.mockClient.AssertNumberOfCalls(suite.T(), "Publish", 1)
+}
+
+func (suite *DeduplicatingClientTestSuite) TestPublishChangedPayload() {
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", map[string]string{"name": "host1"}).Return(nil).Once()
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", map[string]string{"name": "host2"}).Return(nil).Once()
+
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}))
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host2"}))
+
+	suite.mockClient.AssertExpectations(suite.T())
+}
+
+func (suite *DeduplicatingClientTestSuite) TestPublishSamePayloadDifferentDiscoveries() {
+	payload := map[string]string{"name": "host1"}
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(nil).Once()
+	suite.mockClient.On("Publish", mock.Anything, "cloud_discovery", payload).Return(nil).Once()
+
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+	suite.NoError(suite.client.Publish(context.Background(), "cloud_discovery", payload))
+
+	suite.mockClient.AssertExpectations(suite.T())
+}
+
+func (suite *DeduplicatingClientTestSuite) TestPublishForcedRefresh() {
+	payload := map[string]string{"name": "host1"}
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(nil).Twice()
+
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+	suite.now = suite.now.Add(59 * time.Minute)
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+	suite.now = suite.now.Add(time.Minute)
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+
+	suite.mockClient.AssertNumberOfCalls(suite.T(), "Publish", 2)
+}
+
+func (suite *DeduplicatingClientTestSuite) TestPublishRetriedAfterFailure() {
+	payload := map[string]string{"name": "host1"}
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(fmt.Errorf("connection refused")).Once()
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(nil).Once()
+
+	suite.EqualError(suite.client.Publish(context.Background(), "host_discovery", payload), "connection refused")
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+
+	suite.mockClient.AssertNumberOfCalls(suite.T(), "Publish", 2)
+}
+
+func (suite *DeduplicatingClientTestSuite) TestPublishRetriedAfterSpooling() {
+	payload := map[string]string{"name": "host1"}
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(ErrSpooled).Once()
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(nil).Once()
+
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+
+	suite.mockClient.AssertNumberOfCalls(suite.T(), "Publish", 2)
+}
+
+func (suite *DeduplicatingClientTestSuite) TestPublishDeduplicationDisabled() {
+	client := NewDeduplicatingClient(suite.mockClient, 0)
+	payload := map[string]string{"name": "host1"}
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(nil).Twice()
+
+	suite.NoError(client.Publish(context.Background(), "host_discovery", payload))
+	suite.NoError(client.Publish(context.Background(), "host_discovery", payload))
+
+	suite.mockClient.AssertNumberOfCalls(suite.T(), "Publish", 2)
+}
+
+func (suite *DeduplicatingClientTestSuite) TestHeartbeat() {
+	suite.mockClient.On("Heartbeat", mock.Anything).Return(nil).Once()
+
+	suite.NoError(suite.client.Heartbeat(context.Background()))
+
+	suite.mockClient.AssertExpectations(suite.T())
+}
@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// dryRunClient is a Client printing the payloads instead of publishing them to the collector.
+// It doesn't need the collector connectivity nor the machine ID, so it can be used to inspect
+// what the agent would send from any host
+type dryRunClient struct {
+	output    io.Writer
+	outputDir string
+	mutex     sync.Mutex
+}
+
+// NewDryRunClient returns a Client writing every payload to the given output, or to a
+// <discovery type>.json file in the given directory when it's not empty
+func NewDryRunClient(output io.Writer, outputDir string) (*dryRunClient, error) {
+	if outputDir != "" {
+		err := fileSystem.MkdirAll(outputDir, 0755)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &dryRunClient{
+		output:    output,
+		outputDir: outputDir,
+	}, nil
+}
+
+func (c *dryRunClient) Publish(_ context.Context, discoveryType string, payload interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.outputDir != "" {
+		data, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(c.outputDir, fmt.Sprintf("%s.json", discoveryType))
+		return afero.WriteFile(fileSystem, path, append(data, '\n'), 0644)
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"discovery_type": discoveryType,
+		"payload":        payload,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.output, "%s\n", data)
+	return err
+}
+
+// Heartbeat does nothing, as there is no server to notify
+func (c *dryRunClient) Heartbeat(_ context.Context) error {
+	return nil
+}
@@ -0,0 +1,54 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type DryRunClientTestSuite struct {
+	suite.Suite
+}
+
+func TestDryRunClientTestSuite(t *testing.T) {
+	suite.Run(t, new(DryRunClientTestSuite))
+}
+
+func (suite *DryRunClientTestSuite) SetupTest() {
+	fileSystem = afero.NewMemMapFs()
+}
+
+func (suite *DryRunClientTestSuite) TestPublishToOutput() {
+	var output bytes.Buffer
+
+	client, err := NewDryRunClient(&output, "")
+	suite.NoError(err)
+
+	suite.NoError(client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}))
+
+	suite.JSONEq(`{"discovery_type": "host_discovery", "payload": {"name": "host1"}}`, output.String())
+}
+
+func (suite *DryRunClientTestSuite) TestPublishToDirectory() {
+	var output bytes.Buffer
+
+	client, err := NewDryRunClient(&output, "/tmp/payloads")
+	suite.NoError(err)
+
+	suite.NoError(client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}))
+
+	content, err := afero.ReadFile(fileSystem, "/tmp/payloads/host_discovery.json")
+	suite.NoError(err)
+	suite.JSONEq(`{"name": "host1"}`, string(content))
+	suite.Empty(output.String())
+}
+
+func (suite *DryRunClientTestSuite) TestHeartbeat() {
+	client, err := NewDryRunClient(&bytes.Buffer{}, "")
+	suite.NoError(err)
+
+	suite.NoError(client.Heartbeat(context.Background()))
+}
@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -121,7 +122,7 @@ func (suite *PublishingTestSuite) runDiscoveryScenario(discoveryType string, pay
 		}
 	})
 
-	err := collectorClient.Publish(discoveryType, payload)
+	err := collectorClient.Publish(context.Background(), discoveryType, payload)
 
 	suite.NoError(err)
 }
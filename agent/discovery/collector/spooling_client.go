@@ -0,0 +1,336 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/trento-project/trento/agent/metrics"
+)
+
+const (
+	spoolEntryExtension   = ".json"
+	spoolHeartbeatSuffix  = "-heartbeat" + spoolEntryExtension
+	spoolEntryNamePattern = "%020d-%s" + spoolEntryExtension
+)
+
+// ErrSpooled is returned when the collector could not receive a payload which has been spooled instead,
+// so it is not mistaken for one the collector accepted
+var ErrSpooled = errors.New("the payload has been spooled, it will be sent once the collector is reachable")
+
+// spoolingClient is a Client persisting in a local spool directory the payloads and the heartbeats
+// the collector could not receive. The spool is replayed in order once the collector is reachable again,
+// sending the original discovery time along with every payload.
+// The spool is bounded in size: when full, the oldest entries are dropped.
+// While the spool is not empty new payloads are queued behind the spooled ones,
+// so the server never receives an old payload after a newer one.
+type spoolingClient struct {
+	client  TimestampedClient
+	dir     string
+	maxSize int64
+	spool   *spoolState
+	now     func() time.Time
+}
+
+// spoolState is shared by all the clients spooling in the same directory, so a client rebuilt
+// when the agent reloads takes over the spool only once the previous one is done writing or replaying it
+type spoolState struct {
+	mutex   sync.Mutex
+	lastKey int64
+}
+
+var (
+	spoolsMutex sync.Mutex
+	spools      = make(map[string]*spoolState)
+)
+
+// spoolStateFor returns the state of the spool in the given directory, creating it the first time
+func spoolStateFor(dir string) *spoolState {
+	spoolsMutex.Lock()
+	defer spoolsMutex.Unlock()
+
+	dir = filepath.Clean(dir)
+	state, found := spools[dir]
+	if !found {
+		state = &spoolState{}
+		spools[dir] = state
+	}
+
+	return state
+}
+
+type spoolEntry struct {
+	Heartbeat     bool            `json:"heartbeat,omitempty"`
+	DiscoveryType string          `json:"discovery_type,omitempty"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+	Timestamp     time.Time       `json:"timestamp"`
+}
+
+// NewSpoolingClient wraps the given client spooling the undelivered data in the given directory,
+// which is created if missing. The spool never grows beyond maxSize bytes
+func NewSpoolingClient(client TimestampedClient, dir string, maxSize int64) (*spoolingClient, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid spool size %d, it must be positive", maxSize)
+	}
+
+	err := fileSystem.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the spool directory")
+	}
+
+	c := &spoolingClient{
+		client:  client,
+		dir:     dir,
+		maxSize: maxSize,
+		spool:   spoolStateFor(dir),
+		now:     time.Now,
+	}
+
+	c.spool.mutex.Lock()
+	entries, err := c.entries()
+	c.spool.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	metrics.SpoolEntries.Set(float64(len(entries)))
+	if len(entries) > 0 {
+		log.Infof("Found %d spooled entries in %s, they will be sent once the collector is reachable", len(entries), dir)
+	}
+
+	return c, nil
+}
+
+func (c *spoolingClient) Publish(ctx context.Context, discoveryType string, payload interface{}) error {
+	c.spool.mutex.Lock()
+	defer c.spool.mutex.Unlock()
+	defer c.updateSpoolEntries()
+
+	discoveredAt := c.now()
+
+	err := c.flush(ctx)
+	if err == nil {
+		err = c.client.Publish(ctx, discoveryType, payload)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if isRejected(err) {
+		log.Errorf("The collector rejected %s, the payload is not spooled: %s", discoveryType, err)
+		return err
+	}
+
+	data, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	spoolErr := c.store(&spoolEntry{
+		DiscoveryType: discoveryType,
+		Payload:       data,
+		Timestamp:     discoveredAt,
+	})
+	if spoolErr != nil {
+		return errors.Wrapf(err, "could not spool the %s payload: %s", discoveryType, spoolErr)
+	}
+
+	log.Warnf("Could not publish %s, the payload has been spooled: %s", discoveryType, err)
+	return ErrSpooled
+}
+
+// Heartbeat sends the heartbeat, replaying the spool when successful.
+// A failed heartbeat is spooled replacing the previous one, as only the latest is relevant to the server
+func (c *spoolingClient) Heartbeat(ctx context.Context) error {
+	c.spool.mutex.Lock()
+	defer c.spool.mutex.Unlock()
+	defer c.updateSpoolEntries()
+
+	timestamp := c.now()
+
+	err := c.client.Heartbeat(ctx)
+	if err != nil {
+		spoolErr := c.store(&spoolEntry{
+			Heartbeat: true,
+			Timestamp: timestamp,
+		})
+		if spoolErr != nil {
+			log.Errorf("Could not spool the heartbeat: %s", spoolErr)
+		}
+		return err
+	}
+
+	err = c.flush(ctx)
+	if err != nil {
+		log.Warnf("Could not replay the spool: %s", err)
+	}
+
+	return nil
+}
+
+// flush replays the spooled entries from the oldest, stopping at the first failure.
+// The entries the collector rejects for good are dropped, so they don't hold back the following ones
+func (c *spoolingClient) flush(ctx context.Context) error {
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) > 0 {
+		log.Infof("Replaying %d spooled entries", len(entries))
+	}
+
+	for _, name := range entries {
+		path := filepath.Join(c.dir, name)
+
+		entry, err := c.readEntry(path)
+		if err != nil {
+			log.Warnf("Discarding the unreadable spool entry %s: %s", name, err)
+			c.remove(path)
+			continue
+		}
+
+		if entry.Heartbeat {
+			err = c.client.HeartbeatAt(ctx, entry.Timestamp)
+		} else {
+			err = c.client.PublishAt(ctx, entry.DiscoveryType, entry.Payload, entry.Timestamp)
+		}
+		if isRejected(err) {
+			log.Errorf("Dropping the spool entry %s rejected by the collector: %s", name, err)
+		} else if err != nil {
+			return err
+		}
+
+		c.remove(path)
+	}
+
+	return nil
+}
+
+func (c *spoolingClient) store(entry *spoolEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+
+	suffix := entry.DiscoveryType
+	if entry.Heartbeat {
+		suffix = "heartbeat"
+		for _, name := range entries {
+			if strings.HasSuffix(name, spoolHeartbeatSuffix) {
+				c.remove(filepath.Join(c.dir, name))
+			}
+		}
+	}
+
+	// Entries are named after their spooling time, so listing them returns them in order
+	key := entry.Timestamp.UnixNano()
+	if key <= c.spool.lastKey {
+		key = c.spool.lastKey + 1
+	}
+	c.spool.lastKey = key
+
+	err = afero.WriteFile(fileSystem, filepath.Join(c.dir, fmt.Sprintf(spoolEntryNamePattern, key, suffix)), data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return c.enforceMaxSize()
+}
+
+// enforceMaxSize drops the oldest entries until the spool fits its maximum size
+func (c *spoolingClient) enforceMaxSize() error {
+	infos, err := afero.ReadDir(fileSystem, c.dir)
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	var files []os.FileInfo
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), spoolEntryExtension) {
+			continue
+		}
+		size += info.Size()
+		files = append(files, info)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name() < files[j].Name()
+	})
+
+	for _, info := range files {
+		if size <= c.maxSize {
+			break
+		}
+		log.Warnf("The spool exceeds %d bytes, dropping the oldest entry %s", c.maxSize, info.Name())
+		c.remove(filepath.Join(c.dir, info.Name()))
+		size -= info.Size()
+	}
+
+	return nil
+}
+
+// entries returns the names of the spooled entries, from the oldest
+func (c *spoolingClient) entries() ([]string, error) {
+	infos, err := afero.ReadDir(fileSystem, c.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the spool directory")
+	}
+
+	var names []string
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), spoolEntryExtension) {
+			continue
+		}
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// updateSpoolEntries exposes the number of spooled entries
+func (c *spoolingClient) updateSpoolEntries() {
+	entries, err := c.entries()
+	if err != nil {
+		return
+	}
+
+	metrics.SpoolEntries.Set(float64(len(entries)))
+}
+
+func (c *spoolingClient) readEntry(path string) (*spoolEntry, error) {
+	data, err := afero.ReadFile(fileSystem, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry spoolEntry
+	err = json.Unmarshal(data, &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (c *spoolingClient) remove(path string) {
+	err := fileSystem.Remove(path)
+	if err != nil {
+		log.Errorf("Could not remove the spool entry %s: %s", path, err)
+	}
+}
@@ -0,0 +1,243 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const testSpoolDir = "/var/lib/trento/spool"
+
+type SpoolingClientTestSuite struct {
+	suite.Suite
+	mockClient *MockTimestampedClient
+	now        time.Time
+	client     *spoolingClient
+}
+
+func TestSpoolingClientTestSuite(t *testing.T) {
+	suite.Run(t, new(SpoolingClientTestSuite))
+}
+
+func (suite *SpoolingClientTestSuite) SetupTest() {
+	fileSystem = afero.NewMemMapFs()
+	suite.mockClient = new(MockTimestampedClient)
+	suite.now = time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	client, err := NewSpoolingClient(suite.mockClient, testSpoolDir, 1024*1024)
+	suite.NoError(err)
+	client.now = func() time.Time {
+		return suite.now
+	}
+	suite.client = client
+}
+
+func (suite *SpoolingClientTestSuite) spooledEntries() []string {
+	entries, err := suite.client.entries()
+	suite.NoError(err)
+	return entries
+}
+
+func (suite *SpoolingClientTestSuite) TestPublishSuccess() {
+	payload := map[string]string{"name": "host1"}
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(nil).Once()
+
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", payload))
+
+	suite.Empty(suite.spooledEntries())
+	suite.mockClient.AssertExpectations(suite.T())
+}
+
+func (suite *SpoolingClientTestSuite) TestPublishFailureIsSpooled() {
+	payload := map[string]string{"name": "host1"}
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", payload).Return(fmt.Errorf("connection refused")).Once()
+
+	suite.ErrorIs(suite.client.Publish(context.Background(), "host_discovery", payload), ErrSpooled)
+
+	entries := suite.spooledEntries()
+	suite.Len(entries, 1)
+
+	entry, err := suite.client.readEntry(testSpoolDir + "/" + entries[0])
+	suite.NoError(err)
+	suite.Equal("host_discovery", entry.DiscoveryType)
+	suite.JSONEq(`{"name":"host1"}`, string(entry.Payload))
+	suite.True(suite.now.Equal(entry.Timestamp))
+}
+
+func (suite *SpoolingClientTestSuite) TestSpoolReplayedInOrder() {
+	firstDiscoveredAt := suite.now
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	suite.ErrorIs(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}), ErrSpooled)
+
+	suite.now = suite.now.Add(time.Minute)
+	secondDiscoveredAt := suite.now
+	suite.mockClient.On("PublishAt", mock.Anything, "host_discovery", mock.Anything, firstDiscoveredAt).Return(fmt.Errorf("connection refused")).Once()
+	suite.ErrorIs(suite.client.Publish(context.Background(), "cloud_discovery", map[string]string{"provider": "azure"}), ErrSpooled)
+	suite.Len(suite.spooledEntries(), 2)
+
+	suite.now = suite.now.Add(time.Minute)
+	var replayed []string
+	suite.mockClient.On("PublishAt", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		replayed = append(replayed, args.String(1))
+	}).Return(nil).Twice()
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", map[string]string{"name": "host2"}).Return(nil).Once()
+
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host2"}))
+
+	suite.Equal([]string{"host_discovery", "cloud_discovery"}, replayed)
+	suite.mockClient.AssertCalled(suite.T(), "PublishAt", mock.Anything, "cloud_discovery", json.RawMessage(`{"provider":"azure"}`), secondDiscoveredAt)
+	suite.Empty(suite.spooledEntries())
+}
+
+func (suite *SpoolingClientTestSuite) TestRejectedEntryIsDropped() {
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	suite.ErrorIs(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}), ErrSpooled)
+
+	suite.now = suite.now.Add(time.Minute)
+	suite.mockClient.On("PublishAt", mock.Anything, "host_discovery", mock.Anything, mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	suite.ErrorIs(suite.client.Publish(context.Background(), "cloud_discovery", map[string]string{"provider": "azure"}), ErrSpooled)
+	suite.Len(suite.spooledEntries(), 2)
+
+	suite.now = suite.now.Add(time.Minute)
+	rejected := &statusError{statusCode: 413, message: "server responded with status code 413"}
+	suite.mockClient.On("PublishAt", mock.Anything, "host_discovery", mock.Anything, mock.Anything).Return(rejected).Once()
+	suite.mockClient.On("PublishAt", mock.Anything, "cloud_discovery", mock.Anything, mock.Anything).Return(nil).Once()
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", map[string]string{"name": "host2"}).Return(nil).Once()
+
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host2"}))
+
+	suite.Empty(suite.spooledEntries())
+	suite.mockClient.AssertExpectations(suite.T())
+}
+
+func (suite *SpoolingClientTestSuite) TestRejectedPublishIsNotSpooled() {
+	rejected := &statusError{statusCode: 400, message: "server responded with status code 400"}
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", mock.Anything).Return(rejected).Once()
+
+	suite.Equal(rejected, suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}))
+
+	suite.Empty(suite.spooledEntries())
+}
+
+func (suite *SpoolingClientTestSuite) TestUnauthorizedEntryIsKept() {
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	suite.ErrorIs(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}), ErrSpooled)
+
+	unauthorized := &statusError{statusCode: 401, message: "server responded with status code 401"}
+	suite.mockClient.On("Heartbeat", mock.Anything).Return(nil).Once()
+	suite.mockClient.On("PublishAt", mock.Anything, "host_discovery", mock.Anything, mock.Anything).Return(unauthorized).Once()
+
+	suite.NoError(suite.client.Heartbeat(context.Background()))
+
+	suite.Len(suite.spooledEntries(), 1)
+}
+
+func (suite *SpoolingClientTestSuite) TestHeartbeatFailureKeepsLatestOnly() {
+	suite.mockClient.On("Heartbeat", mock.Anything).Return(fmt.Errorf("connection refused")).Twice()
+
+	suite.EqualError(suite.client.Heartbeat(context.Background()), "connection refused")
+	suite.now = suite.now.Add(5 * time.Second)
+	latest := suite.now
+	suite.EqualError(suite.client.Heartbeat(context.Background()), "connection refused")
+
+	entries := suite.spooledEntries()
+	suite.Len(entries, 1)
+
+	entry, err := suite.client.readEntry(testSpoolDir + "/" + entries[0])
+	suite.NoError(err)
+	suite.True(entry.Heartbeat)
+	suite.True(latest.Equal(entry.Timestamp))
+}
+
+func (suite *SpoolingClientTestSuite) TestHeartbeatReplaysSpool() {
+	heartbeatAt := suite.now
+	suite.mockClient.On("Heartbeat", mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	suite.Error(suite.client.Heartbeat(context.Background()))
+
+	suite.now = suite.now.Add(5 * time.Second)
+	suite.mockClient.On("Heartbeat", mock.Anything).Return(nil).Once()
+	suite.mockClient.On("HeartbeatAt", mock.Anything, heartbeatAt).Return(nil).Once()
+
+	suite.NoError(suite.client.Heartbeat(context.Background()))
+
+	suite.Empty(suite.spooledEntries())
+	suite.mockClient.AssertExpectations(suite.T())
+}
+
+func (suite *SpoolingClientTestSuite) TestSpoolDropsOldestEntries() {
+	client, err := NewSpoolingClient(suite.mockClient, testSpoolDir, 200)
+	suite.NoError(err)
+	client.now = suite.client.now
+	suite.client = client
+
+	suite.mockClient.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	suite.mockClient.On("PublishAt", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("connection refused"))
+
+	for i := 0; i < 5; i++ {
+		suite.ErrorIs(suite.client.Publish(context.Background(), "host_discovery", map[string]int{"iteration": i}), ErrSpooled)
+		suite.now = suite.now.Add(time.Second)
+	}
+
+	entries := suite.spooledEntries()
+	suite.NotEmpty(entries)
+	suite.Less(len(entries), 5)
+
+	entry, err := suite.client.readEntry(testSpoolDir + "/" + entries[len(entries)-1])
+	suite.NoError(err)
+	suite.JSONEq(`{"iteration":4}`, string(entry.Payload))
+}
+
+func (suite *SpoolingClientTestSuite) TestSpoolSurvivesRestart() {
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	suite.ErrorIs(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}), ErrSpooled)
+
+	client, err := NewSpoolingClient(suite.mockClient, testSpoolDir, 1024*1024)
+	suite.NoError(err)
+
+	suite.mockClient.On("Heartbeat", mock.Anything).Return(nil).Once()
+	suite.mockClient.On("PublishAt", mock.Anything, "host_discovery", json.RawMessage(`{"name":"host1"}`), suite.now).Return(nil).Once()
+
+	suite.NoError(client.Heartbeat(context.Background()))
+
+	suite.Empty(suite.spooledEntries())
+	suite.mockClient.AssertExpectations(suite.T())
+}
+
+func (suite *SpoolingClientTestSuite) TestSpoolHandedOver() {
+	mockClient := new(MockTimestampedClient)
+	client, err := NewSpoolingClient(mockClient, testSpoolDir+"/", 1024*1024)
+	suite.NoError(err)
+	client.now = suite.client.now
+	suite.Same(suite.client.spool, client.spool)
+
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	suite.ErrorIs(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}), ErrSpooled)
+
+	mockClient.On("PublishAt", mock.Anything, "host_discovery", mock.Anything, suite.now).Return(fmt.Errorf("connection refused")).Once()
+	suite.ErrorIs(client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host2"}), ErrSpooled)
+
+	suite.Len(suite.spooledEntries(), 2)
+	suite.mockClient.AssertExpectations(suite.T())
+	mockClient.AssertExpectations(suite.T())
+}
+
+func (suite *SpoolingClientTestSuite) TestDiscardUnreadableEntry() {
+	afero.WriteFile(fileSystem, testSpoolDir+"/00000000000000000001-host_discovery.json", []byte("not json"), 0600)
+	suite.mockClient.On("Publish", mock.Anything, "host_discovery", mock.Anything).Return(nil).Once()
+
+	suite.NoError(suite.client.Publish(context.Background(), "host_discovery", map[string]string{"name": "host1"}))
+
+	suite.Empty(suite.spooledEntries())
+	suite.mockClient.AssertExpectations(suite.T())
+}
+
+func (suite *SpoolingClientTestSuite) TestInvalidMaxSize() {
+	_, err := NewSpoolingClient(suite.mockClient, testSpoolDir, 0)
+	suite.EqualError(err, "invalid spool size 0, it must be positive")
+}
@@ -1,39 +1,75 @@
 package discovery
 
 import (
+	"context"
 	"os"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/trento-project/trento/agent/discovery/collector"
 )
 
 type Discovery interface {
 	// Returns an arbitrary unique string identifier of the discovery
 	GetId() string
-	// Execute the discovery mechanism
-	Discover() (string, error)
+	// Returns the period between two executions of the discovery
+	GetInterval() time.Duration
+	// Returns the maximum duration of a single execution of the discovery
+	GetTimeout() time.Duration
+	// Execute the discovery mechanism. The execution must be interrupted when the context is done
+	Discover(ctx context.Context) (string, error)
 }
 
+// ErrDiscoveryTimeout is returned when a discovery doesn't complete within its timeout
+var ErrDiscoveryTimeout = errors.New("discovery timed out")
+
 type BaseDiscovery struct {
 	id              string
 	collectorClient collector.Client
 	host            string
+	interval        time.Duration
+	timeout         time.Duration
 }
 
 func (d BaseDiscovery) GetId() string {
 	return d.id
 }
 
+func (d BaseDiscovery) GetInterval() time.Duration {
+	return d.interval
+}
+
+func (d BaseDiscovery) GetTimeout() time.Duration {
+	return d.timeout
+}
+
 // Execute one iteration of a discovery
-func (d BaseDiscovery) Discover() (string, error) {
+func (d BaseDiscovery) Discover(ctx context.Context) (string, error) {
 	d.host, _ = os.Hostname()
 	return "Basic discovery example", nil
 }
 
 // NewDiscovery Return a new base discovery with the support for data collector endpoint
-func NewDiscovery(collectorClient collector.Client) BaseDiscovery {
+func NewDiscovery(collectorClient collector.Client, config DiscoveryConfig) BaseDiscovery {
 	d := BaseDiscovery{}
 	d.id = ""
 	d.collectorClient = collectorClient
 	d.host, _ = os.Hostname()
+	d.interval = config.Interval
+	d.timeout = config.Timeout
 	return d
 }
+
+// RunWithTimeout executes one iteration of the discovery, cancelling it if it doesn't complete within its timeout.
+// A discovery interrupted by the timeout returns an ErrDiscoveryTimeout error
+func RunWithTimeout(ctx context.Context, d Discovery) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.GetTimeout())
+	defer cancel()
+
+	result, err := d.Discover(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", errors.Wrapf(ErrDiscoveryTimeout, "%s did not complete within %s", d.GetId(), d.GetTimeout())
+	}
+
+	return result, err
+}
@@ -0,0 +1,66 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type slowDiscovery struct {
+	BaseDiscovery
+	duration time.Duration
+}
+
+func (d slowDiscovery) Discover(ctx context.Context) (string, error) {
+	select {
+	case <-time.After(d.duration):
+		return "slow discovery completed", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func newSlowDiscovery(duration, timeout time.Duration) slowDiscovery {
+	d := slowDiscovery{
+		BaseDiscovery: NewDiscovery(nil, DiscoveryConfig{Enabled: true, Interval: time.Minute, Timeout: timeout}),
+		duration:      duration,
+	}
+	d.BaseDiscovery.id = "slow_discovery"
+	return d
+}
+
+type DiscoveryTestSuite struct {
+	suite.Suite
+}
+
+func TestDiscoveryTestSuite(t *testing.T) {
+	suite.Run(t, new(DiscoveryTestSuite))
+}
+
+func (suite *DiscoveryTestSuite) TestRunWithTimeout() {
+	result, err := RunWithTimeout(context.Background(), newSlowDiscovery(0, time.Second))
+
+	suite.NoError(err)
+	suite.Equal("slow discovery completed", result)
+}
+
+func (suite *DiscoveryTestSuite) TestRunWithTimeoutExpired() {
+	result, err := RunWithTimeout(context.Background(), newSlowDiscovery(time.Minute, 10*time.Millisecond))
+
+	suite.True(errors.Is(err, ErrDiscoveryTimeout))
+	suite.EqualError(err, "slow_discovery did not complete within 10ms: discovery timed out")
+	suite.Equal("", result)
+}
+
+func (suite *DiscoveryTestSuite) TestRunWithTimeoutCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := RunWithTimeout(ctx, newSlowDiscovery(time.Minute, time.Minute))
+
+	suite.False(errors.Is(err, ErrDiscoveryTimeout))
+	suite.ErrorIs(err, context.Canceled)
+}
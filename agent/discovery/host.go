@@ -1,9 +1,11 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strconv"
+	"time"
 
 	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/host"
@@ -22,11 +24,11 @@ type HostDiscovery struct {
 	discovery  BaseDiscovery
 }
 
-func NewHostDiscovery(sshAddress string, collectorClient collector.Client) HostDiscovery {
+func NewHostDiscovery(sshAddress string, collectorClient collector.Client, config DiscoveryConfig) HostDiscovery {
 	d := HostDiscovery{}
 	d.id = HostDiscoveryId
 	d.sshAddress = sshAddress
-	d.discovery = NewDiscovery(collectorClient)
+	d.discovery = NewDiscovery(collectorClient, config)
 	return d
 }
 
@@ -34,8 +36,16 @@ func (h HostDiscovery) GetId() string {
 	return h.id
 }
 
+func (h HostDiscovery) GetInterval() time.Duration {
+	return h.discovery.interval
+}
+
+func (h HostDiscovery) GetTimeout() time.Duration {
+	return h.discovery.timeout
+}
+
 // Execute one iteration of a discovery and publish to the collector
-func (h HostDiscovery) Discover() (string, error) {
+func (h HostDiscovery) Discover(ctx context.Context) (string, error) {
 	ipAddresses, err := getHostIpAddresses()
 	if err != nil {
 		return "", err
@@ -43,16 +53,16 @@ func (h HostDiscovery) Discover() (string, error) {
 
 	host := hosts.DiscoveredHost{
 		SSHAddress:      h.sshAddress,
-		OSVersion:       getOSVersion(),
+		OSVersion:       getOSVersion(ctx),
 		HostIpAddresses: ipAddresses,
 		HostName:        h.discovery.host,
-		CPUCount:        getLogicalCPUs(),
-		SocketCount:     getCPUSocketCount(),
-		TotalMemoryMB:   getTotalMemoryMB(),
+		CPUCount:        getLogicalCPUs(ctx),
+		SocketCount:     getCPUSocketCount(ctx),
+		TotalMemoryMB:   getTotalMemoryMB(ctx),
 		AgentVersion:    version.Version,
 	}
 
-	err = h.discovery.collectorClient.Publish(h.id, host)
+	err = h.discovery.collectorClient.Publish(ctx, h.id, host)
 	if err != nil {
 		log.Debugf("Error while sending host discovery to data collector: %s", err)
 		return "", err
@@ -84,32 +94,32 @@ func getHostIpAddresses() ([]string, error) {
 	return ipAddrList, nil
 }
 
-func getOSVersion() string {
-	infoStat, err := host.Info()
+func getOSVersion(ctx context.Context) string {
+	infoStat, err := host.InfoWithContext(ctx)
 	if err != nil {
 		log.Errorf("Error while getting host info: %s", err)
 	}
 	return infoStat.PlatformVersion
 }
 
-func getTotalMemoryMB() int {
-	v, err := mem.VirtualMemory()
+func getTotalMemoryMB(ctx context.Context) int {
+	v, err := mem.VirtualMemoryWithContext(ctx)
 	if err != nil {
 		log.Errorf("Error while getting memory info: %s", err)
 	}
 	return int(v.Total) / 1024 / 1024
 }
 
-func getLogicalCPUs() int {
-	logical, err := cpu.Counts(true)
+func getLogicalCPUs(ctx context.Context) int {
+	logical, err := cpu.CountsWithContext(ctx, true)
 	if err != nil {
 		log.Errorf("Error while getting logical CPU count: %s", err)
 	}
 	return logical
 }
 
-func getCPUSocketCount() int {
-	info, err := cpu.Info()
+func getCPUSocketCount(ctx context.Context) int {
+	info, err := cpu.InfoWithContext(ctx)
 
 	if err != nil {
 		log.Errorf("Error while getting CPU info: %s", err)
@@ -0,0 +1,59 @@
+package discovery
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/agent/discovery/collector"
+)
+
+// DiscoveryConfig holds the scheduling configuration of a single discovery
+type DiscoveryConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// DiscoveriesConfig maps the discovery IDs to their configuration
+type DiscoveriesConfig map[string]DiscoveryConfig
+
+// Registry is the list of the enabled discoveries, each one running on its own schedule
+type Registry []Discovery
+
+// DefaultDiscoveriesConfig returns the default configuration of every supported discovery.
+// Cheap discoveries run every few seconds, while the ones spawning the SAP tools
+// or reaching remote services run less often and are given more time to complete.
+func DefaultDiscoveriesConfig() DiscoveriesConfig {
+	return DiscoveriesConfig{
+		HostDiscoveryId:         {Enabled: true, Interval: 10 * time.Second, Timeout: 30 * time.Second},
+		ClusterDiscoveryId:      {Enabled: true, Interval: 10 * time.Second, Timeout: 30 * time.Second},
+		CloudDiscoveryId:        {Enabled: true, Interval: 1 * time.Minute, Timeout: 30 * time.Second},
+		SAPDiscoveryId:          {Enabled: true, Interval: 1 * time.Minute, Timeout: 2 * time.Minute},
+		SubscriptionDiscoveryId: {Enabled: true, Interval: 5 * time.Minute, Timeout: 1 * time.Minute},
+		SystemdDiscoveryId:      {Enabled: true, Interval: 1 * time.Minute, Timeout: 30 * time.Second},
+		TuningDiscoveryId:       {Enabled: true, Interval: 5 * time.Minute, Timeout: 1 * time.Minute},
+	}
+}
+
+// NewRegistry returns the registry of the discoveries enabled in the given configuration.
+// Discoveries missing from the configuration are considered disabled.
+func NewRegistry(collectorClient collector.Client, sshAddress string, systemdUnits []string, config DiscoveriesConfig) Registry {
+	discoveries := []Discovery{
+		NewClusterDiscovery(collectorClient, config[ClusterDiscoveryId]),
+		NewSAPSystemsDiscovery(collectorClient, config[SAPDiscoveryId]),
+		NewCloudDiscovery(collectorClient, config[CloudDiscoveryId]),
+		NewSubscriptionDiscovery(collectorClient, config[SubscriptionDiscoveryId]),
+		NewHostDiscovery(sshAddress, collectorClient, config[HostDiscoveryId]),
+		NewSystemdDiscovery(systemdUnits, collectorClient, config[SystemdDiscoveryId]),
+		NewTuningDiscovery(collectorClient, config[TuningDiscoveryId]),
+	}
+
+	registry := Registry{}
+	for _, d := range discoveries {
+		if !config[d.GetId()].Enabled {
+			continue
+		}
+		registry = append(registry, d)
+	}
+
+	return registry
+}
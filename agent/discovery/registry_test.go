@@ -0,0 +1,47 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RegistryTestSuite struct {
+	suite.Suite
+}
+
+func TestRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
+
+func (suite *RegistryTestSuite) TestNewRegistry() {
+	config := DefaultDiscoveriesConfig()
+	config[SAPDiscoveryId] = DiscoveryConfig{Enabled: true, Interval: 5 * time.Minute}
+	config[SubscriptionDiscoveryId] = DiscoveryConfig{Enabled: false, Interval: 5 * time.Minute}
+
+	registry := NewRegistry(nil, "some-ssh-address", nil, config)
+
+	intervals := make(map[string]time.Duration)
+	for _, d := range registry {
+		intervals[d.GetId()] = d.GetInterval()
+	}
+
+	suite.Equal(map[string]time.Duration{
+		ClusterDiscoveryId: 10 * time.Second,
+		SAPDiscoveryId:     5 * time.Minute,
+		CloudDiscoveryId:   1 * time.Minute,
+		HostDiscoveryId:    10 * time.Second,
+		SystemdDiscoveryId: 1 * time.Minute,
+		TuningDiscoveryId:  5 * time.Minute,
+	}, intervals)
+}
+
+func (suite *RegistryTestSuite) TestNewRegistryMissingConfig() {
+	registry := NewRegistry(nil, "some-ssh-address", nil, DiscoveriesConfig{
+		HostDiscoveryId: {Enabled: true, Interval: 10 * time.Second},
+	})
+
+	suite.Len(registry, 1)
+	suite.Equal(HostDiscoveryId, registry[0].GetId())
+}
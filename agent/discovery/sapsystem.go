@@ -1,7 +1,9 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/agent/discovery/collector"
@@ -15,10 +17,10 @@ type SAPSystemsDiscovery struct {
 	discovery BaseDiscovery
 }
 
-func NewSAPSystemsDiscovery(collectorClient collector.Client) SAPSystemsDiscovery {
+func NewSAPSystemsDiscovery(collectorClient collector.Client, config DiscoveryConfig) SAPSystemsDiscovery {
 	r := SAPSystemsDiscovery{}
 	r.id = SAPDiscoveryId
-	r.discovery = NewDiscovery(collectorClient)
+	r.discovery = NewDiscovery(collectorClient, config)
 	return r
 }
 
@@ -26,14 +28,22 @@ func (d SAPSystemsDiscovery) GetId() string {
 	return d.id
 }
 
-func (d SAPSystemsDiscovery) Discover() (string, error) {
-	systems, err := sapsystem.NewSAPSystemsList()
+func (d SAPSystemsDiscovery) GetInterval() time.Duration {
+	return d.discovery.interval
+}
+
+func (d SAPSystemsDiscovery) GetTimeout() time.Duration {
+	return d.discovery.timeout
+}
+
+func (d SAPSystemsDiscovery) Discover(ctx context.Context) (string, error) {
+	systems, err := sapsystem.NewSAPSystemsList(ctx)
 
 	if err != nil {
 		return "", err
 	}
 
-	err = d.discovery.collectorClient.Publish(d.id, systems)
+	err = d.discovery.collectorClient.Publish(ctx, d.id, systems)
 	if err != nil {
 		log.Debugf("Error while sending sapsystem discovery to data collector: %s", err)
 		return "", err
@@ -1,7 +1,9 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/agent/discovery/collector"
@@ -15,10 +17,10 @@ type SubscriptionDiscovery struct {
 	discovery BaseDiscovery
 }
 
-func NewSubscriptionDiscovery(collectorClient collector.Client) SubscriptionDiscovery {
+func NewSubscriptionDiscovery(collectorClient collector.Client, config DiscoveryConfig) SubscriptionDiscovery {
 	r := SubscriptionDiscovery{}
 	r.id = SubscriptionDiscoveryId
-	r.discovery = NewDiscovery(collectorClient)
+	r.discovery = NewDiscovery(collectorClient, config)
 	return r
 }
 
@@ -26,13 +28,21 @@ func (d SubscriptionDiscovery) GetId() string {
 	return d.id
 }
 
-func (d SubscriptionDiscovery) Discover() (string, error) {
-	subsData, err := subscription.NewSubscriptions()
+func (d SubscriptionDiscovery) GetInterval() time.Duration {
+	return d.discovery.interval
+}
+
+func (d SubscriptionDiscovery) GetTimeout() time.Duration {
+	return d.discovery.timeout
+}
+
+func (d SubscriptionDiscovery) Discover(ctx context.Context) (string, error) {
+	subsData, err := subscription.NewSubscriptions(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	err = d.discovery.collectorClient.Publish(d.id, subsData)
+	err = d.discovery.collectorClient.Publish(ctx, d.id, subsData)
 	if err != nil {
 		log.Debugf("Error while sending subscription discovery to data collector: %s", err)
 		return "", err
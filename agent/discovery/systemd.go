@@ -0,0 +1,54 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/internal/systemd"
+)
+
+const SystemdDiscoveryId string = "systemd_discovery"
+
+type SystemdDiscovery struct {
+	id        string
+	units     []string
+	discovery BaseDiscovery
+}
+
+func NewSystemdDiscovery(units []string, collectorClient collector.Client, config DiscoveryConfig) SystemdDiscovery {
+	d := SystemdDiscovery{}
+	d.id = SystemdDiscoveryId
+	d.units = units
+	d.discovery = NewDiscovery(collectorClient, config)
+	return d
+}
+
+func (d SystemdDiscovery) GetId() string {
+	return d.id
+}
+
+func (d SystemdDiscovery) GetInterval() time.Duration {
+	return d.discovery.interval
+}
+
+func (d SystemdDiscovery) GetTimeout() time.Duration {
+	return d.discovery.timeout
+}
+
+func (d SystemdDiscovery) Discover(ctx context.Context) (string, error) {
+	units, err := systemd.NewUnits(ctx, d.units)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.discovery.collectorClient.Publish(ctx, d.id, units)
+	if err != nil {
+		log.Debugf("Error while sending systemd discovery to data collector: %s", err)
+		return "", err
+	}
+
+	return fmt.Sprintf("Systemd units (%d entries) discovered", len(units)), nil
+}
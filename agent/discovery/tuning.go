@@ -0,0 +1,56 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/internal/tuning"
+)
+
+const TuningDiscoveryId string = "tuning_discovery"
+
+type TuningDiscovery struct {
+	id        string
+	discovery BaseDiscovery
+}
+
+func NewTuningDiscovery(collectorClient collector.Client, config DiscoveryConfig) TuningDiscovery {
+	d := TuningDiscovery{}
+	d.id = TuningDiscoveryId
+	d.discovery = NewDiscovery(collectorClient, config)
+	return d
+}
+
+func (d TuningDiscovery) GetId() string {
+	return d.id
+}
+
+func (d TuningDiscovery) GetInterval() time.Duration {
+	return d.discovery.interval
+}
+
+func (d TuningDiscovery) GetTimeout() time.Duration {
+	return d.discovery.timeout
+}
+
+func (d TuningDiscovery) Discover(ctx context.Context) (string, error) {
+	status, err := tuning.NewStatus(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.discovery.collectorClient.Publish(ctx, d.id, status)
+	if err != nil {
+		log.Debugf("Error while sending tuning discovery to data collector: %s", err)
+		return "", err
+	}
+
+	if status.Tool == "" {
+		return "SAP tuning discovered, the host is not tuned", nil
+	}
+
+	return fmt.Sprintf("SAP tuning discovered, tuned by %s", status.Tool), nil
+}
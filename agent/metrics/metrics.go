@@ -0,0 +1,69 @@
+// Package metrics holds the Prometheus metrics the agent exposes about itself
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "trento_agent"
+
+// Registry is where the agent metrics are registered, apart from the default Prometheus registry
+var Registry = prometheus.NewRegistry()
+
+var (
+	DiscoveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "discovery_duration_seconds",
+		Help:      "Duration of the discovery executions.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120},
+	}, []string{"discovery"})
+
+	DiscoveryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "discovery_errors_total",
+		Help:      "Number of discovery executions failed or timed out.",
+	}, []string{"discovery"})
+
+	DiscoveryLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "discovery_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful discovery execution.",
+	}, []string{"discovery"})
+
+	PayloadSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "published_payload_size_bytes",
+		Help:      "Size of the payloads published to the collector, before compression.",
+		Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+	}, []string{"discovery"})
+
+	PublishErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "publish_errors_total",
+		Help:      "Number of payloads the collector could not receive.",
+	}, []string{"discovery"})
+
+	HeartbeatFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "heartbeat_failures_total",
+		Help:      "Number of heartbeats the collector could not receive.",
+	})
+
+	SpoolEntries = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "spool_entries",
+		Help:      "Number of payloads and heartbeats waiting in the spool.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		DiscoveryDuration,
+		DiscoveryErrors,
+		DiscoveryLastSuccess,
+		PayloadSize,
+		PublishErrors,
+		HeartbeatFailures,
+		SpoolEntries,
+	)
+}
@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/agent/discovery/collector"
+)
+
+// Reload applies a new configuration to the running agent. The collector client is rebuilt only when
+// its configuration changed, so the deduplication state is kept otherwise, and the discovery loops
+// are restarted with the new schedule. A rebuilt client spooling in the same directory takes the spool
+// over from the previous one, which may still be replaying it.
+// The current configuration is kept when the new one can't be applied
+func (a *Agent) Reload(config *Config) error {
+	current := a.getConfig()
+
+	// The instance name is the hostname read when the agent started
+	config.InstanceName = current.InstanceName
+	if config.StatusAddress != current.StatusAddress {
+		log.Warnf("The status-address change from %q to %q is applied only when the agent restarts",
+			current.StatusAddress, config.StatusAddress)
+		config.StatusAddress = current.StatusAddress
+	}
+
+	changes := configChanges(current, config)
+	if len(changes) == 0 {
+		log.Info("The agent configuration didn't change")
+		return nil
+	}
+
+	collectorClient, commandsClient := a.getCollectorClients()
+	if collectorConfigChanged(current, config) {
+		var err error
+		collectorClient, commandsClient, err = newCollectorClients(config)
+		if err != nil {
+			return err
+		}
+	}
+
+	discoveries := discovery.NewRegistry(collectorClient, config.SSHAddress, config.SystemdUnits, config.DiscoveriesConfig)
+
+	a.mutex.Lock()
+	a.config = config
+	a.collectorClient = collectorClient
+	a.commandsClient = commandsClient
+	a.discoveries = discoveries
+	a.mutex.Unlock()
+
+	a.status.reschedule(discoveries)
+
+	// The discovery loops are restarted once, even when the agent is reloaded again before they are
+	select {
+	case a.rescheduled <- struct{}{}:
+	default:
+	}
+
+	for _, change := range changes {
+		log.Infof("Configuration changed: %s", change)
+	}
+	if len(discoveries) == 0 {
+		log.Warn("No discovery is enabled, the agent will only send heartbeats")
+	}
+
+	return nil
+}
+
+// collectorConfigChanged tells whether the client publishing to the collector needs to be rebuilt
+func collectorConfigChanged(current *Config, config *Config) bool {
+	return !reflect.DeepEqual(current.CollectorConfig, config.CollectorConfig) ||
+		current.SpoolDir != config.SpoolDir ||
+		current.SpoolMaxSize != config.SpoolMaxSize ||
+		current.RefreshInterval != config.RefreshInterval
+}
+
+// configChanges describes the differences between two configurations, without revealing the secrets
+func configChanges(current *Config, config *Config) []string {
+	var changes []string
+
+	changed := func(name string, from interface{}, to interface{}) {
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, fmt.Sprintf("%s from %v to %v", name, from, to))
+		}
+	}
+
+	changed("ssh-address", current.SSHAddress, config.SSHAddress)
+	changed("refresh-interval", current.RefreshInterval, config.RefreshInterval)
+	changed("spool-dir", current.SpoolDir, config.SpoolDir)
+	changed("spool-max-size", current.SpoolMaxSize, config.SpoolMaxSize)
+	changed("systemd-units", current.SystemdUnits, config.SystemdUnits)
+
+	from, to := collectorConfigOrEmpty(current), collectorConfigOrEmpty(config)
+	changed("collector-host", from.CollectorHost, to.CollectorHost)
+	changed("collector-port", from.CollectorPort, to.CollectorPort)
+	changed("enable-mtls", from.EnablemTLS, to.EnablemTLS)
+	changed("collector-tls", from.CollectorTLS, to.CollectorTLS)
+	changed("cert", from.Cert, to.Cert)
+	changed("key", from.Key, to.Key)
+	changed("ca", from.CA, to.CA)
+	changed("credential-file", from.CredentialFile, to.CredentialFile)
+	changed("agent-id", from.AgentID, to.AgentID)
+	if from.EnrollmentToken != to.EnrollmentToken {
+		changes = append(changes, "enrollment-token")
+	}
+
+	var ids []string
+	for id := range current.DiscoveriesConfig {
+		ids = append(ids, id)
+	}
+	for id := range config.DiscoveriesConfig {
+		if _, found := current.DiscoveriesConfig[id]; !found {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		from, to := current.DiscoveriesConfig[id], config.DiscoveriesConfig[id]
+		changed(fmt.Sprintf("discoveries.%s.enabled", id), from.Enabled, to.Enabled)
+		changed(fmt.Sprintf("discoveries.%s.interval", id), from.Interval, to.Interval)
+		changed(fmt.Sprintf("discoveries.%s.timeout", id), from.Timeout, to.Timeout)
+	}
+
+	return changes
+}
+
+func collectorConfigOrEmpty(config *Config) *collector.Config {
+	if config.CollectorConfig == nil {
+		return &collector.Config{}
+	}
+
+	return config.CollectorConfig
+}
@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/agent/discovery/collector"
+)
+
+func newReloadTestConfig() *Config {
+	return &Config{
+		InstanceName: "some-hostname",
+		SSHAddress:   "some-ssh-address",
+		DiscoveriesConfig: discovery.DiscoveriesConfig{
+			discovery.HostDiscoveryId:  {Enabled: true, Interval: 10 * time.Second, Timeout: 30 * time.Second},
+			discovery.CloudDiscoveryId: {Enabled: true, Interval: time.Minute, Timeout: 30 * time.Second},
+		},
+		CollectorConfig: &collector.Config{
+			CollectorHost:   "localhost",
+			CollectorPort:   8081,
+			EnrollmentToken: "some-token",
+		},
+		RefreshInterval: 15 * time.Minute,
+		StatusAddress:   "127.0.0.1:8702",
+	}
+}
+
+func TestConfigChanges(t *testing.T) {
+	current := newReloadTestConfig()
+	config := newReloadTestConfig()
+
+	assert.Empty(t, configChanges(current, config))
+
+	config.SSHAddress = "other-ssh-address"
+	config.CollectorConfig.CollectorPort = 8082
+	config.CollectorConfig.EnrollmentToken = "other-token"
+	config.DiscoveriesConfig[discovery.HostDiscoveryId] = discovery.DiscoveryConfig{Enabled: true, Interval: time.Minute, Timeout: 30 * time.Second}
+	config.DiscoveriesConfig[discovery.CloudDiscoveryId] = discovery.DiscoveryConfig{Enabled: false, Interval: time.Minute, Timeout: 30 * time.Second}
+
+	assert.Equal(t, []string{
+		"ssh-address from some-ssh-address to other-ssh-address",
+		"collector-port from 8081 to 8082",
+		"enrollment-token",
+		"discoveries.cloud_discovery.enabled from true to false",
+		"discoveries.host_discovery.interval from 10s to 1m0s",
+	}, configChanges(current, config))
+}
+
+func TestReload(t *testing.T) {
+	collectorClient := new(collector.MockClient)
+	a := NewAgentWithCollectorClient(newReloadTestConfig(), collectorClient)
+	a.status.record(discovery.HostDiscoveryId, time.Now(), "Host discovered", nil)
+
+	config := newReloadTestConfig()
+	config.InstanceName = "other-hostname"
+	config.StatusAddress = "127.0.0.1:9702"
+	config.DiscoveriesConfig[discovery.HostDiscoveryId] = discovery.DiscoveryConfig{Enabled: true, Interval: time.Minute, Timeout: 30 * time.Second}
+	config.DiscoveriesConfig[discovery.CloudDiscoveryId] = discovery.DiscoveryConfig{Enabled: false}
+
+	err := a.Reload(config)
+	assert.NoError(t, err)
+
+	// The collector configuration didn't change, so the client and its state are kept
+	reloadedClient, _ := a.getCollectorClients()
+	assert.Same(t, collectorClient, reloadedClient)
+
+	// The instance name and the status address are kept until the agent restarts
+	assert.Equal(t, "some-hostname", a.getConfig().InstanceName)
+	assert.Equal(t, "127.0.0.1:8702", a.getConfig().StatusAddress)
+
+	discoveries := a.getDiscoveries()
+	assert.Len(t, discoveries, 1)
+	assert.Equal(t, discovery.HostDiscoveryId, discoveries[0].GetId())
+	assert.Equal(t, time.Minute, discoveries[0].GetInterval())
+
+	statuses := a.status.list()
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, "1m0s", statuses[0].Interval)
+	assert.Equal(t, "Host discovered", statuses[0].LastResult)
+
+	assert.Len(t, a.rescheduled, 1)
+}
+
+func TestReloadUnchanged(t *testing.T) {
+	a := NewAgentWithCollectorClient(newReloadTestConfig(), new(collector.MockClient))
+
+	err := a.Reload(newReloadTestConfig())
+	assert.NoError(t, err)
+
+	assert.Len(t, a.rescheduled, 0)
+}
+
+func TestReloadInvalidCollectorConfig(t *testing.T) {
+	collectorClient := new(collector.MockClient)
+	a := NewAgentWithCollectorClient(newReloadTestConfig(), collectorClient)
+	current := a.getConfig()
+
+	config := newReloadTestConfig()
+	config.SSHAddress = "other-ssh-address"
+	config.CollectorConfig.EnablemTLS = true
+	config.CollectorConfig.CA = "/non/existent/ca.pem"
+
+	err := a.Reload(config)
+	assert.Error(t, err)
+
+	reloadedClient, _ := a.getCollectorClients()
+	assert.Same(t, collectorClient, reloadedClient)
+	assert.Same(t, current, a.getConfig())
+	assert.Len(t, a.rescheduled, 0)
+}
+
+func TestStartDiscoveriesRescheduled(t *testing.T) {
+	a := NewAgentWithCollectorClient(&Config{}, new(collector.MockClient))
+	a.discoveries = discovery.Registry{fakeDiscovery{id: "cloud_discovery", err: errors.New("failed")}}
+
+	done := make(chan struct{})
+	go func() {
+		a.startDiscoveries()
+		close(done)
+	}()
+
+	a.mutex.Lock()
+	a.discoveries = discovery.Registry{fakeDiscovery{id: "host_discovery"}}
+	a.mutex.Unlock()
+	a.rescheduled <- struct{}{}
+
+	assert.Eventually(t, func() bool {
+		for _, status := range a.status.list() {
+			if status.ID == "host_discovery" && status.LastRunAt != nil {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	a.Stop()
+	<-done
+}
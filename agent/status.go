@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/agent/metrics"
+	"github.com/trento-project/trento/version"
+)
+
+// Status is the state of the agent served by the status listener
+type Status struct {
+	InstanceName string             `json:"instance_name"`
+	Version      string             `json:"version"`
+	Discoveries  []*DiscoveryStatus `json:"discoveries"`
+}
+
+// DiscoveryStatus is the outcome of the last execution of a discovery
+type DiscoveryStatus struct {
+	ID                  string     `json:"id"`
+	Interval            string     `json:"interval"`
+	LastRunAt           *time.Time `json:"last_run_at"`
+	LastDurationSeconds float64    `json:"last_duration_seconds"`
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	LastResult          string     `json:"last_result"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// discoveriesStatus keeps the outcome of the last execution of every discovery
+type discoveriesStatus struct {
+	mutex    sync.Mutex
+	statuses map[string]*DiscoveryStatus
+}
+
+func newDiscoveriesStatus(discoveries discovery.Registry) *discoveriesStatus {
+	statuses := make(map[string]*DiscoveryStatus)
+	for _, d := range discoveries {
+		statuses[d.GetId()] = &DiscoveryStatus{
+			ID:       d.GetId(),
+			Interval: d.GetInterval().String(),
+		}
+	}
+
+	return &discoveriesStatus{statuses: statuses}
+}
+
+// reschedule keeps the status of the discoveries still enabled, updating their interval,
+// and drops the status of the disabled ones
+func (s *discoveriesStatus) reschedule(discoveries discovery.Registry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	statuses := make(map[string]*DiscoveryStatus)
+	for _, d := range discoveries {
+		status, found := s.statuses[d.GetId()]
+		if !found {
+			status = &DiscoveryStatus{ID: d.GetId()}
+		}
+		status.Interval = d.GetInterval().String()
+		statuses[d.GetId()] = status
+	}
+
+	s.statuses = statuses
+}
+
+// record updates the status and the metrics of a discovery after its execution
+func (s *discoveriesStatus) record(id string, startedAt time.Time, result string, err error) {
+	duration := time.Since(startedAt)
+
+	metrics.DiscoveryDuration.WithLabelValues(id).Observe(duration.Seconds())
+	if err != nil {
+		metrics.DiscoveryErrors.WithLabelValues(id).Inc()
+	} else {
+		metrics.DiscoveryLastSuccess.WithLabelValues(id).Set(float64(time.Now().Unix()))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status, found := s.statuses[id]
+	if !found {
+		status = &DiscoveryStatus{ID: id}
+		s.statuses[id] = status
+	}
+
+	status.LastRunAt = &startedAt
+	status.LastDurationSeconds = duration.Seconds()
+	status.LastResult = result
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	} else {
+		finishedAt := startedAt.Add(duration)
+		status.LastSuccessAt = &finishedAt
+	}
+}
+
+// list returns a copy of the discoveries status, sorted by discovery
+func (s *discoveriesStatus) list() []*DiscoveryStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	statuses := make([]*DiscoveryStatus, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		statusCopy := *status
+		statuses = append(statuses, &statusCopy)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ID < statuses[j].ID
+	})
+
+	return statuses
+}
+
+// newStatusHandler serves the Prometheus metrics of the agent on /metrics and its status on /status
+func (a *Agent) newStatusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(&Status{
+			InstanceName: a.getConfig().InstanceName,
+			Version:      version.Version,
+			Discoveries:  a.status.list(),
+		})
+		if err != nil {
+			log.Errorf("Error while writing the agent status: %s", err)
+		}
+	})
+
+	return mux
+}
+
+// startStatusServer serves the agent metrics and status until the agent is stopped
+func (a *Agent) startStatusServer() {
+	address := a.getConfig().StatusAddress
+	server := &http.Server{
+		Addr:              address,
+		Handler:           a.newStatusHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-a.ctx.Done()
+		server.Close()
+	}()
+
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		log.Errorf("Error while serving the agent status on %s: %s", address, err)
+	}
+}
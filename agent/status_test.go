@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/agent/discovery"
+	"github.com/trento-project/trento/agent/metrics"
+)
+
+type fakeDiscovery struct {
+	id  string
+	err error
+}
+
+func (d fakeDiscovery) GetId() string {
+	return d.id
+}
+
+func (d fakeDiscovery) GetInterval() time.Duration {
+	return time.Minute
+}
+
+func (d fakeDiscovery) GetTimeout() time.Duration {
+	return time.Second
+}
+
+func (d fakeDiscovery) Discover(ctx context.Context) (string, error) {
+	if d.err != nil {
+		return "", d.err
+	}
+
+	return "discovered", nil
+}
+
+func TestStatusHandler(t *testing.T) {
+	discoveries := discovery.Registry{fakeDiscovery{id: "host_discovery"}, fakeDiscovery{id: "cloud_discovery"}}
+	a := &Agent{
+		config:      &Config{InstanceName: "some-hostname"},
+		discoveries: discoveries,
+		status:      newDiscoveriesStatus(discoveries),
+	}
+
+	startedAt := time.Now().Add(-2 * time.Second)
+	a.status.record("host_discovery", startedAt, "Host discovered", nil)
+	a.status.record("host_discovery", startedAt, "", errors.New("failed"))
+
+	resp := httptest.NewRecorder()
+	a.newStatusHandler().ServeHTTP(resp, httptest.NewRequest("GET", "/status", nil))
+
+	assert.Equal(t, 200, resp.Code)
+
+	var status Status
+	err := json.Unmarshal(resp.Body.Bytes(), &status)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "some-hostname", status.InstanceName)
+	assert.Len(t, status.Discoveries, 2)
+
+	cloudStatus := status.Discoveries[0]
+	assert.Equal(t, "cloud_discovery", cloudStatus.ID)
+	assert.Equal(t, "1m0s", cloudStatus.Interval)
+	assert.Nil(t, cloudStatus.LastRunAt)
+
+	hostStatus := status.Discoveries[1]
+	assert.Equal(t, "host_discovery", hostStatus.ID)
+	assert.WithinDuration(t, startedAt, *hostStatus.LastRunAt, time.Millisecond)
+	assert.NotNil(t, hostStatus.LastSuccessAt)
+	assert.Equal(t, "failed", hostStatus.LastError)
+	assert.GreaterOrEqual(t, hostStatus.LastDurationSeconds, 2.0)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	discoveries := discovery.Registry{fakeDiscovery{id: "host_discovery"}}
+	a := &Agent{
+		config:      &Config{},
+		discoveries: discoveries,
+		status:      newDiscoveriesStatus(discoveries),
+	}
+
+	errorsBefore := testutil.ToFloat64(metrics.DiscoveryErrors.WithLabelValues("host_discovery"))
+	a.status.record("host_discovery", time.Now(), "", errors.New("failed"))
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(metrics.DiscoveryErrors.WithLabelValues("host_discovery")))
+
+	resp := httptest.NewRecorder()
+	a.newStatusHandler().ServeHTTP(resp, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, 200, resp.Code)
+	body := resp.Body.String()
+	assert.Contains(t, body, `trento_agent_discovery_errors_total{discovery="host_discovery"}`)
+	assert.Contains(t, body, `trento_agent_discovery_duration_seconds_count{discovery="host_discovery"}`)
+	assert.Contains(t, body, "trento_agent_spool_entries 0")
+}
@@ -13,20 +13,31 @@ import (
 
 	"github.com/trento-project/trento/agent"
 	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/internal/systemd"
 )
 
 func NewAgentCmd() *cobra.Command {
 	var sshAddress string
 	var discoveryPeriod int
+	var refreshInterval int
+	var spoolDir string
+	var spoolMaxSize int
+	var systemdUnits []string
+	var statusAddress string
 
 	var collectorHost string
 	var collectorPort int
 
 	var enablemTLS bool
+	var collectorTLS bool
 	var cert string
 	var key string
 	var ca string
 
+	var enrollmentToken string
+	var credentialFile string
+	var agentID string
+
 	agentCmd := &cobra.Command{
 		Use:   "agent",
 		Short: "Command tree related to the agent component",
@@ -47,17 +58,33 @@ func NewAgentCmd() *cobra.Command {
 
 	startCmd.Flags().StringVar(&sshAddress, "ssh-address", "", "The address to which the trento-agent should be reachable for ssh connection by the runner for check execution.")
 
-	startCmd.Flags().IntVarP(&discoveryPeriod, "discovery-period", "", 10, "Discovery mechanism loop period in seconds")
+	startCmd.Flags().IntVarP(&discoveryPeriod, "discovery-period", "", 10, "Discovery mechanism loop period in seconds, applied to every discovery. If not provided, each discovery runs at its own default interval")
+
+	startCmd.Flags().IntVar(&refreshInterval, "refresh-interval", 900, "Interval in seconds after which unchanged discovery data is published anyway. 0 publishes every discovery result")
+
+	startCmd.Flags().StringVar(&spoolDir, "spool-dir", "/var/lib/trento/spool", "Directory where the data the collector could not receive is kept until it is reachable again. An empty value disables the spool")
+	startCmd.Flags().IntVar(&spoolMaxSize, "spool-max-size", 50, "Maximum size of the spool in megabytes, the oldest data is dropped when it's full")
+
+	startCmd.Flags().StringSliceVar(&systemdUnits, "systemd-units", systemd.DefaultUnits, "Systemd units whose state is discovered, e.g. --systemd-units pacemaker.service,sbd.service")
+
+	startCmd.Flags().StringVar(&statusAddress, "status-address", "", "Address of the local HTTP listener serving the agent Prometheus metrics on /metrics and its status on /status, e.g. 127.0.0.1:8702. An empty value disables it")
 
 	startCmd.Flags().StringVar(&collectorHost, "collector-host", "localhost", "Data Collector host")
 	startCmd.Flags().IntVar(&collectorPort, "collector-port", 8081, "Data Collector port")
 
 	startCmd.Flags().BoolVar(&enablemTLS, "enable-mtls", false, "Enable mTLS authentication between server and agent")
+	startCmd.Flags().BoolVar(&collectorTLS, "collector-tls", false, "Connect to the Data Collector over TLS, verifying its certificate with --ca, without a client certificate")
 	startCmd.Flags().StringVar(&cert, "cert", "", "mTLS client certificate")
 	startCmd.Flags().StringVar(&key, "key", "", "mTLS client key")
-	startCmd.Flags().StringVar(&ca, "ca", "", "mTLS Certificate Authority")
+	startCmd.Flags().StringVar(&ca, "ca", "", "Certificate Authority of the Data Collector certificate")
+
+	startCmd.Flags().StringVar(&enrollmentToken, "enrollment-token", "", "Token exchanged for the credential the agent authenticates to the Data Collector with, needed only until the agent is enrolled. Requires --collector-tls or --enable-mtls")
+	startCmd.Flags().StringVar(&credentialFile, "credential-file", "/var/lib/trento/agent-credential", "File where the credential obtained by enrolling the agent is kept")
+
+	startCmd.Flags().StringVar(&agentID, "agent-id", "", "UUID identifying the agent, overriding the one derived from /etc/machine-id. Set it on hosts cloned with the same machine ID")
 
 	agentCmd.AddCommand(startCmd)
+	agentCmd.AddCommand(NewDiscoverCmd())
 
 	return agentCmd
 }
@@ -66,7 +93,7 @@ func start(*cobra.Command, []string) {
 	var err error
 
 	signals := make(chan os.Signal, 1)
-	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	config, err := LoadConfig()
 	if err != nil {
@@ -79,11 +106,18 @@ func start(*cobra.Command, []string) {
 	}
 
 	go func() {
-		quit := <-signals
-		log.Printf("Caught %s signal!", quit)
-
-		log.Println("Stopping the agent...")
-		a.Stop()
+		for sig := range signals {
+			log.Printf("Caught %s signal!", sig)
+
+			if sig == syscall.SIGHUP {
+				reload(a)
+				continue
+			}
+
+			log.Println("Stopping the agent...")
+			a.Stop()
+			return
+		}
 	}()
 
 	log.Println("Starting the Console Agent...")
@@ -92,3 +126,20 @@ func start(*cobra.Command, []string) {
 		log.Fatal("Failed to start the agent: ", err)
 	}
 }
+
+// reload re-reads the configuration and applies it to the running agent,
+// which keeps the current one when the new configuration is not valid
+func reload(a *agent.Agent) {
+	log.Println("Reloading the agent configuration...")
+
+	config, err := ReloadConfig()
+	if err != nil {
+		log.Errorf("Invalid configuration, keeping the current one: %s", err)
+		return
+	}
+
+	err = a.Reload(config)
+	if err != nil {
+		log.Errorf("Failed to apply the configuration, keeping the current one: %s", err)
+	}
+}
@@ -5,14 +5,18 @@ import (
 	"os"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"github.com/trento-project/trento/agent"
+	"github.com/trento-project/trento/agent/discovery"
 	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/internal"
 )
 
 func LoadConfig() (*agent.Config, error) {
 	enablemTLS := viper.GetBool("enable-mtls")
+	collectorTLS := viper.GetBool("collector-tls")
 	cert := viper.GetString("cert")
 	key := viper.GetString("key")
 	ca := viper.GetString("ca")
@@ -34,6 +38,16 @@ func LoadConfig() (*agent.Config, error) {
 		}
 	}
 
+	if collectorTLS && ca == "" {
+		return nil, errors.New("you must provide a CA ssl certificate to enable collector-tls")
+	}
+
+	// The enrollment token and the credential it's exchanged for must not travel in clear text
+	enrollmentToken := viper.GetString("enrollment-token")
+	if enrollmentToken != "" && !enablemTLS && !collectorTLS {
+		return nil, errors.New("enrollment-token requires collector-tls or enable-mtls")
+	}
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not read the hostname")
@@ -44,17 +58,131 @@ func LoadConfig() (*agent.Config, error) {
 		return nil, errors.New("ssh-address is required, cannot start agent")
 	}
 
+	discoveriesConfig, err := LoadDiscoveriesConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	spoolMaxSize := viper.GetInt64("spool-max-size")
+	if spoolMaxSize <= 0 {
+		return nil, fmt.Errorf("invalid spool-max-size %d, it must be positive", spoolMaxSize)
+	}
+
+	agentID := viper.GetString("agent-id")
+	if agentID != "" {
+		if _, err := uuid.Parse(agentID); err != nil {
+			return nil, fmt.Errorf("invalid agent-id %s, it must be a UUID", agentID)
+		}
+	}
+
 	return &agent.Config{
 		CollectorConfig: &collector.Config{
-			CollectorHost: viper.GetString("collector-host"),
-			CollectorPort: viper.GetInt("collector-port"),
-			EnablemTLS:    enablemTLS,
-			Cert:          cert,
-			Key:           key,
-			CA:            ca,
+			CollectorHost:   viper.GetString("collector-host"),
+			CollectorPort:   viper.GetInt("collector-port"),
+			EnablemTLS:      enablemTLS,
+			CollectorTLS:    collectorTLS,
+			Cert:            cert,
+			Key:             key,
+			CA:              ca,
+			EnrollmentToken: enrollmentToken,
+			CredentialFile:  viper.GetString("credential-file"),
+			AgentID:         agentID,
 		},
-		InstanceName:    hostname,
-		SSHAddress:      sshAddress,
-		DiscoveryPeriod: time.Duration(viper.GetInt("discovery-period")) * time.Second,
+		InstanceName:      hostname,
+		SSHAddress:        sshAddress,
+		DiscoveriesConfig: discoveriesConfig,
+		RefreshInterval:   time.Duration(viper.GetInt("refresh-interval")) * time.Second,
+		SpoolDir:          viper.GetString("spool-dir"),
+		SpoolMaxSize:      spoolMaxSize * 1024 * 1024,
+		SystemdUnits:      viper.GetStringSlice("systemd-units"),
+		StatusAddress:     viper.GetString("status-address"),
 	}, nil
 }
+
+// ReloadConfig reads the configuration file again and returns the resulting agent configuration.
+// The flags and the environment variables keep their precedence over the file
+func ReloadConfig() (*agent.Config, error) {
+	err := viper.ReadInConfig()
+	if _, notFound := err.(viper.ConfigFileNotFoundError); err != nil && !notFound {
+		return nil, errors.Wrap(err, "could not read the configuration file")
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	internal.SetLogLevel(viper.GetString("log-level"))
+
+	return config, nil
+}
+
+// LoadDiscoverConfig returns the configuration of an agent running the discoveries locally,
+// without any collector. When the discovery flag lists some discovery IDs, only those are enabled
+func LoadDiscoverConfig() (*agent.Config, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the hostname")
+	}
+
+	discoveriesConfig, err := LoadDiscoveriesConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	selected := viper.GetStringSlice("discovery")
+	if len(selected) > 0 {
+		for _, id := range selected {
+			if _, found := discoveriesConfig[id]; !found {
+				return nil, fmt.Errorf("unknown discovery %s", id)
+			}
+		}
+
+		for id, discoveryConfig := range discoveriesConfig {
+			discoveryConfig.Enabled = internal.Contains(selected, id)
+			discoveriesConfig[id] = discoveryConfig
+		}
+	}
+
+	return &agent.Config{
+		InstanceName:      hostname,
+		SSHAddress:        viper.GetString("ssh-address"),
+		DiscoveriesConfig: discoveriesConfig,
+		SystemdUnits:      viper.GetStringSlice("systemd-units"),
+	}, nil
+}
+
+// LoadDiscoveriesConfig returns the configuration of the discoveries, starting from their defaults.
+// The legacy discovery-period, when provided, applies to every discovery, while the
+// discoveries.<id>.enabled, discoveries.<id>.interval and discoveries.<id>.timeout keys configure a single one.
+func LoadDiscoveriesConfig() (discovery.DiscoveriesConfig, error) {
+	discoveriesConfig := discovery.DefaultDiscoveriesConfig()
+
+	for id, discoveryConfig := range discoveriesConfig {
+		if viper.IsSet("discovery-period") {
+			discoveryConfig.Interval = time.Duration(viper.GetInt("discovery-period")) * time.Second
+		}
+
+		key := fmt.Sprintf("discoveries.%s", id)
+		if viper.IsSet(key + ".enabled") {
+			discoveryConfig.Enabled = viper.GetBool(key + ".enabled")
+		}
+		if viper.IsSet(key + ".interval") {
+			discoveryConfig.Interval = viper.GetDuration(key + ".interval")
+		}
+		if viper.IsSet(key + ".timeout") {
+			discoveryConfig.Timeout = viper.GetDuration(key + ".timeout")
+		}
+
+		if discoveryConfig.Interval <= 0 {
+			return nil, fmt.Errorf("invalid interval %s for discovery %s, it must be positive", discoveryConfig.Interval, id)
+		}
+		if discoveryConfig.Timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %s for discovery %s, it must be positive", discoveryConfig.Timeout, id)
+		}
+
+		discoveriesConfig[id] = discoveryConfig
+	}
+
+	return discoveriesConfig, nil
+}
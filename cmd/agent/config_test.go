@@ -2,13 +2,18 @@ package agent
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/agent"
+	"github.com/trento-project/trento/agent/discovery"
 	"github.com/trento-project/trento/agent/discovery/collector"
 )
 
@@ -26,7 +31,8 @@ func (suite *AgentCmdTestSuite) SetupTest() {
 
 	cmd := NewAgentCmd()
 
-	cmd.Commands()[0].Run = func(cmd *cobra.Command, args []string) {
+	startCmd, _, _ := cmd.Find([]string{"start"})
+	startCmd.Run = func(cmd *cobra.Command, args []string) {
 		// do nothing
 	}
 
@@ -46,14 +52,31 @@ func (suite *AgentCmdTestSuite) TearDownTest() {
 	expectedConfig := &agent.Config{
 		InstanceName:    "some-hostname",
 		SSHAddress:      "some-ssh-address",
-		DiscoveryPeriod: 10 * time.Second,
+		RefreshInterval: 5 * time.Minute,
+		SpoolDir:        "/some/spool",
+		SpoolMaxSize:    10 * 1024 * 1024,
+		SystemdUnits:    []string{"pacemaker.service", "sbd.service"},
+		StatusAddress:   "127.0.0.1:8702",
+		DiscoveriesConfig: discovery.DiscoveriesConfig{
+			discovery.HostDiscoveryId:         {Enabled: true, Interval: 10 * time.Second, Timeout: 30 * time.Second},
+			discovery.ClusterDiscoveryId:      {Enabled: true, Interval: 10 * time.Second, Timeout: 30 * time.Second},
+			discovery.CloudDiscoveryId:        {Enabled: true, Interval: 10 * time.Second, Timeout: 30 * time.Second},
+			discovery.SAPDiscoveryId:          {Enabled: true, Interval: 10 * time.Second, Timeout: 2 * time.Minute},
+			discovery.SubscriptionDiscoveryId: {Enabled: true, Interval: 10 * time.Second, Timeout: 1 * time.Minute},
+			discovery.SystemdDiscoveryId:      {Enabled: true, Interval: 10 * time.Second, Timeout: 30 * time.Second},
+			discovery.TuningDiscoveryId:       {Enabled: true, Interval: 10 * time.Second, Timeout: 1 * time.Minute},
+		},
 		CollectorConfig: &collector.Config{
-			CollectorHost: "localhost",
-			CollectorPort: 1337,
-			EnablemTLS:    true,
-			Cert:          "some-cert",
-			Key:           "some-key",
-			CA:            "some-ca",
+			CollectorHost:   "localhost",
+			CollectorPort:   1337,
+			EnablemTLS:      true,
+			CollectorTLS:    true,
+			Cert:            "some-cert",
+			Key:             "some-key",
+			CA:              "some-ca",
+			EnrollmentToken: "some-token",
+			CredentialFile:  "/some/credential",
+			AgentID:         "a4d1e7c2-5b8f-4e3a-9c6d-2f1b0e8a7d35",
 		},
 	}
 
@@ -69,26 +92,181 @@ func (suite *AgentCmdTestSuite) TestConfigFromFlags() {
 		"start",
 		"--ssh-address=some-ssh-address",
 		"--discovery-period=10",
+		"--refresh-interval=300",
+		"--status-address=127.0.0.1:8702",
+		"--spool-dir=/some/spool",
+		"--spool-max-size=10",
+		"--systemd-units=pacemaker.service,sbd.service",
 		"--collector-host=localhost",
 		"--collector-port=1337",
 		"--enable-mtls",
+		"--collector-tls",
 		"--cert=some-cert",
 		"--key=some-key",
 		"--ca=some-ca",
+		"--enrollment-token=some-token",
+		"--credential-file=/some/credential",
+		"--agent-id=a4d1e7c2-5b8f-4e3a-9c6d-2f1b0e8a7d35",
 	})
 }
 
 func (suite *AgentCmdTestSuite) TestConfigFromEnv() {
 	os.Setenv("TRENTO_SSH_ADDRESS", "some-ssh-address")
 	os.Setenv("TRENTO_DISCOVERY_PERIOD", "10")
+	os.Setenv("TRENTO_REFRESH_INTERVAL", "300")
+	os.Setenv("TRENTO_STATUS_ADDRESS", "127.0.0.1:8702")
+	os.Setenv("TRENTO_SPOOL_DIR", "/some/spool")
+	os.Setenv("TRENTO_SPOOL_MAX_SIZE", "10")
+	os.Setenv("TRENTO_SYSTEMD_UNITS", "pacemaker.service sbd.service")
 	os.Setenv("TRENTO_COLLECTOR_HOST", "localhost")
 	os.Setenv("TRENTO_COLLECTOR_PORT", "1337")
 	os.Setenv("TRENTO_ENABLE_MTLS", "true")
+	os.Setenv("TRENTO_COLLECTOR_TLS", "true")
 	os.Setenv("TRENTO_CERT", "some-cert")
 	os.Setenv("TRENTO_KEY", "some-key")
 	os.Setenv("TRENTO_CA", "some-ca")
+	os.Setenv("TRENTO_ENROLLMENT_TOKEN", "some-token")
+	os.Setenv("TRENTO_CREDENTIAL_FILE", "/some/credential")
+	os.Setenv("TRENTO_AGENT_ID", "a4d1e7c2-5b8f-4e3a-9c6d-2f1b0e8a7d35")
 }
 
 func (suite *AgentCmdTestSuite) TestConfigFromFile() {
 	os.Setenv("TRENTO_CONFIG", "../../test/fixtures/config/agent.yaml")
 }
+
+func TestLoadConfigInvalidAgentID(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("ssh-address", "some-ssh-address")
+	viper.Set("spool-max-size", 10)
+	viper.Set("agent-id", "not-a-uuid")
+
+	_, err := LoadConfig()
+	assert.EqualError(t, err, "invalid agent-id not-a-uuid, it must be a UUID")
+}
+
+func TestLoadConfigEnrollmentTokenWithoutTLS(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("ssh-address", "some-ssh-address")
+	viper.Set("spool-max-size", 10)
+	viper.Set("enrollment-token", "some-token")
+
+	_, err := LoadConfig()
+	assert.EqualError(t, err, "enrollment-token requires collector-tls or enable-mtls")
+}
+
+func TestLoadConfigCollectorTLSWithoutCA(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("ssh-address", "some-ssh-address")
+	viper.Set("spool-max-size", 10)
+	viper.Set("collector-tls", true)
+
+	_, err := LoadConfig()
+	assert.EqualError(t, err, "you must provide a CA ssl certificate to enable collector-tls")
+}
+
+func TestLoadDiscoveriesConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("discoveries.sap_system_discovery.interval", "5m")
+	viper.Set("discoveries.sap_system_discovery.timeout", "4m")
+	viper.Set("discoveries.subscription_discovery.enabled", false)
+
+	config, err := LoadDiscoveriesConfig()
+	assert.NoError(t, err)
+
+	expectedConfig := discovery.DefaultDiscoveriesConfig()
+	expectedConfig[discovery.SAPDiscoveryId] = discovery.DiscoveryConfig{Enabled: true, Interval: 5 * time.Minute, Timeout: 4 * time.Minute}
+	expectedConfig[discovery.SubscriptionDiscoveryId] = discovery.DiscoveryConfig{Enabled: false, Interval: 5 * time.Minute, Timeout: 1 * time.Minute}
+
+	assert.Equal(t, expectedConfig, config)
+}
+
+func TestLoadDiscoveriesConfigInvalidInterval(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("discoveries.host_discovery.interval", "-1s")
+
+	_, err := LoadDiscoveriesConfig()
+	assert.EqualError(t, err, "invalid interval -1s for discovery host_discovery, it must be positive")
+}
+
+func TestLoadDiscoveriesConfigInvalidTimeout(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("discoveries.cloud_discovery.timeout", "0s")
+
+	_, err := LoadDiscoveriesConfig()
+	assert.EqualError(t, err, "invalid timeout 0s for discovery cloud_discovery, it must be positive")
+}
+
+func TestLoadDiscoverConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("discovery", []string{"host_discovery", "cloud_discovery"})
+	viper.Set("discoveries.cloud_discovery.enabled", false)
+
+	config, err := LoadDiscoverConfig()
+	assert.NoError(t, err)
+
+	for id, discoveryConfig := range config.DiscoveriesConfig {
+		expected := id == discovery.HostDiscoveryId || id == discovery.CloudDiscoveryId
+		assert.Equal(t, expected, discoveryConfig.Enabled, id)
+	}
+	assert.Nil(t, config.CollectorConfig)
+}
+
+func TestLoadDiscoverConfigUnknownDiscovery(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("discovery", []string{"unknown_discovery"})
+
+	_, err := LoadDiscoverConfig()
+	assert.EqualError(t, err, "unknown discovery unknown_discovery")
+}
+
+func TestReloadConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	configFile := filepath.Join(t.TempDir(), "agent.yaml")
+	viper.SetConfigType("yaml")
+	viper.SetConfigFile(configFile)
+
+	err := ioutil.WriteFile(configFile, []byte("ssh-address: some-ssh-address\nspool-max-size: 10\n"), 0644)
+	assert.NoError(t, err)
+
+	config, err := ReloadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "some-ssh-address", config.SSHAddress)
+
+	err = ioutil.WriteFile(configFile, []byte("ssh-address: other-ssh-address\nspool-max-size: 10\ndiscoveries:\n  host_discovery:\n    interval: 1m\n"), 0644)
+	assert.NoError(t, err)
+
+	config, err = ReloadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "other-ssh-address", config.SSHAddress)
+	assert.Equal(t, time.Minute, config.DiscoveriesConfig[discovery.HostDiscoveryId].Interval)
+
+	err = ioutil.WriteFile(configFile, []byte("ssh-address: other-ssh-address\nspool-max-size: 0\n"), 0644)
+	assert.NoError(t, err)
+
+	_, err = ReloadConfig()
+	assert.EqualError(t, err, "invalid spool-max-size 0, it must be positive")
+
+	err = ioutil.WriteFile(configFile, []byte("ssh-address: [unterminated\n"), 0644)
+	assert.NoError(t, err)
+
+	_, err = ReloadConfig()
+	assert.Error(t, err)
+}
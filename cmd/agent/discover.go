@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/trento-project/trento/agent"
+	"github.com/trento-project/trento/agent/discovery/collector"
+	"github.com/trento-project/trento/internal/systemd"
+)
+
+func NewDiscoverCmd() *cobra.Command {
+	var once bool
+	var discoveries []string
+	var outputDir string
+	var sshAddress string
+	var systemdUnits []string
+
+	discoverCmd := &cobra.Command{
+		Use:   "discover",
+		Short: "Run the discoveries printing the data the agent would send, without publishing it",
+		Long: `Run the discoveries printing the data the agent would send to the collector, without publishing it.
+Neither the collector connectivity nor the machine ID are required.
+The payloads are printed to the standard output, or written to a <discovery type>.json file in the output directory`,
+		Run: discover,
+	}
+
+	discoverCmd.Flags().BoolVar(&once, "once", false, "Run every discovery once and exit. Otherwise the discoveries run on their schedule until interrupted")
+	discoverCmd.Flags().StringSliceVar(&discoveries, "discovery", nil, "Only run the given discoveries, e.g. --discovery host_discovery,cloud_discovery")
+	discoverCmd.Flags().StringVar(&outputDir, "output-dir", "", "Directory where the payloads are written instead of the standard output")
+	discoverCmd.Flags().StringVar(&sshAddress, "ssh-address", "", "The address to which the trento-agent should be reachable for ssh connection, as reported by the host discovery")
+
+	discoverCmd.Flags().StringSliceVar(&systemdUnits, "systemd-units", systemd.DefaultUnits, "Systemd units whose state is discovered, e.g. --systemd-units pacemaker.service,sbd.service")
+
+	return discoverCmd
+}
+
+func discover(cmd *cobra.Command, _ []string) {
+	config, err := LoadDiscoverConfig()
+	if err != nil {
+		log.Fatal("Failed to create the agent configuration: ", err)
+	}
+
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+	dryRunClient, err := collector.NewDryRunClient(cmd.OutOrStdout(), outputDir)
+	if err != nil {
+		log.Fatal("Failed to create the output directory: ", err)
+	}
+
+	a := agent.NewAgentWithCollectorClient(config, dryRunClient)
+
+	once, _ := cmd.Flags().GetBool("once")
+	if once {
+		err = a.DiscoverOnce()
+		if err != nil {
+			log.Fatal("Discovery failed: ", err)
+		}
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		quit := <-signals
+		log.Printf("Caught %s signal!", quit)
+		a.Stop()
+	}()
+
+	err = a.Start()
+	if err != nil {
+		log.Fatal("Failed to run the discoveries: ", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 func LoadConfig() (*web.Config, error) {
 	enablemTLS := viper.GetBool("enable-mtls")
+	collectorTLS := viper.GetBool("collector-tls")
 	cert := viper.GetString("cert")
 	key := viper.GetString("key")
 	ca := viper.GetString("ca")
@@ -33,15 +34,33 @@ func LoadConfig() (*web.Config, error) {
 		}
 	}
 
+	if collectorTLS && (cert == "" || key == "") {
+		return nil, errors.New("you must provide a server ssl certificate and key to enable collector-tls")
+	}
+
+	// The enrollment tokens and the agent credentials must not travel in clear text
+	enableAgentAuth := viper.GetBool("enable-agent-auth")
+	if enableAgentAuth && !enablemTLS && !collectorTLS {
+		return nil, errors.New("enable-agent-auth requires collector-tls or enable-mtls")
+	}
+
+	adminToken := viper.GetString("admin-token")
+	if enableAgentAuth && adminToken == "" {
+		return nil, errors.New("enable-agent-auth requires admin-token to manage the enrollment")
+	}
+
 	return &web.Config{
-		Host:          viper.GetString("host"),
-		Port:          viper.GetInt("port"),
-		CollectorPort: viper.GetInt("collector-port"),
-		EnablemTLS:    enablemTLS,
-		Cert:          cert,
-		Key:           key,
-		CA:            ca,
-		DBConfig:      dbCmd.LoadConfig(),
+		Host:            viper.GetString("host"),
+		Port:            viper.GetInt("port"),
+		CollectorPort:   viper.GetInt("collector-port"),
+		EnablemTLS:      enablemTLS,
+		CollectorTLS:    collectorTLS,
+		Cert:            cert,
+		Key:             key,
+		CA:              ca,
+		EnableAgentAuth: enableAgentAuth,
+		AdminToken:      adminToken,
+		DBConfig:        dbCmd.LoadConfig(),
 		GrafanaConfig: &grafana.Config{
 			PublicURL: viper.GetString("grafana-public-url"),
 			ApiURL:    viper.GetString("grafana-api-url"),
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/internal/db"
 	"github.com/trento-project/trento/internal/grafana"
@@ -44,13 +46,16 @@ func (suite *WebCmdTestSuite) TearDownTest() {
 	suite.cmd.Execute()
 
 	expectedConfig := &web.Config{
-		Host:          "some-host",
-		Port:          1337,
-		CollectorPort: 1338,
-		EnablemTLS:    true,
-		Cert:          "some-cert",
-		Key:           "some-key",
-		CA:            "some-ca",
+		Host:            "some-host",
+		Port:            1337,
+		CollectorPort:   1338,
+		EnablemTLS:      true,
+		CollectorTLS:    true,
+		Cert:            "some-cert",
+		Key:             "some-key",
+		CA:              "some-ca",
+		EnableAgentAuth: true,
+		AdminToken:      "some-admin-token",
 		DBConfig: &db.Config{
 			Host:     "some-db-host",
 			Port:     6543,
@@ -79,9 +84,12 @@ func (suite *WebCmdTestSuite) TestConfigFromFlags() {
 		"--port=1337",
 		"--collector-port=1338",
 		"--enable-mtls",
+		"--collector-tls",
 		"--cert=some-cert",
 		"--key=some-key",
 		"--ca=some-ca",
+		"--enable-agent-auth",
+		"--admin-token=some-admin-token",
 		"--db-host=some-db-host",
 		"--db-port=6543",
 		"--db-user=postgres",
@@ -100,9 +108,12 @@ func (suite *WebCmdTestSuite) TestConfigFromEnv() {
 	os.Setenv("TRENTO_PORT", "1337")
 	os.Setenv("TRENTO_COLLECTOR_PORT", "1338")
 	os.Setenv("TRENTO_ENABLE_MTLS", "true")
+	os.Setenv("TRENTO_COLLECTOR_TLS", "true")
 	os.Setenv("TRENTO_CERT", "some-cert")
 	os.Setenv("TRENTO_KEY", "some-key")
 	os.Setenv("TRENTO_CA", "some-ca")
+	os.Setenv("TRENTO_ENABLE_AGENT_AUTH", "true")
+	os.Setenv("TRENTO_ADMIN_TOKEN", "some-admin-token")
 	os.Setenv("TRENTO_DB_HOST", "some-db-host")
 	os.Setenv("TRENTO_DB_PORT", "6543")
 	os.Setenv("TRENTO_DB_USER", "postgres")
@@ -118,3 +129,37 @@ func (suite *WebCmdTestSuite) TestConfigFromEnv() {
 func (suite *WebCmdTestSuite) TestConfigFromFile() {
 	os.Setenv("TRENTO_CONFIG", "../../test/fixtures/config/web.yaml")
 }
+
+func TestLoadConfigAgentAuthWithoutTLS(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("enable-agent-auth", true)
+
+	_, err := LoadConfig()
+	assert.EqualError(t, err, "enable-agent-auth requires collector-tls or enable-mtls")
+}
+
+func TestLoadConfigAgentAuthWithoutAdminToken(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("enable-agent-auth", true)
+	viper.Set("collector-tls", true)
+	viper.Set("cert", "some-cert")
+	viper.Set("key", "some-key")
+
+	_, err := LoadConfig()
+	assert.EqualError(t, err, "enable-agent-auth requires admin-token to manage the enrollment")
+}
+
+func TestLoadConfigCollectorTLSWithoutCert(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("collector-tls", true)
+	viper.Set("cert", "some-cert")
+
+	_, err := LoadConfig()
+	assert.EqualError(t, err, "you must provide a server ssl certificate and key to enable collector-tls")
+}
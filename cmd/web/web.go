@@ -42,9 +42,12 @@ func addServeCmd(webCmd *cobra.Command) {
 
 	var collectorPort int
 	var enablemTLS bool
+	var collectorTLS bool
 	var cert string
 	var key string
 	var ca string
+	var enableAgentAuth bool
+	var adminToken string
 
 	var grafanaPublicURL string
 	var grafanaApiURL string
@@ -64,9 +67,12 @@ func addServeCmd(webCmd *cobra.Command) {
 
 	serveCmd.Flags().IntVar(&collectorPort, "collector-port", 8081, "The port for the data collector service to listen on")
 	serveCmd.Flags().BoolVar(&enablemTLS, "enable-mtls", false, "Enable mTLS authentication between server and agents")
+	serveCmd.Flags().BoolVar(&collectorTLS, "collector-tls", false, "Serve the data collector over TLS with --cert and --key, without requiring a client certificate from the agents")
 	serveCmd.Flags().StringVar(&cert, "cert", "", "mTLS server certificate")
 	serveCmd.Flags().StringVar(&key, "key", "", "mTLS server key")
 	serveCmd.Flags().StringVar(&ca, "ca", "", "mTLS Certificate Authority")
+	serveCmd.Flags().BoolVar(&enableAgentAuth, "enable-agent-auth", false, "Require the agents to authenticate to the collector with the credential obtained with an enrollment token. Requires --collector-tls or --enable-mtls")
+	serveCmd.Flags().StringVar(&adminToken, "admin-token", "", "Token the enrollment API requests must present as a bearer token, to create enrollment tokens and revoke or reset the agent credentials. The enrollment API is disabled without it")
 
 	serveCmd.Flags().StringVar(&grafanaPublicURL, "grafana-public-url", "", "Browsable Grafana URL, if not provided, the API url will be used. This is the base url for iframes embedding.")
 	serveCmd.Flags().StringVar(&grafanaApiURL, "grafana-api-url", "http://localhost:3000", "Grafana API URL")
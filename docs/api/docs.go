@@ -245,6 +245,52 @@ var doc = `{
                 }
             }
         },
+        "/clusters/{cluster_id}/health": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the health of a cluster, with its partial healths and the reasons they are not passing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster id",
+                        "name": "cluster_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthState"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/clusters/{cluster_id}/results": {
             "get": {
                 "produces": [
@@ -280,6 +326,73 @@ var doc = `{
                 }
             }
         },
+        "/clusters/{id}/commands": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ask the agents of all the cluster nodes to run a discovery right away",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The discovery to run",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONRunDiscoveryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AgentCommand"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/clusters/{id}/tags": {
             "post": {
                 "consumes": [
@@ -374,13 +487,477 @@ var doc = `{
                         "description": "No Content",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/commands/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve an agent command and its status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Command id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AgentCommand"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/databases/{id}/tags": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add tag to a HANA database",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Database id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The tag to create",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONTag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/databases/{id}/tags/{tag}": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a specific tag that belongs to a HANA database",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Database id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollment/agents": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the enrolled agents and whether their credential was revoked",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AgentCredential"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollment/agents/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "summary": "Revoke the collector credential of an agent, it can't publish data nor enroll again until it is reset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollment/agents/{id}/reset": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "summary": "Reset the collector credential of an agent, it can enroll again with a new token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollment/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the enrollment tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EnrollmentToken"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a token the agents exchange for their own collector credential",
+                "parameters": [
+                    {
+                        "description": "A single use token, an expiring one or both",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONEnrollmentTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.EnrollmentToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrollment/tokens/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "summary": "Delete an enrollment token, the agents already enrolled with it keep their credential",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Enrollment token id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/identity_conflicts": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the agent IDs reported by different hosts, e.g. hosts cloned with the same machine ID",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.HostIdentityConflict"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{id}/commands": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the most recent commands queued for the agent of a host",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AgentCommand"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/databases/{id}/tags": {
+            },
             "post": {
                 "consumes": [
                     "application/json"
@@ -388,30 +965,30 @@ var doc = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Add tag to a HANA database",
+                "summary": "Ask the agent of a host to run a discovery right away",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Database id",
+                        "description": "Host id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "The tag to create",
+                        "description": "The discovery to run",
                         "name": "Body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
+                            "$ref": "#/definitions/web.JSONRunDiscoveryRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/web.JSONTag"
+                            "$ref": "#/definitions/models.AgentCommand"
                         }
                     },
                     "400": {
@@ -444,37 +1021,50 @@ var doc = `{
                 }
             }
         },
-        "/databases/{id}/tags/{tag}": {
-            "delete": {
+        "/hosts/{id}/systemd_units": {
+            "get": {
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete a specific tag that belongs to a HANA database",
+                "summary": "Retrieve the state of the systemd units discovered on a host",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Database id",
+                        "description": "Host id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Tag",
-                        "name": "tag",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SystemdUnit"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
@@ -580,6 +1170,52 @@ var doc = `{
                 }
             }
         },
+        "/hosts/{id}/tuning": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieve the SAP tuning state of a host, made by saptune or sapconf",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HostTuning"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/prometheus/targets": {
             "get": {
                 "produces": [
@@ -771,6 +1407,52 @@ var doc = `{
         }
     },
     "definitions": {
+        "models.AgentCommand": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "discovery_type": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "picked_up_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AgentCredential": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
         "models.Check": {
             "type": "object",
             "properties": {
@@ -829,6 +1511,44 @@ var doc = `{
                 }
             }
         },
+        "models.EnrollmentToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "single_use": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "description": "The token is returned only once, when it's created",
+                    "type": "string"
+                },
+                "used_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HealthState": {
+            "type": "object",
+            "properties": {
+                "health": {
+                    "type": "string"
+                },
+                "partial_healths": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.PartialHealth"
+                    }
+                }
+            }
+        },
         "models.HostConnection": {
             "type": "object",
             "properties": {
@@ -843,6 +1563,92 @@ var doc = `{
                 }
             }
         },
+        "models.HostIdentity": {
+            "type": "object",
+            "properties": {
+                "hostname": {
+                    "type": "string"
+                },
+                "ip_addresses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "last_reported_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HostIdentityConflict": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string"
+                },
+                "detected_at": {
+                    "type": "string"
+                },
+                "identities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HostIdentity"
+                    }
+                }
+            }
+        },
+        "models.HostTuning": {
+            "type": "object",
+            "properties": {
+                "applied_notes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "compliant": {
+                    "type": "boolean"
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sapconf_active": {
+                    "type": "boolean"
+                },
+                "sapconf_profile": {
+                    "type": "string"
+                },
+                "sapconf_version": {
+                    "type": "string"
+                },
+                "saptune_configured_version": {
+                    "type": "string"
+                },
+                "saptune_version": {
+                    "type": "string"
+                },
+                "solution": {
+                    "type": "string"
+                },
+                "tool": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PartialHealth": {
+            "type": "object",
+            "properties": {
+                "health": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
         "models.SAPSystemHealthSummary": {
             "type": "object",
             "properties": {
@@ -866,6 +1672,29 @@ var doc = `{
                 }
             }
         },
+        "models.SystemdUnit": {
+            "type": "object",
+            "properties": {
+                "active_state": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "load_state": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sub_state": {
+                    "type": "string"
+                },
+                "unit_file_state": {
+                    "type": "string"
+                }
+            }
+        },
         "web.JSONCheck": {
             "type": "object",
             "required": [
@@ -982,6 +1811,19 @@ var doc = `{
                 }
             }
         },
+        "web.JSONEnrollmentTokenRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "Seconds after which the token expires, 0 never expires",
+                    "type": "integer"
+                },
+                "single_use": {
+                    "description": "Whether the token can be used to enroll only one agent",
+                    "type": "boolean"
+                }
+            }
+        },
         "web.JSONHosts": {
             "type": "object",
             "properties": {
@@ -996,6 +1838,17 @@ var doc = `{
                 }
             }
         },
+        "web.JSONRunDiscoveryRequest": {
+            "type": "object",
+            "required": [
+                "discovery_type"
+            ],
+            "properties": {
+                "discovery_type": {
+                    "type": "string"
+                }
+            }
+        },
         "web.JSONTag": {
             "type": "object",
             "required": [
@@ -1024,6 +1877,13 @@ var doc = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,157 @@
+/*
+Based on https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/configuring-instance-metadata-service.html
+*/
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	awsTokenTTL           = "21600"
+	awsTokenHeader        = "X-aws-ec2-metadata-token"
+	awsTokenTTLHeader     = "X-aws-ec2-metadata-token-ttl-seconds"
+	awsEbsDevicePrefix    = "ebs"
+	awsRootDevice         = "root"
+	awsBlockDeviceMapping = "/latest/meta-data/block-device-mapping/"
+)
+
+// Extracted for UT purposes, the tests point it to a local HTTP server
+var awsMetadataUrl = "http://169.254.169.254"
+
+type AwsMetadata struct {
+	AccountId        string            `json:"accountId,omitempty" mapstructure:"accountid,omitempty"`
+	Architecture     string            `json:"architecture,omitempty" mapstructure:"architecture,omitempty"`
+	AvailabilityZone string            `json:"availabilityZone,omitempty" mapstructure:"availabilityzone,omitempty"`
+	ImageId          string            `json:"imageId,omitempty" mapstructure:"imageid,omitempty"`
+	InstanceId       string            `json:"instanceId,omitempty" mapstructure:"instanceid,omitempty"`
+	InstanceType     string            `json:"instanceType,omitempty" mapstructure:"instancetype,omitempty"`
+	PrivateIp        string            `json:"privateIp,omitempty" mapstructure:"privateip,omitempty"`
+	Region           string            `json:"region,omitempty" mapstructure:"region,omitempty"`
+	EbsVolumes       []*AwsBlockDevice `json:"ebsVolumes,omitempty" mapstructure:"ebsvolumes,omitempty"`
+}
+
+type AwsBlockDevice struct {
+	Name   string `json:"name,omitempty" mapstructure:"name,omitempty"`
+	Device string `json:"device,omitempty" mapstructure:"device,omitempty"`
+}
+
+// NewAwsMetadata retrieves the instance metadata using the IMDSv2 protocol:
+// a session token is requested first, and sent along with every metadata request
+func NewAwsMetadata(ctx context.Context) (*AwsMetadata, error) {
+	log.Debug("Requesting Aws metadata...")
+
+	token, err := getAwsToken(ctx)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	document, err := getAwsMetadata(ctx, token, "/latest/dynamic/instance-identity/document")
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	log.Debugln(string(document))
+
+	m := &AwsMetadata{}
+	err = json.Unmarshal(document, m)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	m.EbsVolumes, err = getAwsEbsVolumes(ctx, token)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func getAwsToken(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, awsMetadataUrl+"/latest/api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add(awsTokenTTLHeader, awsTokenTTL)
+
+	token, err := doAwsRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	return string(token), nil
+}
+
+func getAwsMetadata(ctx context.Context, token string, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, awsMetadataUrl+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(awsTokenHeader, token)
+
+	return doAwsRequest(req)
+}
+
+// getAwsEbsVolumes returns the EBS volumes attached to the instance, as listed in the block device mapping,
+// starting from the root volume. The ami mapping is the same root volume as seen by the image, so it's not
+// listed twice, while the ephemeral and swap mappings are instance store volumes rather than EBS ones
+func getAwsEbsVolumes(ctx context.Context, token string) ([]*AwsBlockDevice, error) {
+	mapping, err := getAwsMetadata(ctx, token, awsBlockDeviceMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range strings.Fields(string(mapping)) {
+		if name == awsRootDevice {
+			names = append([]string{name}, names...)
+		} else if strings.HasPrefix(name, awsEbsDevicePrefix) {
+			names = append(names, name)
+		}
+	}
+
+	var volumes []*AwsBlockDevice
+	for _, name := range names {
+		device, err := getAwsMetadata(ctx, token, awsBlockDeviceMapping+name)
+		if err != nil {
+			return nil, err
+		}
+
+		volumes = append(volumes, &AwsBlockDevice{
+			Name:   name,
+			Device: strings.TrimSpace(string(device)),
+		})
+	}
+
+	return volumes, nil
+}
+
+func doAwsRequest(req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d requesting %s", resp.StatusCode, req.URL.Path)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,116 @@
+package cloud
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const awsTestToken = "AQAEAHEpHr8-test-token"
+
+const awsIdentityDocument = `{
+  "accountId" : "123456789012",
+  "architecture" : "x86_64",
+  "availabilityZone" : "eu-central-1a",
+  "billingProducts" : null,
+  "devpayProductCodes" : null,
+  "marketplaceProductCodes" : null,
+  "imageId" : "ami-0a1b2c3d4e5f67890",
+  "instanceId" : "i-0123456789abcdef0",
+  "instanceType" : "r5b.4xlarge",
+  "kernelId" : null,
+  "pendingTime" : "2022-04-01T10:00:00Z",
+  "privateIp" : "10.0.1.10",
+  "ramdiskId" : null,
+  "region" : "eu-central-1",
+  "version" : "2017-09-30"
+}`
+
+// newAwsMetadataServer returns a local stand-in for the IMDSv2 endpoint
+func newAwsMetadataServer(t *testing.T) *httptest.Server {
+	metadata := map[string]string{
+		"/latest/dynamic/instance-identity/document":  awsIdentityDocument,
+		"/latest/meta-data/block-device-mapping/":     "ami\nebs1\nebs2\nroot",
+		"/latest/meta-data/block-device-mapping/ebs1": "sdb",
+		"/latest/meta-data/block-device-mapping/ebs2": "sdc",
+		"/latest/meta-data/block-device-mapping/ami":  "/dev/sda1",
+		"/latest/meta-data/block-device-mapping/root": "/dev/sda1",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			assert.Equal(t, http.MethodPut, r.Method)
+			assert.Equal(t, "21600", r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+			w.Write([]byte(awsTestToken))
+			return
+		}
+
+		if r.Header.Get("X-aws-ec2-metadata-token") != awsTestToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		value, found := metadata[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(value))
+	}))
+}
+
+func TestNewAwsMetadata(t *testing.T) {
+	server := newAwsMetadataServer(t)
+	defer server.Close()
+
+	awsMetadataUrl = server.URL
+	client = server.Client()
+
+	m, err := NewAwsMetadata(context.Background())
+
+	expectedMeta := &AwsMetadata{
+		AccountId:        "123456789012",
+		Architecture:     "x86_64",
+		AvailabilityZone: "eu-central-1a",
+		ImageId:          "ami-0a1b2c3d4e5f67890",
+		InstanceId:       "i-0123456789abcdef0",
+		InstanceType:     "r5b.4xlarge",
+		PrivateIp:        "10.0.1.10",
+		Region:           "eu-central-1",
+		EbsVolumes: []*AwsBlockDevice{
+			{
+				Name:   "root",
+				Device: "/dev/sda1",
+			},
+			{
+				Name:   "ebs1",
+				Device: "sdb",
+			},
+			{
+				Name:   "ebs2",
+				Device: "sdc",
+			},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedMeta, m)
+}
+
+func TestNewAwsMetadataTokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	awsMetadataUrl = server.URL
+	client = server.Client()
+
+	m, err := NewAwsMetadata(context.Background())
+
+	assert.Nil(t, m)
+	assert.EqualError(t, err, "unexpected status code 403 requesting /latest/api/token")
+}
@@ -6,24 +6,28 @@ package cloud
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"path"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 const (
-	azureApiVersion = "2021-02-01"
-	azureApiAddress = "169.254.169.254"
-	azurePortalUrl  = "https://portal.azure.com/#@SUSERDBillingsuse.onmicrosoft.com/resource"
+	azureApiVersion                = "2021-02-01"
+	azureScheduledEventsApiVersion = "2020-07-01"
+	azureApiAddress                = "169.254.169.254"
+	azurePortalUrl                 = "https://portal.azure.com/#@SUSERDBillingsuse.onmicrosoft.com/resource"
 )
 
 type AzureMetadata struct {
-	Compute Compute `json:"compute,omitempty" mapstructure:"compute,omitempty"`
-	Network Network `json:"network,omitempty" mapstructure:"network,omitempty"`
+	Compute         Compute           `json:"compute,omitempty" mapstructure:"compute,omitempty"`
+	Network         Network           `json:"network,omitempty" mapstructure:"network,omitempty"`
+	ScheduledEvents []*ScheduledEvent `json:"scheduledEvents,omitempty" mapstructure:"scheduledevents,omitempty"`
 }
 
 type Compute struct {
@@ -136,6 +140,24 @@ type Address struct {
 	PublicIp  string `json:"publicIpAddress,omitempty" mapstructure:"publicip,omitempty"`
 }
 
+// Based on https://docs.microsoft.com/en-us/azure/virtual-machines/linux/scheduled-events
+type ScheduledEvents struct {
+	DocumentIncarnation int               `json:"DocumentIncarnation"`
+	Events              []*ScheduledEvent `json:"Events"`
+}
+
+type ScheduledEvent struct {
+	EventId           string   `json:"EventId,omitempty" mapstructure:"eventid,omitempty"`
+	EventType         string   `json:"EventType,omitempty" mapstructure:"eventtype,omitempty"`
+	ResourceType      string   `json:"ResourceType,omitempty" mapstructure:"resourcetype,omitempty"`
+	Resources         []string `json:"Resources,omitempty" mapstructure:"resources,omitempty"`
+	EventStatus       string   `json:"EventStatus,omitempty" mapstructure:"eventstatus,omitempty"`
+	NotBefore         string   `json:"NotBefore,omitempty" mapstructure:"notbefore,omitempty"`
+	Description       string   `json:"Description,omitempty" mapstructure:"description,omitempty"`
+	EventSource       string   `json:"EventSource,omitempty" mapstructure:"eventsource,omitempty"`
+	DurationInSeconds int      `json:"DurationInSeconds,omitempty" mapstructure:"durationinseconds,omitempty"`
+}
+
 type Subnet struct {
 	Address string `json:"address,omitempty" mapstructure:"address,omitempty"`
 	Prefix  string `json:"prefix,omitempty" mapstructure:"prefix,omitempty"`
@@ -149,11 +171,15 @@ type HTTPClient interface {
 
 var client HTTPClient = &http.Client{Transport: &http.Transport{Proxy: nil}}
 
-func NewAzureMetadata() (*AzureMetadata, error) {
+// The first scheduled events request enables the service, which can take up to a couple of minutes.
+// It's bounded by a shorter timeout so the cloud discovery doesn't time out, the events are retrieved on the next runs
+var scheduledEventsTimeout = 10 * time.Second
+
+func NewAzureMetadata(ctx context.Context) (*AzureMetadata, error) {
 	var err error
 	m := &AzureMetadata{}
 
-	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/metadata/instance", azureApiAddress), nil)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/metadata/instance", azureApiAddress), nil)
 	req.Header.Add("Metadata", "True")
 
 	q := req.URL.Query()
@@ -190,9 +216,57 @@ func NewAzureMetadata() (*AzureMetadata, error) {
 		return nil, err
 	}
 
+	// The scheduled events are not essential, the metadata is published anyway if they are not available
+	scheduledEventsCtx, cancel := context.WithTimeout(ctx, scheduledEventsTimeout)
+	defer cancel()
+
+	scheduledEvents, err := NewAzureScheduledEvents(scheduledEventsCtx)
+	if err != nil {
+		log.Warnf("Could not retrieve the Azure scheduled events: %s", err)
+	} else {
+		m.ScheduledEvents = scheduledEvents.Events
+	}
+
 	return m, nil
 }
 
+// NewAzureScheduledEvents returns the upcoming maintenance events of the VM, like reboots, redeploys and freezes
+func NewAzureScheduledEvents(ctx context.Context) (*ScheduledEvents, error) {
+	e := &ScheduledEvents{}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/metadata/scheduledevents", azureApiAddress), nil)
+	req.Header.Add("Metadata", "True")
+
+	q := req.URL.Query()
+	q.Add("api-version", azureScheduledEventsApiVersion)
+	req.URL.RawQuery = q.Encode()
+
+	log.Debug("Requesting Azure scheduled events...")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d requesting the scheduled events", resp.StatusCode)
+	}
+	log.Debugln(string(body))
+
+	err = json.Unmarshal(body, e)
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
 func (m *AzureMetadata) GetVmUrl() string {
 	return path.Join(azurePortalUrl, m.Compute.ResourceId)
 }
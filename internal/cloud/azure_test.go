@@ -2,10 +2,12 @@ package cloud
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -24,13 +26,26 @@ func TestNewAzureMetadata(t *testing.T) {
 		Body:       body,
 	}
 
-	clientMock.On("Do", mock.AnythingOfType("*http.Request")).Return(
+	eventsFile, _ := os.Open("../../test/azure_scheduled_events")
+	eventsText, _ := ioutil.ReadAll(eventsFile)
+	eventsBody := ioutil.NopCloser(bytes.NewReader([]byte(eventsText)))
+
+	eventsResponse := &http.Response{
+		StatusCode: 200,
+		Body:       eventsBody,
+	}
+
+	clientMock.On("Do", requestToPath("/metadata/instance")).Return(
 		response, nil,
 	)
 
+	clientMock.On("Do", requestToPath("/metadata/scheduledevents")).Return(
+		eventsResponse, nil,
+	)
+
 	client = clientMock
 
-	m, err := NewAzureMetadata()
+	m, err := NewAzureMetadata(context.Background())
 
 	expectedMeta := &AzureMetadata{
 		Compute: Compute{
@@ -184,12 +199,95 @@ func TestNewAzureMetadata(t *testing.T) {
 				},
 			},
 		},
+		ScheduledEvents: []*ScheduledEvent{
+			{
+				EventId:           "C7061BAC-AFDC-4513-B24B-AA5F13A16123",
+				EventType:         "Freeze",
+				ResourceType:      "VirtualMachine",
+				Resources:         []string{"vmhana01"},
+				EventStatus:       "Scheduled",
+				NotBefore:         "Mon, 11 Apr 2022 10:00:00 GMT",
+				Description:       "Virtual machine is being paused because of a memory-preserving Live Migration operation.",
+				EventSource:       "Platform",
+				DurationInSeconds: 5,
+			},
+		},
 	}
 
 	assert.Equal(t, expectedMeta, m)
 	assert.NoError(t, err)
 }
 
+func requestToPath(path string) interface{} {
+	return mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.Path == path
+	})
+}
+
+func TestNewAzureMetadataWithoutScheduledEvents(t *testing.T) {
+	clientMock := new(mocks.HTTPClient)
+
+	clientMock.On("Do", requestToPath("/metadata/instance")).Return(
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"compute": {"name": "vmhana01"}}`))),
+		}, nil,
+	)
+
+	clientMock.On("Do", requestToPath("/metadata/scheduledevents")).Return(
+		&http.Response{
+			StatusCode: 500,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+		}, nil,
+	)
+
+	client = clientMock
+
+	m, err := NewAzureMetadata(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "vmhana01", m.Compute.Name)
+	assert.Nil(t, m.ScheduledEvents)
+}
+
+func TestNewAzureMetadataScheduledEventsTimeout(t *testing.T) {
+	clientMock := new(mocks.HTTPClient)
+
+	clientMock.On("Do", requestToPath("/metadata/instance")).Return(
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"compute": {"name": "vmhana01"}}`))),
+		}, nil,
+	)
+
+	// The scheduled events service is being enabled, the request blocks until it's canceled
+	clientMock.On("Do", requestToPath("/metadata/scheduledevents")).Return(
+		func(req *http.Request) *http.Response {
+			<-req.Context().Done()
+			return nil
+		},
+		func(req *http.Request) error {
+			return req.Context().Err()
+		},
+	)
+
+	client = clientMock
+	scheduledEventsTimeout = 10 * time.Millisecond
+	defer func() {
+		scheduledEventsTimeout = 10 * time.Second
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	m, err := NewAzureMetadata(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "vmhana01", m.Compute.Name)
+	assert.Nil(t, m.ScheduledEvents)
+	assert.NoError(t, ctx.Err())
+}
+
 func TestGetVmUrl(t *testing.T) {
 	meta := &AzureMetadata{
 		Compute: Compute{
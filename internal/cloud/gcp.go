@@ -0,0 +1,99 @@
+/*
+Based on https://cloud.google.com/compute/docs/metadata/overview
+*/
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Extracted for UT purposes, the tests point it to a local HTTP server
+var gcpMetadataUrl = "http://metadata.google.internal"
+
+type GcpMetadata struct {
+	Instance GcpInstance `json:"instance,omitempty" mapstructure:"instance,omitempty"`
+	Project  GcpProject  `json:"project,omitempty" mapstructure:"project,omitempty"`
+}
+
+type GcpInstance struct {
+	CpuPlatform       string                 `json:"cpuPlatform,omitempty" mapstructure:"cpuplatform,omitempty"`
+	Disks             []*GcpDisk             `json:"disks,omitempty" mapstructure:"disks,omitempty"`
+	Hostname          string                 `json:"hostname,omitempty" mapstructure:"hostname,omitempty"`
+	Image             string                 `json:"image,omitempty" mapstructure:"image,omitempty"`
+	MachineType       string                 `json:"machineType,omitempty" mapstructure:"machinetype,omitempty"`
+	Name              string                 `json:"name,omitempty" mapstructure:"name,omitempty"`
+	NetworkInterfaces []*GcpNetworkInterface `json:"networkInterfaces,omitempty" mapstructure:"networkinterfaces,omitempty"`
+	Zone              string                 `json:"zone,omitempty" mapstructure:"zone,omitempty"`
+}
+
+type GcpDisk struct {
+	DeviceName string `json:"deviceName,omitempty" mapstructure:"devicename,omitempty"`
+	Index      int    `json:"index,omitempty" mapstructure:"index,omitempty"`
+	Interface  string `json:"interface,omitempty" mapstructure:"interface,omitempty"`
+	Mode       string `json:"mode,omitempty" mapstructure:"mode,omitempty"`
+	Type       string `json:"type,omitempty" mapstructure:"type,omitempty"`
+}
+
+type GcpNetworkInterface struct {
+	Gateway    string `json:"gateway,omitempty" mapstructure:"gateway,omitempty"`
+	Ip         string `json:"ip,omitempty" mapstructure:"ip,omitempty"`
+	Mac        string `json:"mac,omitempty" mapstructure:"mac,omitempty"`
+	Network    string `json:"network,omitempty" mapstructure:"network,omitempty"`
+	Subnetmask string `json:"subnetmask,omitempty" mapstructure:"subnetmask,omitempty"`
+}
+
+type GcpProject struct {
+	NumericProjectId int64  `json:"numericProjectId,omitempty" mapstructure:"numericprojectid,omitempty"`
+	ProjectId        string `json:"projectId,omitempty" mapstructure:"projectid,omitempty"`
+}
+
+func NewGcpMetadata(ctx context.Context) (*GcpMetadata, error) {
+	m := &GcpMetadata{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/computeMetadata/v1/", gcpMetadataUrl), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Metadata-Flavor", "Google")
+
+	q := req.URL.Query()
+	q.Add("recursive", "true")
+	req.URL.RawQuery = q.Encode()
+
+	log.Debug("Requesting Gcp metadata...")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status code %d requesting the gcp metadata", resp.StatusCode)
+		log.Error(err)
+		return nil, err
+	}
+	log.Debugln(string(body))
+
+	err = json.Unmarshal(body, m)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return m, nil
+}
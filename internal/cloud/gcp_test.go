@@ -0,0 +1,97 @@
+package cloud
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGcpMetadata(t *testing.T) {
+	fixture, _ := ioutil.ReadFile("../../test/fixtures/discovery/gcp/gcp_metadata.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/computeMetadata/v1/", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("recursive"))
+
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	gcpMetadataUrl = server.URL
+	client = server.Client()
+
+	m, err := NewGcpMetadata(context.Background())
+
+	expectedMeta := &GcpMetadata{
+		Instance: GcpInstance{
+			CpuPlatform: "Intel Broadwell",
+			Disks: []*GcpDisk{
+				{
+					DeviceName: "persistent-disk-0",
+					Index:      0,
+					Interface:  "SCSI",
+					Mode:       "READ_WRITE",
+					Type:       "PERSISTENT",
+				},
+				{
+					DeviceName: "vmhana01-data",
+					Index:      1,
+					Interface:  "SCSI",
+					Mode:       "READ_WRITE",
+					Type:       "PERSISTENT",
+				},
+				{
+					DeviceName: "vmhana01-backup",
+					Index:      2,
+					Interface:  "SCSI",
+					Mode:       "READ_WRITE",
+					Type:       "PERSISTENT",
+				},
+			},
+			Hostname:    "vmhana01.europe-west1-b.c.sap-project.internal",
+			Image:       "projects/suse-sap-cloud/global/images/sles-15-sp1-sap-v20220126",
+			MachineType: "projects/123456/machineTypes/n1-highmem-32",
+			Name:        "vmhana01",
+			NetworkInterfaces: []*GcpNetworkInterface{
+				{
+					Gateway:    "10.0.0.1",
+					Ip:         "10.0.0.10",
+					Mac:        "42:01:0a:00:00:0a",
+					Network:    "projects/123456/networks/network",
+					Subnetmask: "255.255.255.0",
+				},
+			},
+			Zone: "projects/123456/zones/europe-west1-b",
+		},
+		Project: GcpProject{
+			NumericProjectId: 123456,
+			ProjectId:        "sap-project",
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedMeta, m)
+}
+
+func TestNewGcpMetadataError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	gcpMetadataUrl = server.URL
+	client = server.Client()
+
+	m, err := NewGcpMetadata(context.Background())
+
+	assert.Nil(t, m)
+	assert.EqualError(t, err, "unexpected status code 404 requesting the gcp metadata")
+}
@@ -2,6 +2,7 @@ package cloud
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"os/exec"
@@ -21,11 +22,11 @@ func TestIdentifyCloudProviderErr(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockCommand.On("Execute", "dmidecode", "-s", "chassis-asset-tag").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "chassis-asset-tag").Return(
 		mockDmidecodeErr(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "", provider)
 	assert.EqualError(t, err, "exec: \"error\": executable file not found in $PATH")
@@ -40,11 +41,11 @@ func TestIdentifyCloudProviderAzure(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockCommand.On("Execute", "dmidecode", "-s", "chassis-asset-tag").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "chassis-asset-tag").Return(
 		mockDmidecodeAzure(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "azure", provider)
 	assert.NoError(t, err)
@@ -59,15 +60,15 @@ func TestIdentifyCloudProviderAws(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockCommand.On("Execute", "dmidecode", "-s", "chassis-asset-tag").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "chassis-asset-tag").Return(
 		mockDmidecodeNoCloud(),
 	)
 
-	mockCommand.On("Execute", "dmidecode", "-s", "system-version").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "system-version").Return(
 		mockDmidecodeAws(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "aws", provider)
 	assert.NoError(t, err)
@@ -82,19 +83,19 @@ func TestIdentifyCloudProviderGcp(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockCommand.On("Execute", "dmidecode", "-s", "chassis-asset-tag").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "chassis-asset-tag").Return(
 		mockDmidecodeNoCloud(),
 	)
 
-	mockCommand.On("Execute", "dmidecode", "-s", "system-version").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "system-version").Return(
 		mockDmidecodeNoCloud(),
 	)
 
-	mockCommand.On("Execute", "dmidecode", "-s", "bios-vendor").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "bios-vendor").Return(
 		mockDmidecodeGcp(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "gcp", provider)
 	assert.NoError(t, err)
@@ -109,19 +110,19 @@ func TestIdentifyCloudProviderNoCloud(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockCommand.On("Execute", "dmidecode", "-s", "chassis-asset-tag").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "chassis-asset-tag").Return(
 		mockDmidecodeNoCloud(),
 	)
 
-	mockCommand.On("Execute", "dmidecode", "-s", "system-version").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "system-version").Return(
 		mockDmidecodeNoCloud(),
 	)
 
-	mockCommand.On("Execute", "dmidecode", "-s", "bios-vendor").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "bios-vendor").Return(
 		mockDmidecodeNoCloud(),
 	)
 
-	provider, err := IdentifyCloudProvider()
+	provider, err := IdentifyCloudProvider(context.Background())
 
 	assert.Equal(t, "", provider)
 	assert.NoError(t, err)
@@ -132,7 +133,7 @@ func TestNewCloudInstanceAzure(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockCommand.On("Execute", "dmidecode", "-s", "chassis-asset-tag").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "chassis-asset-tag").Return(
 		mockDmidecodeAzure(),
 	)
 
@@ -151,7 +152,7 @@ func TestNewCloudInstanceAzure(t *testing.T) {
 
 	client = clientMock
 
-	c, err := NewCloudInstance()
+	c, err := NewCloudInstance(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, "azure", c.Provider)
@@ -164,19 +165,19 @@ func TestNewCloudInstanceNoCloud(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockCommand.On("Execute", "dmidecode", "-s", "chassis-asset-tag").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "chassis-asset-tag").Return(
 		mockDmidecodeNoCloud(),
 	)
 
-	mockCommand.On("Execute", "dmidecode", "-s", "system-version").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "system-version").Return(
 		mockDmidecodeNoCloud(),
 	)
 
-	mockCommand.On("Execute", "dmidecode", "-s", "bios-vendor").Return(
+	mockCommand.On("Execute", mock.Anything, "dmidecode", "-s", "bios-vendor").Return(
 		mockDmidecodeNoCloud(),
 	)
 
-	c, err := NewCloudInstance()
+	c, err := NewCloudInstance(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, "", c.Provider)
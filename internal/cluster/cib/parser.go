@@ -1,6 +1,7 @@
 package cib
 
 import (
+	"context"
 	"encoding/xml"
 	"os/exec"
 
@@ -8,16 +9,16 @@ import (
 )
 
 type Parser interface {
-	Parse() (Root, error)
+	Parse(ctx context.Context) (Root, error)
 }
 
 type cibAdminParser struct {
 	cibAdminPath string
 }
 
-func (p *cibAdminParser) Parse() (Root, error) {
+func (p *cibAdminParser) Parse(ctx context.Context) (Root, error) {
 	var CIB Root
-	cibXML, err := exec.Command(p.cibAdminPath, "--query", "--local").Output()
+	cibXML, err := exec.CommandContext(ctx, p.cibAdminPath, "--query", "--local").Output()
 	if err != nil {
 		return CIB, errors.Wrap(err, "error while executing cibadmin")
 	}
@@ -1,6 +1,7 @@
 package cib
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,9 +12,18 @@ func TestConstructor(t *testing.T) {
 	assert.Equal(t, "foo", p.cibAdminPath)
 }
 
+func TestParseCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := NewCibAdminParser("../../../test/fake_cibadmin.sh")
+	_, err := p.Parse(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestParse(t *testing.T) {
 	p := NewCibAdminParser("../../../test/fake_cibadmin.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(data.Configuration.Nodes))
 	assert.Equal(t, "cib-bootstrap-options-cluster-name", data.Configuration.CrmConfig.ClusterProperties[3].Id)
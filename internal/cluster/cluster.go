@@ -1,10 +1,12 @@
 package cluster
 
 import (
+	"context"
 	"os"
 	"strconv"
 	"strings"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/internal"
 
 	// These packages were originally imported from github.com/ClusterLabs/ha_cluster_exporter/collector/pacemaker
@@ -23,50 +25,62 @@ const (
 	stonithAgent           string = "stonith:"
 	sbdFencingAgentName    string = "external/sbd"
 	clusterNameWordCount   int    = 1
+	// RedactedValue replaces the value of the attributes holding credentials
+	RedactedValue string = "********"
 )
 
+// secretAttributeNames are the parts of the attribute names holding credentials,
+// e.g. password and passwd_script (fence_azure_arm) or access_key and secret_key (fence_aws)
+var secretAttributeNames = []string{"passw", "secret", "key", "token", "credential"}
+
 type DiscoveryTools struct {
-	CibAdmPath      string
-	CrmmonAdmPath   string
-	CorosyncKeyPath string
-	SBDPath         string
-	SBDConfigPath   string
+	CibAdmPath          string
+	CrmmonAdmPath       string
+	CorosyncKeyPath     string
+	CorosyncConfPath    string
+	CorosyncCfgtoolPath string
+	SBDPath             string
+	SBDConfigPath       string
 }
 
 type Cluster struct {
-	Cib    cib.Root    `mapstructure:"cib,omitempty"`
-	Crmmon crmmon.Root `mapstructure:"crmmon,omitempty"`
-	SBD    SBD         `mapstructure:"sbd,omitempty"`
-	Id     string      `mapstructure:"id"`
-	Name   string      `mapstructure:"name"`
-	DC     bool        `mapstructure:"dc"`
+	Cib      cib.Root    `mapstructure:"cib,omitempty"`
+	Crmmon   crmmon.Root `mapstructure:"crmmon,omitempty"`
+	SBD      SBD         `mapstructure:"sbd,omitempty"`
+	Corosync Corosync    `mapstructure:"corosync,omitempty"`
+	Id       string      `mapstructure:"id"`
+	Name     string      `mapstructure:"name"`
+	DC       bool        `mapstructure:"dc"`
 }
 
-func NewCluster() (Cluster, error) {
-	return NewClusterWithDiscoveryTools(&DiscoveryTools{
-		CibAdmPath:      cibAdmPath,
-		CrmmonAdmPath:   crmmonAdmPath,
-		CorosyncKeyPath: corosyncKeyPath,
-		SBDPath:         SBDPath,
-		SBDConfigPath:   SBDConfigPath,
+func NewCluster(ctx context.Context) (Cluster, error) {
+	return NewClusterWithDiscoveryTools(ctx, &DiscoveryTools{
+		CibAdmPath:          cibAdmPath,
+		CrmmonAdmPath:       crmmonAdmPath,
+		CorosyncKeyPath:     corosyncKeyPath,
+		CorosyncConfPath:    CorosyncConfPath,
+		CorosyncCfgtoolPath: CorosyncCfgtoolPath,
+		SBDPath:             SBDPath,
+		SBDConfigPath:       SBDConfigPath,
 	})
 }
 
-func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, error) {
+func NewClusterWithDiscoveryTools(ctx context.Context, discoveryTools *DiscoveryTools) (Cluster, error) {
 	var cluster = Cluster{}
 
 	cibParser := cib.NewCibAdminParser(discoveryTools.CibAdmPath)
 
-	cibConfig, err := cibParser.Parse()
+	cibConfig, err := cibParser.Parse(ctx)
 	if err != nil {
 		return cluster, err
 	}
 
 	cluster.Cib = cibConfig
+	redactFencingSecrets(&cluster)
 
 	crmmonParser := crmmon.NewCrmMonParser(discoveryTools.CrmmonAdmPath)
 
-	crmmonConfig, err := crmmonParser.Parse()
+	crmmonConfig, err := crmmonParser.Parse(ctx)
 	if err != nil {
 		return cluster, err
 	}
@@ -81,8 +95,14 @@ func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, erro
 
 	cluster.Name = getName(cluster)
 
+	// The corosync data is not essential, the cluster is published anyway if it can't be read
+	cluster.Corosync, err = NewCorosync(ctx, discoveryTools.CorosyncConfPath, discoveryTools.CorosyncCfgtoolPath)
+	if err != nil {
+		log.Warnf("Error getting corosync information: %s", err)
+	}
+
 	if cluster.IsFencingSBD() {
-		sbdData, err := NewSBD(cluster.Id, discoveryTools.SBDPath, discoveryTools.SBDConfigPath)
+		sbdData, err := NewSBD(ctx, cluster.Id, discoveryTools.SBDPath, discoveryTools.SBDConfigPath)
 		if err != nil {
 			return cluster, err
 		}
@@ -152,6 +172,51 @@ func (c *Cluster) FencingType() string {
 	return stonithResourceMissing
 }
 
+// IsSecretAttribute tells whether a resource attribute holds a credential, so its value must not be disclosed
+func IsSecretAttribute(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range secretAttributeNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactFencingSecrets replaces the credentials of the stonith resources, so they are never published.
+// The stonith resources can be plain primitives, or be part of groups, clones and masters
+func redactFencingSecrets(c *Cluster) {
+	resources := &c.Cib.Configuration.Resources
+
+	redact := func(primitive *cib.Primitive) {
+		if primitive.Class != "stonith" {
+			return
+		}
+
+		for i, a := range primitive.InstanceAttributes {
+			if IsSecretAttribute(a.Name) {
+				primitive.InstanceAttributes[i].Value = RedactedValue
+			}
+		}
+	}
+
+	for i := range resources.Primitives {
+		redact(&resources.Primitives[i])
+	}
+	for i := range resources.Groups {
+		for j := range resources.Groups[i].Primitives {
+			redact(&resources.Groups[i].Primitives[j])
+		}
+	}
+	for i := range resources.Clones {
+		redact(&resources.Clones[i].Primitive)
+	}
+	for i := range resources.Masters {
+		redact(&resources.Masters[i].Primitive)
+	}
+}
+
 func (c *Cluster) IsFencingSBD() bool {
 	f := c.FencingType()
 
@@ -1,6 +1,8 @@
 package cluster
 
 import (
+	"encoding/xml"
+	"io/ioutil"
 	"os"
 	"testing"
 
@@ -242,3 +244,78 @@ func TestIsFencingSBD(t *testing.T) {
 
 	assert.Equal(t, false, c.IsFencingSBD())
 }
+
+func TestIsSecretAttribute(t *testing.T) {
+	assert.True(t, IsSecretAttribute("password"))
+	assert.True(t, IsSecretAttribute("passwd_script"))
+	assert.True(t, IsSecretAttribute("secret_key"))
+	assert.True(t, IsSecretAttribute("Access_Key"))
+	assert.False(t, IsSecretAttribute("login"))
+	assert.False(t, IsSecretAttribute("pcmk_delay_max"))
+	assert.False(t, IsSecretAttribute("subscriptionId"))
+}
+
+func TestRedactFencingSecrets(t *testing.T) {
+	c := Cluster{}
+	c.Cib.Configuration.Resources.Primitives = []cib.Primitive{
+		{
+			Id:    "rsc_st_azure",
+			Class: "stonith",
+			Type:  "fence_azure_arm",
+			InstanceAttributes: []cib.Attribute{
+				{Name: "login", Value: "app-id"},
+				{Name: "password", Value: "app-secret"},
+			},
+		},
+		{
+			Id:    "rsc_app",
+			Class: "ocf",
+			Type:  "app",
+			InstanceAttributes: []cib.Attribute{
+				{Name: "password", Value: "not-fencing"},
+			},
+		},
+	}
+	c.Cib.Configuration.Resources.Groups = []cib.Group{
+		{
+			Id: "grp_fencing",
+			Primitives: []cib.Primitive{
+				{
+					Id:    "rsc_st_aws",
+					Class: "stonith",
+					Type:  "fence_aws",
+					InstanceAttributes: []cib.Attribute{
+						{Name: "secret_key", Value: "aws-secret"},
+					},
+				},
+			},
+		},
+	}
+
+	redactFencingSecrets(&c)
+
+	primitives := c.Cib.Configuration.Resources.Primitives
+	assert.Equal(t, "app-id", primitives[0].InstanceAttributes[0].Value)
+	assert.Equal(t, RedactedValue, primitives[0].InstanceAttributes[1].Value)
+	assert.Equal(t, "not-fencing", primitives[1].InstanceAttributes[0].Value)
+	assert.Equal(t, RedactedValue, c.Cib.Configuration.Resources.Groups[0].Primitives[0].InstanceAttributes[0].Value)
+}
+
+func TestRedactFencingSecretsClones(t *testing.T) {
+	cibXML, err := ioutil.ReadFile("../../test/fixtures/discovery/cluster/cib_cloned_stonith.xml")
+	assert.NoError(t, err)
+
+	c := Cluster{}
+	assert.NoError(t, xml.Unmarshal(cibXML, &c.Cib))
+
+	redactFencingSecrets(&c)
+
+	resources := c.Cib.Configuration.Resources
+	assert.Equal(t, []cib.Attribute{
+		{Id: "rsc_stonith_vmware-instance_attributes-ip", Name: "ip", Value: "vcenter.example.com"},
+		{Id: "rsc_stonith_vmware-instance_attributes-username", Name: "username", Value: "fencing@vsphere.local"},
+		{Id: "rsc_stonith_vmware-instance_attributes-password", Name: "password", Value: RedactedValue},
+	}, resources.Clones[0].Primitive.InstanceAttributes)
+	assert.Equal(t, RedactedValue, resources.Masters[0].Primitive.InstanceAttributes[1].Value)
+	assert.Equal(t, "not-fencing", resources.Clones[1].Primitive.InstanceAttributes[0].Value)
+}
@@ -0,0 +1,311 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	CorosyncConfPath    = "/etc/corosync/corosync.conf"
+	CorosyncCfgtoolPath = "/usr/sbin/corosync-cfgtool"
+)
+
+type Corosync struct {
+	Config CorosyncConfig  `mapstructure:"config,omitempty"`
+	Links  []*CorosyncLink `mapstructure:"links,omitempty"`
+}
+
+// CorosyncConfig holds the relevant settings of corosync.conf
+type CorosyncConfig struct {
+	Totem  CorosyncTotem   `mapstructure:"totem,omitempty"`
+	Nodes  []*CorosyncNode `mapstructure:"nodes,omitempty"`
+	Quorum CorosyncQuorum  `mapstructure:"quorum,omitempty"`
+}
+
+type CorosyncTotem struct {
+	Version     int                  `mapstructure:"version,omitempty"`
+	ClusterName string               `mapstructure:"clustername,omitempty"`
+	Token       int                  `mapstructure:"token,omitempty"`
+	Consensus   int                  `mapstructure:"consensus,omitempty"`
+	Transport   string               `mapstructure:"transport,omitempty"`
+	RRPMode     string               `mapstructure:"rrpmode,omitempty"`
+	Interfaces  []*CorosyncInterface `mapstructure:"interfaces,omitempty"`
+}
+
+// CorosyncInterface is a totem interface, configuring a ring in corosync 2 or a link in corosync 3
+type CorosyncInterface struct {
+	Number      int    `mapstructure:"number"`
+	BindNetAddr string `mapstructure:"bindnetaddr,omitempty"`
+	McastAddr   string `mapstructure:"mcastaddr,omitempty"`
+	McastPort   int    `mapstructure:"mcastport,omitempty"`
+}
+
+type CorosyncNode struct {
+	NodeID    int      `mapstructure:"nodeid,omitempty"`
+	Name      string   `mapstructure:"name,omitempty"`
+	Addresses []string `mapstructure:"addresses,omitempty"`
+}
+
+type CorosyncQuorum struct {
+	Provider      string `mapstructure:"provider,omitempty"`
+	ExpectedVotes int    `mapstructure:"expectedvotes,omitempty"`
+	TwoNode       bool   `mapstructure:"twonode,omitempty"`
+	WaitForAll    bool   `mapstructure:"waitforall,omitempty"`
+}
+
+// CorosyncLink is the live status of a ring (corosync 2) or a link (corosync 3) as seen by the local node
+type CorosyncLink struct {
+	ID      int                 `mapstructure:"id"`
+	Address string              `mapstructure:"address,omitempty"`
+	Status  string              `mapstructure:"status,omitempty"`
+	Nodes   []*CorosyncLinkNode `mapstructure:"nodes,omitempty"`
+	Faulty  bool                `mapstructure:"faulty,omitempty"`
+}
+
+type CorosyncLinkNode struct {
+	NodeID int    `mapstructure:"nodeid"`
+	Status string `mapstructure:"status,omitempty"`
+}
+
+// corosyncSection is a generic section of corosync.conf, e.g. totem { ... }
+type corosyncSection struct {
+	values   map[string]string
+	sections map[string][]*corosyncSection
+}
+
+var corosyncCfgtoolExecCommand = exec.CommandContext
+
+func NewCorosync(ctx context.Context, corosyncConfPath, corosyncCfgtoolPath string) (Corosync, error) {
+	var c = Corosync{}
+
+	config, err := getCorosyncConfig(corosyncConfPath)
+	if err != nil {
+		return c, err
+	}
+	c.Config = config
+
+	links, err := getCorosyncLinks(ctx, corosyncCfgtoolPath)
+	c.Links = links
+	if err != nil {
+		return c, err
+	}
+
+	return c, nil
+}
+
+func getCorosyncConfig(corosyncConfPath string) (CorosyncConfig, error) {
+	var config = CorosyncConfig{}
+
+	corosyncConfRaw, err := ioutil.ReadFile(corosyncConfPath)
+	if err != nil {
+		return config, fmt.Errorf("could not read corosync config file %s", err)
+	}
+
+	root, err := parseCorosyncConf(corosyncConfRaw)
+	if err != nil {
+		return config, errors.Wrap(err, "could not parse corosync config file")
+	}
+
+	if totem := root.section("totem"); totem != nil {
+		config.Totem = CorosyncTotem{
+			Version:     totem.intValue("version"),
+			ClusterName: totem.values["cluster_name"],
+			Token:       totem.intValue("token"),
+			Consensus:   totem.intValue("consensus"),
+			Transport:   totem.values["transport"],
+			RRPMode:     totem.values["rrp_mode"],
+		}
+
+		for _, i := range totem.sections["interface"] {
+			number := i.intValue("ringnumber")
+			if _, ok := i.values["linknumber"]; ok {
+				number = i.intValue("linknumber")
+			}
+
+			config.Totem.Interfaces = append(config.Totem.Interfaces, &CorosyncInterface{
+				Number:      number,
+				BindNetAddr: i.values["bindnetaddr"],
+				McastAddr:   i.values["mcastaddr"],
+				McastPort:   i.intValue("mcastport"),
+			})
+		}
+	}
+
+	if nodelist := root.section("nodelist"); nodelist != nil {
+		for _, n := range nodelist.sections["node"] {
+			node := &CorosyncNode{
+				NodeID: n.intValue("nodeid"),
+				Name:   n.values["name"],
+			}
+
+			// ring0_addr, ring1_addr... are the addresses of the node in every ring/link
+			for ring := 0; ; ring++ {
+				address, ok := n.values[fmt.Sprintf("ring%d_addr", ring)]
+				if !ok {
+					break
+				}
+				node.Addresses = append(node.Addresses, address)
+			}
+
+			config.Nodes = append(config.Nodes, node)
+		}
+	}
+
+	if quorum := root.section("quorum"); quorum != nil {
+		config.Quorum = CorosyncQuorum{
+			Provider:      quorum.values["provider"],
+			ExpectedVotes: quorum.intValue("expected_votes"),
+			TwoNode:       quorum.values["two_node"] == "1",
+			WaitForAll:    quorum.values["wait_for_all"] == "1",
+		}
+	}
+
+	return config, nil
+}
+
+// parseCorosyncConf parses the corosync.conf format, made of "key: value" pairs and nested "name { ... }" sections
+func parseCorosyncConf(content []byte) (*corosyncSection, error) {
+	root := newCorosyncSection()
+	stack := []*corosyncSection{root}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		current := stack[len(stack)-1]
+
+		switch {
+		case strings.HasSuffix(line, "{"):
+			name := strings.TrimSpace(strings.TrimSuffix(line, "{"))
+			section := newCorosyncSection()
+			current.sections[name] = append(current.sections[name], section)
+			stack = append(stack, section)
+		case line == "}":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("unexpected closing bracket")
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			pair := strings.SplitN(line, ":", 2)
+			if len(pair) != 2 {
+				continue
+			}
+			current.values[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("unclosed section")
+	}
+
+	return root, nil
+}
+
+func newCorosyncSection() *corosyncSection {
+	return &corosyncSection{
+		values:   make(map[string]string),
+		sections: make(map[string][]*corosyncSection),
+	}
+}
+
+func (s *corosyncSection) section(name string) *corosyncSection {
+	if sections := s.sections[name]; len(sections) > 0 {
+		return sections[0]
+	}
+	return nil
+}
+
+func (s *corosyncSection) intValue(key string) int {
+	value, _ := strconv.Atoi(s.values[key])
+	return value
+}
+
+// getCorosyncLinks returns the status of the rings or links, as reported by corosync-cfgtool.
+// Possible output, corosync 2:
+//
+//	Printing ring status.
+//	Local node ID 1
+//	RING ID 0
+//		id	= 10.162.32.167
+//		status	= ring 0 active with no faults
+//
+// Possible output, corosync 3:
+//
+//	Printing link status.
+//	Local node ID 1
+//	LINK ID 0
+//		addr	= 192.168.1.1
+//		status:
+//			nodeid  1:	localhost
+//			nodeid  2:	connected
+func getCorosyncLinks(ctx context.Context, corosyncCfgtoolPath string) ([]*CorosyncLink, error) {
+	output, err := corosyncCfgtoolExecCommand(ctx, corosyncCfgtoolPath, "-s").Output()
+
+	links := parseCorosyncCfgtool(output)
+
+	// Sanity check at the end, corosync-cfgtool exits with an error when a ring is faulty
+	if err != nil && len(links) == 0 {
+		return links, errors.Wrap(err, "corosync-cfgtool command error")
+	}
+
+	return links, nil
+}
+
+func parseCorosyncCfgtool(output []byte) []*CorosyncLink {
+	var links = []*CorosyncLink{}
+	var link *CorosyncLink
+
+	linkRegexp := regexp.MustCompile(`^(?:RING|LINK) ID (\d+)`)
+	propertyRegexp := regexp.MustCompile(`^(id|addr|status)\s*=\s*(.*)$`)
+	nodeRegexp := regexp.MustCompile(`^nodeid:?\s+(\d+):\s*(.*)$`)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if match := linkRegexp.FindStringSubmatch(line); match != nil {
+			id, _ := strconv.Atoi(match[1])
+			link = &CorosyncLink{ID: id}
+			links = append(links, link)
+			continue
+		}
+
+		if link == nil {
+			continue
+		}
+
+		if match := propertyRegexp.FindStringSubmatch(line); match != nil {
+			switch match[1] {
+			case "id", "addr":
+				link.Address = match[2]
+			case "status":
+				link.Status = match[2]
+				link.Faulty = !strings.Contains(match[2], "no faults")
+			}
+			continue
+		}
+
+		if match := nodeRegexp.FindStringSubmatch(line); match != nil {
+			nodeID, _ := strconv.Atoi(match[1])
+			status := strings.TrimSpace(match[2])
+			link.Nodes = append(link.Nodes, &CorosyncLinkNode{NodeID: nodeID, Status: status})
+			if status != "localhost" && status != "connected" {
+				link.Faulty = true
+			}
+		}
+	}
+
+	return links
+}
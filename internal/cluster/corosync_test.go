@@ -0,0 +1,160 @@
+package cluster
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockCorosyncCfgtoolRings(ctx context.Context, command string, args ...string) *exec.Cmd {
+	return exec.Command("../../test/fake_corosync_cfgtool.sh")
+}
+
+func mockCorosyncCfgtoolLinks(ctx context.Context, command string, args ...string) *exec.Cmd {
+	cmd := `Printing link status.
+Local node ID 1, transport knet
+LINK ID 0 udp
+	addr	= 192.168.1.1
+	status:
+		nodeid:          1:	localhost
+		nodeid:          2:	connected
+LINK ID 1 udp
+	addr	= 192.168.2.1
+	status:
+		nodeid:          1:	localhost
+		nodeid:          2:	disconnected`
+	return exec.Command("echo", cmd)
+}
+
+func mockCorosyncCfgtoolErr(ctx context.Context, command string, args ...string) *exec.Cmd {
+	return exec.Command("bash", "-c", "echo 'Could not initialize corosync configuration API error 2' && exit 1")
+}
+
+func TestGetCorosyncConfig(t *testing.T) {
+	config, err := getCorosyncConfig("../../test/corosync.conf")
+
+	expectedConfig := CorosyncConfig{
+		Totem: CorosyncTotem{
+			Version:     2,
+			ClusterName: "hacluster",
+			Token:       5000,
+			Consensus:   6000,
+			Transport:   "udpu",
+			RRPMode:     "passive",
+			Interfaces: []*CorosyncInterface{
+				{Number: 0, BindNetAddr: "10.80.1.0", McastPort: 5405},
+				{Number: 1, BindNetAddr: "10.80.2.0", McastPort: 5407},
+			},
+		},
+		Nodes: []*CorosyncNode{
+			{NodeID: 1, Addresses: []string{"10.80.1.11", "10.80.2.11"}},
+			{NodeID: 2, Addresses: []string{"10.80.1.12", "10.80.2.12"}},
+		},
+		Quorum: CorosyncQuorum{
+			Provider:      "corosync_votequorum",
+			ExpectedVotes: 2,
+			TwoNode:       true,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedConfig, config)
+}
+
+func TestGetCorosyncConfigKnet(t *testing.T) {
+	root, err := parseCorosyncConf([]byte(`totem {
+	version: 2
+	cluster_name: hacluster
+	transport: knet
+	interface {
+		linknumber: 1
+		knet_transport: sctp
+	}
+}
+nodelist {
+	node {
+		name: node1
+		nodeid: 1
+		ring0_addr: 192.168.1.1
+	}
+}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "knet", root.section("totem").values["transport"])
+	assert.Equal(t, "1", root.section("totem").sections["interface"][0].values["linknumber"])
+	assert.Equal(t, "node1", root.section("nodelist").sections["node"][0].values["name"])
+}
+
+func TestGetCorosyncConfigError(t *testing.T) {
+	_, err := getCorosyncConfig("../../test/missing.conf")
+	assert.Error(t, err)
+
+	_, err = parseCorosyncConf([]byte("totem {\n\tversion: 2\n"))
+	assert.EqualError(t, err, "unclosed section")
+
+	_, err = parseCorosyncConf([]byte("}\n"))
+	assert.EqualError(t, err, "unexpected closing bracket")
+}
+
+func TestGetCorosyncLinksRings(t *testing.T) {
+	corosyncCfgtoolExecCommand = mockCorosyncCfgtoolRings
+
+	links, err := getCorosyncLinks(context.Background(), "/usr/sbin/corosync-cfgtool")
+
+	expectedLinks := []*CorosyncLink{
+		{
+			ID:      0,
+			Address: "10.80.1.11",
+			Status:  "ring 0 active with no faults",
+		},
+		{
+			ID:      1,
+			Address: "10.80.2.11",
+			Status:  "Marking ringid 1 interface 10.80.2.11 FAULTY",
+			Faulty:  true,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLinks, links)
+}
+
+func TestGetCorosyncLinksKnet(t *testing.T) {
+	corosyncCfgtoolExecCommand = mockCorosyncCfgtoolLinks
+
+	links, err := getCorosyncLinks(context.Background(), "/usr/sbin/corosync-cfgtool")
+
+	expectedLinks := []*CorosyncLink{
+		{
+			ID:      0,
+			Address: "192.168.1.1",
+			Nodes: []*CorosyncLinkNode{
+				{NodeID: 1, Status: "localhost"},
+				{NodeID: 2, Status: "connected"},
+			},
+		},
+		{
+			ID:      1,
+			Address: "192.168.2.1",
+			Nodes: []*CorosyncLinkNode{
+				{NodeID: 1, Status: "localhost"},
+				{NodeID: 2, Status: "disconnected"},
+			},
+			Faulty: true,
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLinks, links)
+}
+
+func TestGetCorosyncLinksError(t *testing.T) {
+	corosyncCfgtoolExecCommand = mockCorosyncCfgtoolErr
+
+	links, err := getCorosyncLinks(context.Background(), "/usr/sbin/corosync-cfgtool")
+
+	assert.Empty(t, links)
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package crmmon
 
 import (
+	"context"
 	"encoding/xml"
 	"os/exec"
 
@@ -8,15 +9,15 @@ import (
 )
 
 type Parser interface {
-	Parse() (Root, error)
+	Parse(ctx context.Context) (Root, error)
 }
 
 type crmMonParser struct {
 	crmMonPath string
 }
 
-func (c *crmMonParser) Parse() (crmMon Root, err error) {
-	crmMonXML, err := exec.Command(c.crmMonPath, "-X", "--inactive").Output()
+func (c *crmMonParser) Parse(ctx context.Context) (crmMon Root, err error) {
+	crmMonXML, err := exec.CommandContext(ctx, c.crmMonPath, "-X", "--inactive").Output()
 	if err != nil {
 		return crmMon, errors.Wrap(err, "error while executing crm_mon")
 	}
@@ -1,6 +1,7 @@
 package crmmon
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ func TestConstructor(t *testing.T) {
 
 func TestParse(t *testing.T) {
 	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, "2.0.0", data.Version)
 	assert.Equal(t, 8, data.Summary.Resources.Number)
@@ -55,7 +56,7 @@ func TestParse(t *testing.T) {
 
 func TestParseClones(t *testing.T) {
 	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, 3, len(data.Clones))
 	assert.Equal(t, "msl_SAPHana_PRD_HDB00", data.Clones[0].Id)
@@ -71,7 +72,7 @@ func TestParseClones(t *testing.T) {
 
 func TestParseGroups(t *testing.T) {
 	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(data.Groups))
 
@@ -90,7 +91,7 @@ func TestParseGroups(t *testing.T) {
 
 func TestParseNodeAttributes(t *testing.T) {
 	p := NewCrmMonParser("../../../test/fake_crm_mon.sh")
-	data, err := p.Parse()
+	data, err := p.Parse(context.Background())
 	assert.NoError(t, err)
 	assert.Len(t, data.NodeAttributes.Nodes, 2)
 	assert.Equal(t, "node01", data.NodeAttributes.Nodes[0].Name)
@@ -14,13 +14,20 @@ import (
 //go:generate mockery --all
 
 type WebService interface {
-	GetInstanceProperties() (*GetInstancePropertiesResponse, error)
-	GetProcessList() (*GetProcessListResponse, error)
-	GetSystemInstanceList() (*GetSystemInstanceListResponse, error)
+	GetInstanceProperties(ctx context.Context) (*GetInstancePropertiesResponse, error)
+	GetProcessList(ctx context.Context) (*GetProcessListResponse, error)
+	GetSystemInstanceList(ctx context.Context) (*GetSystemInstanceListResponse, error)
+	GetVersionInfo(ctx context.Context) (*GetVersionInfoResponse, error)
+	HACheckConfig(ctx context.Context) (*HACheckConfigResponse, error)
+	HAGetFailoverConfig(ctx context.Context) (*HAGetFailoverConfigResponse, error)
+	ABAPGetWPTable(ctx context.Context) (*ABAPGetWPTableResponse, error)
+	EnqGetStatistic(ctx context.Context) (*EnqGetStatisticResponse, error)
 }
 
 type STATECOLOR string
 type STATECOLOR_CODE int
+type HAVerificationState string
+type HACheckCategory string
 
 const (
 	STATECOLOR_GRAY   STATECOLOR = "SAPControl-GRAY"
@@ -34,6 +41,15 @@ const (
 	STATECOLOR_CODE_GREEN  STATECOLOR_CODE = 2
 	STATECOLOR_CODE_YELLOW STATECOLOR_CODE = 3
 	STATECOLOR_CODE_RED    STATECOLOR_CODE = 4
+
+	HAVerificationStateSUCCESS HAVerificationState = "SAPControl-HA-SUCCESS"
+	HAVerificationStateWARNING HAVerificationState = "SAPControl-HA-WARNING"
+	HAVerificationStateERROR   HAVerificationState = "SAPControl-HA-ERROR"
+
+	HACheckCategorySAPCONFIGURATION HACheckCategory = "SAPControl-SAP-CONFIGURATION"
+	HACheckCategorySAPSTATE         HACheckCategory = "SAPControl-SAP-STATE"
+	HACheckCategoryHACONFIGURATION  HACheckCategory = "SAPControl-HA-CONFIGURATION"
+	HACheckCategoryHASTATE          HACheckCategory = "SAPControl-HA-STATE"
 )
 
 type GetInstanceProperties struct {
@@ -63,6 +79,52 @@ type GetSystemInstanceListResponse struct {
 	Instances []*SAPInstance `xml:"instance>item,omitempty" json:"instance>item,omitempty"`
 }
 
+type GetVersionInfo struct {
+	XMLName xml.Name `xml:"urn:SAPControl GetVersionInfo"`
+}
+
+type GetVersionInfoResponse struct {
+	XMLName  xml.Name               `xml:"urn:SAPControl GetVersionInfoResponse"`
+	Versions []*InstanceVersionInfo `xml:"version>item,omitempty" json:"version>item,omitempty"`
+}
+
+type HACheckConfig struct {
+	XMLName xml.Name `xml:"urn:SAPControl HACheckConfig"`
+}
+
+type HACheckConfigResponse struct {
+	XMLName xml.Name   `xml:"urn:SAPControl HACheckConfigResponse"`
+	Checks  []*HACheck `xml:"check>item,omitempty" json:"check>item,omitempty"`
+}
+
+type HAGetFailoverConfig struct {
+	XMLName xml.Name `xml:"urn:SAPControl HAGetFailoverConfig"`
+}
+
+type HAGetFailoverConfigResponse struct {
+	XMLName xml.Name `xml:"urn:SAPControl HAGetFailoverConfigResponse"`
+	HAFailoverConfig
+}
+
+type ABAPGetWPTable struct {
+	XMLName xml.Name `xml:"urn:SAPControl ABAPGetWPTable"`
+}
+
+type ABAPGetWPTableResponse struct {
+	XMLName       xml.Name       `xml:"urn:SAPControl ABAPGetWPTableResponse"`
+	WorkProcesses []*WorkProcess `xml:"workprocess>item,omitempty" json:"workprocess>item,omitempty"`
+}
+
+type EnqGetStatistic struct {
+	XMLName xml.Name `xml:"urn:SAPControl EnqGetStatistic"`
+}
+
+// EnqGetStatisticResponse is named after the request for consistency, the actual SOAP response element is EnqStatistic
+type EnqGetStatisticResponse struct {
+	XMLName xml.Name `xml:"urn:SAPControl EnqStatistic"`
+	EnqStatistic
+}
+
 type OSProcess struct {
 	Name        string     `xml:"name,omitempty" json:"name,omitempty" mapstructure:"name,omitempty"`
 	Description string     `xml:"description,omitempty" json:"description,omitempty" mapstructure:"description,omitempty"`
@@ -89,6 +151,76 @@ type SAPInstance struct {
 	Dispstatus    STATECOLOR `xml:"dispstatus,omitempty" json:"dispstatus,omitempty" mapstructure:"dispstatus,omitempty"`
 }
 
+type InstanceVersionInfo struct {
+	Filename    string `xml:"Filename,omitempty" json:"Filename,omitempty" mapstructure:"filename,omitempty"`
+	VersionInfo string `xml:"VersionInfo,omitempty" json:"VersionInfo,omitempty" mapstructure:"versioninfo,omitempty"`
+	Time        string `xml:"Time,omitempty" json:"Time,omitempty" mapstructure:"time,omitempty"`
+}
+
+type HACheck struct {
+	State       HAVerificationState `xml:"state,omitempty" json:"state,omitempty" mapstructure:"state,omitempty"`
+	Category    HACheckCategory     `xml:"category,omitempty" json:"category,omitempty" mapstructure:"category,omitempty"`
+	Description string              `xml:"description,omitempty" json:"description,omitempty" mapstructure:"description,omitempty"`
+	Comment     string              `xml:"comment,omitempty" json:"comment,omitempty" mapstructure:"comment,omitempty"`
+}
+
+type HAFailoverConfig struct {
+	HAActive              bool     `xml:"HAActive,omitempty" json:"HAActive,omitempty" mapstructure:"haactive,omitempty"`
+	HAProductVersion      string   `xml:"HAProductVersion,omitempty" json:"HAProductVersion,omitempty" mapstructure:"haproductversion,omitempty"`
+	HASAPInterfaceVersion string   `xml:"HASAPInterfaceVersion,omitempty" json:"HASAPInterfaceVersion,omitempty" mapstructure:"hasapinterfaceversion,omitempty"`
+	HADocumentation       string   `xml:"HADocumentation,omitempty" json:"HADocumentation,omitempty" mapstructure:"hadocumentation,omitempty"`
+	HAActiveNode          string   `xml:"HAActiveNode,omitempty" json:"HAActiveNode,omitempty" mapstructure:"haactivenode,omitempty"`
+	HANodes               []string `xml:"HANodes>item,omitempty" json:"HANodes,omitempty" mapstructure:"hanodes,omitempty"`
+}
+
+type WorkProcess struct {
+	No      int32  `xml:"No,omitempty" json:"No,omitempty" mapstructure:"no,omitempty"`
+	Typ     string `xml:"Typ,omitempty" json:"Typ,omitempty" mapstructure:"typ,omitempty"`
+	Pid     int32  `xml:"Pid,omitempty" json:"Pid,omitempty" mapstructure:"pid,omitempty"`
+	Status  string `xml:"Status,omitempty" json:"Status,omitempty" mapstructure:"status,omitempty"`
+	Reason  string `xml:"Reason,omitempty" json:"Reason,omitempty" mapstructure:"reason,omitempty"`
+	Start   string `xml:"Start,omitempty" json:"Start,omitempty" mapstructure:"start,omitempty"`
+	Err     string `xml:"Err,omitempty" json:"Err,omitempty" mapstructure:"err,omitempty"`
+	Sem     string `xml:"Sem,omitempty" json:"Sem,omitempty" mapstructure:"sem,omitempty"`
+	Cpu     string `xml:"Cpu,omitempty" json:"Cpu,omitempty" mapstructure:"cpu,omitempty"`
+	Time    string `xml:"Time,omitempty" json:"Time,omitempty" mapstructure:"time,omitempty"`
+	Program string `xml:"Program,omitempty" json:"Program,omitempty" mapstructure:"program,omitempty"`
+	Client  string `xml:"Client,omitempty" json:"Client,omitempty" mapstructure:"client,omitempty"`
+	User    string `xml:"User,omitempty" json:"User,omitempty" mapstructure:"user,omitempty"`
+	Action  string `xml:"Action,omitempty" json:"Action,omitempty" mapstructure:"action,omitempty"`
+	Table   string `xml:"Table,omitempty" json:"Table,omitempty" mapstructure:"table,omitempty"`
+}
+
+type EnqStatistic struct {
+	OwnerNow           int32      `xml:"owner-now,omitempty" json:"owner-now,omitempty" mapstructure:"owner-now,omitempty"`
+	OwnerHigh          int32      `xml:"owner-high,omitempty" json:"owner-high,omitempty" mapstructure:"owner-high,omitempty"`
+	OwnerMax           int32      `xml:"owner-max,omitempty" json:"owner-max,omitempty" mapstructure:"owner-max,omitempty"`
+	OwnerState         STATECOLOR `xml:"owner-state,omitempty" json:"owner-state,omitempty" mapstructure:"owner-state,omitempty"`
+	ArgumentsNow       int32      `xml:"arguments-now,omitempty" json:"arguments-now,omitempty" mapstructure:"arguments-now,omitempty"`
+	ArgumentsHigh      int32      `xml:"arguments-high,omitempty" json:"arguments-high,omitempty" mapstructure:"arguments-high,omitempty"`
+	ArgumentsMax       int32      `xml:"arguments-max,omitempty" json:"arguments-max,omitempty" mapstructure:"arguments-max,omitempty"`
+	ArgumentsState     STATECOLOR `xml:"arguments-state,omitempty" json:"arguments-state,omitempty" mapstructure:"arguments-state,omitempty"`
+	LocksNow           int32      `xml:"locks-now,omitempty" json:"locks-now,omitempty" mapstructure:"locks-now,omitempty"`
+	LocksHigh          int32      `xml:"locks-high,omitempty" json:"locks-high,omitempty" mapstructure:"locks-high,omitempty"`
+	LocksMax           int32      `xml:"locks-max,omitempty" json:"locks-max,omitempty" mapstructure:"locks-max,omitempty"`
+	LocksState         STATECOLOR `xml:"locks-state,omitempty" json:"locks-state,omitempty" mapstructure:"locks-state,omitempty"`
+	EnqueueRequests    int64      `xml:"enqueue-requests,omitempty" json:"enqueue-requests,omitempty" mapstructure:"enqueue-requests,omitempty"`
+	EnqueueRejects     int64      `xml:"enqueue-rejects,omitempty" json:"enqueue-rejects,omitempty" mapstructure:"enqueue-rejects,omitempty"`
+	EnqueueErrors      int64      `xml:"enqueue-errors,omitempty" json:"enqueue-errors,omitempty" mapstructure:"enqueue-errors,omitempty"`
+	DequeueRequests    int64      `xml:"dequeue-requests,omitempty" json:"dequeue-requests,omitempty" mapstructure:"dequeue-requests,omitempty"`
+	DequeueErrors      int64      `xml:"dequeue-errors,omitempty" json:"dequeue-errors,omitempty" mapstructure:"dequeue-errors,omitempty"`
+	DequeueAllRequests int64      `xml:"dequeue-all-requests,omitempty" json:"dequeue-all-requests,omitempty" mapstructure:"dequeue-all-requests,omitempty"`
+	CleanupRequests    int64      `xml:"cleanup-requests,omitempty" json:"cleanup-requests,omitempty" mapstructure:"cleanup-requests,omitempty"`
+	BackupRequests     int64      `xml:"backup-requests,omitempty" json:"backup-requests,omitempty" mapstructure:"backup-requests,omitempty"`
+	ReportingRequests  int64      `xml:"reporting-requests,omitempty" json:"reporting-requests,omitempty" mapstructure:"reporting-requests,omitempty"`
+	CompressRequests   int64      `xml:"compress-requests,omitempty" json:"compress-requests,omitempty" mapstructure:"compress-requests,omitempty"`
+	VerifyRequests     int64      `xml:"verify-requests,omitempty" json:"verify-requests,omitempty" mapstructure:"verify-requests,omitempty"`
+	LockTime           float64    `xml:"lock-time,omitempty" json:"lock-time,omitempty" mapstructure:"lock-time,omitempty"`
+	LockWaitTime       float64    `xml:"lock-wait-time,omitempty" json:"lock-wait-time,omitempty" mapstructure:"lock-wait-time,omitempty"`
+	ServerTime         float64    `xml:"server-time,omitempty" json:"server-time,omitempty" mapstructure:"server-time,omitempty"`
+	ReplicationState   STATECOLOR `xml:"replication-state,omitempty" json:"replication-state,omitempty" mapstructure:"replication-state,omitempty"`
+}
+
 type webService struct {
 	client *soap.Client
 }
@@ -115,10 +247,10 @@ func NewWebService(instNumber string) WebService {
 }
 
 // GetInstanceProperties returns a list of available instance features and information how to get it.
-func (s *webService) GetInstanceProperties() (*GetInstancePropertiesResponse, error) {
+func (s *webService) GetInstanceProperties(ctx context.Context) (*GetInstancePropertiesResponse, error) {
 	request := &GetInstanceProperties{}
 	response := &GetInstancePropertiesResponse{}
-	err := s.client.Call("''", request, response)
+	err := s.client.CallContext(ctx, "''", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -128,10 +260,10 @@ func (s *webService) GetInstanceProperties() (*GetInstancePropertiesResponse, er
 
 // GetProcessList returns a list of all processes directly started by the webservice
 // according to the SAP start profile.
-func (s *webService) GetProcessList() (*GetProcessListResponse, error) {
+func (s *webService) GetProcessList(ctx context.Context) (*GetProcessListResponse, error) {
 	request := &GetProcessList{}
 	response := &GetProcessListResponse{}
-	err := s.client.Call("''", request, response)
+	err := s.client.CallContext(ctx, "''", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -141,10 +273,72 @@ func (s *webService) GetProcessList() (*GetProcessListResponse, error) {
 
 // GetSystemInstanceList returns a list of all processes directly started by the webservice
 // according to the SAP start profile.
-func (s *webService) GetSystemInstanceList() (*GetSystemInstanceListResponse, error) {
+func (s *webService) GetSystemInstanceList(ctx context.Context) (*GetSystemInstanceListResponse, error) {
 	request := &GetSystemInstanceList{}
 	response := &GetSystemInstanceListResponse{}
-	err := s.client.Call("''", request, response)
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetVersionInfo returns a list of version information for the most important files of the instance,
+// e.g. the kernel release and patch level.
+func (s *webService) GetVersionInfo(ctx context.Context) (*GetVersionInfoResponse, error) {
+	request := &GetVersionInfo{}
+	response := &GetVersionInfoResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// HACheckConfig checks the high availability configuration and status of the system.
+func (s *webService) HACheckConfig(ctx context.Context) (*HACheckConfigResponse, error) {
+	request := &HACheckConfig{}
+	response := &HACheckConfigResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// HAGetFailoverConfig returns the high availability configuration of the instance,
+// as reported by the HA connector of the cluster software.
+func (s *webService) HAGetFailoverConfig(ctx context.Context) (*HAGetFailoverConfigResponse, error) {
+	request := &HAGetFailoverConfig{}
+	response := &HAGetFailoverConfigResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ABAPGetWPTable returns the work process table of an ABAP application server instance.
+func (s *webService) ABAPGetWPTable(ctx context.Context) (*ABAPGetWPTableResponse, error) {
+	request := &ABAPGetWPTable{}
+	response := &ABAPGetWPTableResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// EnqGetStatistic returns the statistics of the enqueue server running in the instance.
+func (s *webService) EnqGetStatistic(ctx context.Context) (*EnqGetStatisticResponse, error) {
+	request := &EnqGetStatistic{}
+	response := &EnqGetStatisticResponse{}
+	err := s.client.CallContext(ctx, "''", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -2,6 +2,7 @@ package sapsystem
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -87,10 +88,17 @@ type SAPInstance struct {
 }
 
 type SAPControl struct {
-	webService sapcontrol.WebService
-	Processes  map[string]*sapcontrol.OSProcess        `mapstructure:"processes,omitempty"`
-	Instances  map[string]*sapcontrol.SAPInstance      `mapstructure:"instances,omitempty"`
-	Properties map[string]*sapcontrol.InstanceProperty `mapstructure:"properties,omitempty"`
+	webService       sapcontrol.WebService
+	Processes        map[string]*sapcontrol.OSProcess        `mapstructure:"processes,omitempty"`
+	Instances        map[string]*sapcontrol.SAPInstance      `mapstructure:"instances,omitempty"`
+	Properties       map[string]*sapcontrol.InstanceProperty `mapstructure:"properties,omitempty"`
+	VersionInfo      []*sapcontrol.InstanceVersionInfo       `mapstructure:"versioninfo,omitempty"`
+	HAChecks         []*sapcontrol.HACheck                   `mapstructure:"hachecks,omitempty"`
+	HAFailoverConfig *sapcontrol.HAFailoverConfig            `mapstructure:"hafailoverconfig,omitempty"`
+	// Only for ABAP application server instances
+	WorkProcesses []*sapcontrol.WorkProcess `mapstructure:"workprocesses,omitempty"`
+	// Only for instances running the enqueue server
+	EnqStatistic *sapcontrol.EnqStatistic `mapstructure:"enqstatistic,omitempty"`
 }
 
 type DatabaseData struct {
@@ -105,17 +113,24 @@ type DatabaseData struct {
 	Active    string `mapstructure:"active,omitempty"`
 }
 
+// Processes identifying the optional SAPControl features of an instance
+const (
+	dispatcherProcess     string = "disp+work"
+	enqueueServerProcess  string = "enserver"   // ENSA1
+	enqueueServer2Process string = "enq_server" // ENSA2
+)
+
 var newWebService = func(instNumber string) sapcontrol.WebService {
 	return sapcontrol.NewWebService(instNumber)
 }
 
 //go:generate mockery --name=CustomCommand
 
-type CustomCommand func(name string, arg ...string) *exec.Cmd
+type CustomCommand func(ctx context.Context, name string, arg ...string) *exec.Cmd
 
-var customExecCommand CustomCommand = exec.Command
+var customExecCommand CustomCommand = exec.CommandContext
 
-func NewSAPSystemsList() (SAPSystemsList, error) {
+func NewSAPSystemsList(ctx context.Context) (SAPSystemsList, error) {
 	var systems = SAPSystemsList{}
 
 	appFS := afero.NewOsFs()
@@ -126,7 +141,11 @@ func NewSAPSystemsList() (SAPSystemsList, error) {
 
 	// Find systems
 	for _, sysPath := range systemPaths {
-		system, err := NewSAPSystem(appFS, sysPath)
+		if ctx.Err() != nil {
+			return systems, ctx.Err()
+		}
+
+		system, err := NewSAPSystem(ctx, appFS, sysPath)
 		if err != nil {
 			log.Printf("Error discovering a SAP system: %s", err)
 			continue
@@ -172,7 +191,7 @@ func (sl SAPSystemsList) GetTypesString() string {
 	return strings.Join(typesString, ",")
 }
 
-func NewSAPSystem(fs afero.Fs, sysPath string) (*SAPSystem, error) {
+func NewSAPSystem(ctx context.Context, fs afero.Fs, sysPath string) (*SAPSystem, error) {
 	system := &SAPSystem{
 		SID:       sysPath[strings.LastIndex(sysPath, "/")+1:],
 		Instances: make(map[string]*SAPInstance),
@@ -195,7 +214,7 @@ func NewSAPSystem(fs afero.Fs, sysPath string) (*SAPSystem, error) {
 	// Find instances
 	for _, instPath := range instPaths {
 		webService := newWebService(instPath[1])
-		instance, err := NewSAPInstance(webService)
+		instance, err := NewSAPInstance(ctx, webService)
 		if err != nil {
 			log.Printf("Error discovering a SAP instance: %s", err)
 			continue
@@ -222,7 +241,7 @@ func NewSAPSystem(fs afero.Fs, sysPath string) (*SAPSystem, error) {
 		}
 	}
 
-	system, err = setSystemId(fs, system)
+	system, err = setSystemId(ctx, fs, system)
 	if err != nil {
 		return system, err
 	}
@@ -325,7 +344,7 @@ func getDBAddress(system *SAPSystem) (string, error) {
 	return "", fmt.Errorf("could not get any IPv4 address")
 }
 
-func setSystemId(fs afero.Fs, system *SAPSystem) (*SAPSystem, error) {
+func setSystemId(ctx context.Context, fs afero.Fs, system *SAPSystem) (*SAPSystem, error) {
 	// Set system ID
 	var err error
 	var id string
@@ -334,7 +353,7 @@ func setSystemId(fs afero.Fs, system *SAPSystem) (*SAPSystem, error) {
 	case Database:
 		id, err = getUniqueIdHana(fs, system.SID)
 	case Application:
-		id, err = getUniqueIdApplication(system.SID)
+		id, err = getUniqueIdApplication(ctx, system.SID)
 	case DiagnosticsAgent:
 		id, err = getUniqueIdDiagnostics(fs)
 	default:
@@ -371,10 +390,10 @@ func getUniqueIdHana(fs afero.Fs, sid string) (string, error) {
 	return hanaIdMd5, nil
 }
 
-func getUniqueIdApplication(sid string) (string, error) {
+func getUniqueIdApplication(ctx context.Context, sid string) (string, error) {
 	user := fmt.Sprintf("%sadm", strings.ToLower(sid))
 	cmd := fmt.Sprintf(sappfparCmd, sid)
-	sappfpar, err := customExecCommand("su", "-lc", cmd, user).Output()
+	sappfpar, err := customExecCommand(ctx, "su", "-lc", cmd, user).Output()
 	if err != nil {
 		return "", fmt.Errorf("error running sappfpar command with sid %s", sid)
 	}
@@ -441,13 +460,13 @@ func getDatabases(fs afero.Fs, sid string) ([]*DatabaseData, error) {
 	return databaseList, nil
 }
 
-func NewSAPInstance(w sapcontrol.WebService) (*SAPInstance, error) {
+func NewSAPInstance(ctx context.Context, w sapcontrol.WebService) (*SAPInstance, error) {
 	host, _ := os.Hostname()
 	var sapInstance = &SAPInstance{
 		Host: host,
 	}
 
-	scontrol, err := NewSAPControl(w)
+	scontrol, err := NewSAPControl(ctx, w)
 	if err != nil {
 		return sapInstance, err
 	}
@@ -468,9 +487,9 @@ func NewSAPInstance(w sapcontrol.WebService) (*SAPInstance, error) {
 
 	if sapInstance.Type == Database {
 		sid := sapInstance.SAPControl.Properties["SAPSYSTEMNAME"].Value
-		sapInstance.SystemReplication = systemReplicationStatus(sid, sapInstance.Name)
-		sapInstance.HostConfiguration = landscapeHostConfiguration(sid, sapInstance.Name)
-		sapInstance.HdbnsutilSRstate = hdbnsutilSrstate(sid, sapInstance.Name)
+		sapInstance.SystemReplication = systemReplicationStatus(ctx, sid, sapInstance.Name)
+		sapInstance.HostConfiguration = landscapeHostConfiguration(ctx, sid, sapInstance.Name)
+		sapInstance.HdbnsutilSRstate = hdbnsutilSrstate(ctx, sid, sapInstance.Name)
 	}
 
 	return sapInstance, nil
@@ -501,36 +520,36 @@ func detectType(sapControl *SAPControl) (int, error) {
 	return instanceType, nil
 }
 
-func runPythonSupport(sid, instance, script string) map[string]interface{} {
+func runPythonSupport(ctx context.Context, sid, instance, script string) map[string]interface{} {
 	user := fmt.Sprintf("%sadm", strings.ToLower(sid))
 	cmdPath := path.Join(sapInstallationPath, sid, instance, "exe/python_support", script)
 	cmd := fmt.Sprintf("python %s --sapcontrol=1", cmdPath)
 	// Even with a error return code, some data is available
-	srData, _ := customExecCommand("su", "-lc", cmd, user).Output()
+	srData, _ := customExecCommand(ctx, "su", "-lc", cmd, user).Output()
 
 	dataMap := internal.FindMatches(`(\S+)=(.*)`, srData)
 
 	return dataMap
 }
 
-func systemReplicationStatus(sid, instance string) map[string]interface{} {
-	return runPythonSupport(sid, instance, "systemReplicationStatus.py")
+func systemReplicationStatus(ctx context.Context, sid, instance string) map[string]interface{} {
+	return runPythonSupport(ctx, sid, instance, "systemReplicationStatus.py")
 }
 
-func landscapeHostConfiguration(sid, instance string) map[string]interface{} {
-	return runPythonSupport(sid, instance, "landscapeHostConfiguration.py")
+func landscapeHostConfiguration(ctx context.Context, sid, instance string) map[string]interface{} {
+	return runPythonSupport(ctx, sid, instance, "landscapeHostConfiguration.py")
 }
 
-func hdbnsutilSrstate(sid, instance string) map[string]interface{} {
+func hdbnsutilSrstate(ctx context.Context, sid, instance string) map[string]interface{} {
 	user := fmt.Sprintf("%sadm", strings.ToLower(sid))
 	cmdPath := path.Join(sapInstallationPath, sid, instance, "exe", "hdbnsutil")
 	cmd := fmt.Sprintf("%s -sr_state -sapcontrol=1", cmdPath)
-	srData, _ := customExecCommand("su", "-lc", cmd, user).Output()
+	srData, _ := customExecCommand(ctx, "su", "-lc", cmd, user).Output()
 	dataMap := internal.FindMatches(`(.+)=(.*)`, srData)
 	return dataMap
 }
 
-func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
+func NewSAPControl(ctx context.Context, w sapcontrol.WebService) (*SAPControl, error) {
 	var scontrol = &SAPControl{
 		webService: w,
 		Processes:  make(map[string]*sapcontrol.OSProcess),
@@ -538,7 +557,7 @@ func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
 		Properties: make(map[string]*sapcontrol.InstanceProperty),
 	}
 
-	properties, err := scontrol.webService.GetInstanceProperties()
+	properties, err := scontrol.webService.GetInstanceProperties(ctx)
 	if err != nil {
 		return scontrol, errors.Wrap(err, "SAPControl web service error")
 	}
@@ -547,7 +566,7 @@ func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
 		scontrol.Properties[prop.Property] = prop
 	}
 
-	processes, err := scontrol.webService.GetProcessList()
+	processes, err := scontrol.webService.GetProcessList(ctx)
 	if err != nil {
 		return scontrol, errors.Wrap(err, "SAPControl web service error")
 	}
@@ -556,7 +575,7 @@ func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
 		scontrol.Processes[proc.Name] = proc
 	}
 
-	instances, err := scontrol.webService.GetSystemInstanceList()
+	instances, err := scontrol.webService.GetSystemInstanceList(ctx)
 	if err != nil {
 		return scontrol, errors.Wrap(err, "SAPControl web service error")
 	}
@@ -565,5 +584,56 @@ func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
 		scontrol.Instances[inst.Hostname] = inst
 	}
 
+	// The following data is not available on every instance type and version,
+	// so errors are not blocking
+	versionInfo, err := scontrol.webService.GetVersionInfo(ctx)
+	if err != nil {
+		log.Warnf("Error getting the SAPControl version info: %s", err)
+	} else {
+		scontrol.VersionInfo = versionInfo.Versions
+	}
+
+	failoverConfig, err := scontrol.webService.HAGetFailoverConfig(ctx)
+	if err != nil {
+		log.Debugf("Error getting the SAPControl HA failover config: %s", err)
+	} else {
+		scontrol.HAFailoverConfig = &failoverConfig.HAFailoverConfig
+	}
+
+	haChecks, err := scontrol.webService.HACheckConfig(ctx)
+	if err != nil {
+		log.Debugf("Error getting the SAPControl HA config checks: %s", err)
+	} else {
+		scontrol.HAChecks = haChecks.Checks
+	}
+
+	if scontrol.hasProcess(dispatcherProcess) {
+		wpTable, err := scontrol.webService.ABAPGetWPTable(ctx)
+		if err != nil {
+			log.Warnf("Error getting the SAPControl work process table: %s", err)
+		} else {
+			scontrol.WorkProcesses = wpTable.WorkProcesses
+		}
+	}
+
+	if scontrol.hasProcess(enqueueServerProcess, enqueueServer2Process) {
+		enqStatistic, err := scontrol.webService.EnqGetStatistic(ctx)
+		if err != nil {
+			log.Warnf("Error getting the SAPControl enqueue statistic: %s", err)
+		} else {
+			scontrol.EnqStatistic = &enqStatistic.EnqStatistic
+		}
+	}
+
 	return scontrol, nil
 }
+
+func (s *SAPControl) hasProcess(names ...string) bool {
+	for _, name := range names {
+		if _, ok := s.Processes[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
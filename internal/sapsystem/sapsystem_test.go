@@ -1,6 +1,7 @@
 package sapsystem
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	sapSystemMocks "github.com/trento-project/trento/internal/sapsystem/mocks"
 	"github.com/trento-project/trento/internal/sapsystem/sapcontrol"
 	sapControlMocks "github.com/trento-project/trento/internal/sapsystem/sapcontrol/mocks"
@@ -33,7 +35,7 @@ func fakeNewWebService(instNumber string) sapcontrol.WebService {
 		instance = "ERS02"
 	}
 
-	mockWebService.On("GetInstanceProperties").Return(&sapcontrol.GetInstancePropertiesResponse{
+	mockWebService.On("GetInstanceProperties", mock.Anything).Return(&sapcontrol.GetInstancePropertiesResponse{
 		Properties: []*sapcontrol.InstanceProperty{
 			{
 				Property:     "SAPSYSTEMNAME",
@@ -53,14 +55,18 @@ func fakeNewWebService(instNumber string) sapcontrol.WebService {
 		},
 	}, nil)
 
-	mockWebService.On("GetProcessList").Return(&sapcontrol.GetProcessListResponse{
+	mockWebService.On("GetProcessList", mock.Anything).Return(&sapcontrol.GetProcessListResponse{
 		Processes: []*sapcontrol.OSProcess{},
 	}, nil)
 
-	mockWebService.On("GetSystemInstanceList").Return(&sapcontrol.GetSystemInstanceListResponse{
+	mockWebService.On("GetSystemInstanceList", mock.Anything).Return(&sapcontrol.GetSystemInstanceListResponse{
 		Instances: []*sapcontrol.SAPInstance{},
 	}, nil)
 
+	mockWebService.On("GetVersionInfo", mock.Anything).Return(&sapcontrol.GetVersionInfoResponse{}, nil)
+	mockWebService.On("HAGetFailoverConfig", mock.Anything).Return(&sapcontrol.HAGetFailoverConfigResponse{}, nil)
+	mockWebService.On("HACheckConfig", mock.Anything).Return(&sapcontrol.HACheckConfigResponse{}, nil)
+
 	return mockWebService
 }
 
@@ -113,9 +119,9 @@ func TestNewSAPSystem(t *testing.T) {
 	}
 
 	cmd := fmt.Sprintf(sappfparCmd, "DEV")
-	mockCommand.On("Execute", "su", "-lc", cmd, "devadm").Return(mockSappfpar())
+	mockCommand.On("Execute", mock.Anything, "su", "-lc", cmd, "devadm").Return(mockSappfpar())
 
-	system, err := NewSAPSystem(appFS, "/usr/sap/DEV")
+	system, err := NewSAPSystem(context.Background(), appFS, "/usr/sap/DEV")
 
 	assert.Equal(t, Unknown, system.Type)
 	assert.Contains(t, system.Instances, "ASCS01")
@@ -165,7 +171,7 @@ key2 = value2
 		SID:  "DEV",
 	}
 
-	system, err := setSystemId(appFS, system)
+	system, err := setSystemId(context.Background(), appFS, system)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "089d1a278481b86e821237f8e98e6de7", system.Id)
@@ -177,14 +183,14 @@ func TestSetSystemIdApplication(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 	cmd := fmt.Sprintf(sappfparCmd, "DEV")
-	mockCommand.On("Execute", "su", "-lc", cmd, "devadm").Return(mockSappfpar())
+	mockCommand.On("Execute", mock.Anything, "su", "-lc", cmd, "devadm").Return(mockSappfpar())
 
 	system := &SAPSystem{
 		Type: Application,
 		SID:  "DEV",
 	}
 
-	system, err := setSystemId(appFS, system)
+	system, err := setSystemId(context.Background(), appFS, system)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "089d1a278481b86e821237f8e98e6de7", system.Id)
@@ -198,7 +204,7 @@ func TestSetSystemIdOther(t *testing.T) {
 		SID:  "DEV",
 	}
 
-	system, err := setSystemId(appFS, system)
+	system, err := setSystemId(context.Background(), appFS, system)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "-", system.Id)
@@ -219,7 +225,7 @@ func TestSetSystemIdDiagnostics(t *testing.T) {
 		SID:  "DAA",
 	}
 
-	system, err := setSystemId(appFS, system)
+	system, err := setSystemId(context.Background(), appFS, system)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "d3d5dd5ec501127e0011a2531e3b11ff", system.Id)
@@ -292,7 +298,7 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockWebService.On("GetInstanceProperties").Return(&sapcontrol.GetInstancePropertiesResponse{
+	mockWebService.On("GetInstanceProperties", mock.Anything).Return(&sapcontrol.GetInstancePropertiesResponse{
 		Properties: []*sapcontrol.InstanceProperty{
 			{
 				Property:     "prop1",
@@ -317,7 +323,7 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 		},
 	}, nil)
 
-	mockWebService.On("GetProcessList").Return(&sapcontrol.GetProcessListResponse{
+	mockWebService.On("GetProcessList", mock.Anything).Return(&sapcontrol.GetProcessListResponse{
 		Processes: []*sapcontrol.OSProcess{
 			{
 				Name:        "enserver",
@@ -340,7 +346,7 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 		},
 	}, nil)
 
-	mockWebService.On("GetSystemInstanceList").Return(&sapcontrol.GetSystemInstanceListResponse{
+	mockWebService.On("GetSystemInstanceList", mock.Anything).Return(&sapcontrol.GetSystemInstanceListResponse{
 		Instances: []*sapcontrol.SAPInstance{
 			{
 				Hostname:      "host1",
@@ -363,19 +369,25 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 		},
 	}, nil)
 
-	mockCommand.On("Execute", "su", "-lc", "python /usr/sap/PRD/HDB00/exe/python_support/systemReplicationStatus.py --sapcontrol=1", "prdadm").Return(
+	// The optional SAPControl data is not available, the instance is discovered anyway
+	mockWebService.On("GetVersionInfo", mock.Anything).Return(nil, fmt.Errorf("some error"))
+	mockWebService.On("HAGetFailoverConfig", mock.Anything).Return(nil, fmt.Errorf("some error"))
+	mockWebService.On("HACheckConfig", mock.Anything).Return(nil, fmt.Errorf("some error"))
+	mockWebService.On("EnqGetStatistic", mock.Anything).Return(nil, fmt.Errorf("some error"))
+
+	mockCommand.On("Execute", mock.Anything, "su", "-lc", "python /usr/sap/PRD/HDB00/exe/python_support/systemReplicationStatus.py --sapcontrol=1", "prdadm").Return(
 		mockSystemReplicationStatus(),
 	)
 
-	mockCommand.On("Execute", "su", "-lc", "python /usr/sap/PRD/HDB00/exe/python_support/landscapeHostConfiguration.py --sapcontrol=1", "prdadm").Return(
+	mockCommand.On("Execute", mock.Anything, "su", "-lc", "python /usr/sap/PRD/HDB00/exe/python_support/landscapeHostConfiguration.py --sapcontrol=1", "prdadm").Return(
 		mockLandscapeHostConfiguration(),
 	)
 
-	mockCommand.On("Execute", "su", "-lc", "/usr/sap/PRD/HDB00/exe/hdbnsutil -sr_state -sapcontrol=1", "prdadm").Return(
+	mockCommand.On("Execute", mock.Anything, "su", "-lc", "/usr/sap/PRD/HDB00/exe/hdbnsutil -sr_state -sapcontrol=1", "prdadm").Return(
 		mockHdbnsutilSrstate(),
 	)
 
-	sapInstance, _ := NewSAPInstance(mockWebService)
+	sapInstance, _ := NewSAPInstance(context.Background(), mockWebService)
 	host, _ := os.Hostname()
 
 	expectedInstance := &SAPInstance{
@@ -541,7 +553,7 @@ func TestNewSAPInstanceDatabase(t *testing.T) {
 func TestNewSAPInstanceApp(t *testing.T) {
 	mockWebService := new(sapControlMocks.WebService)
 
-	mockWebService.On("GetInstanceProperties").Return(&sapcontrol.GetInstancePropertiesResponse{
+	mockWebService.On("GetInstanceProperties", mock.Anything).Return(&sapcontrol.GetInstancePropertiesResponse{
 		Properties: []*sapcontrol.InstanceProperty{
 			{
 				Property:     "prop1",
@@ -566,7 +578,7 @@ func TestNewSAPInstanceApp(t *testing.T) {
 		},
 	}, nil)
 
-	mockWebService.On("GetProcessList").Return(&sapcontrol.GetProcessListResponse{
+	mockWebService.On("GetProcessList", mock.Anything).Return(&sapcontrol.GetProcessListResponse{
 		Processes: []*sapcontrol.OSProcess{
 			{
 				Name:        "enserver",
@@ -589,7 +601,7 @@ func TestNewSAPInstanceApp(t *testing.T) {
 		},
 	}, nil)
 
-	mockWebService.On("GetSystemInstanceList").Return(&sapcontrol.GetSystemInstanceListResponse{
+	mockWebService.On("GetSystemInstanceList", mock.Anything).Return(&sapcontrol.GetSystemInstanceListResponse{
 		Instances: []*sapcontrol.SAPInstance{
 			{
 				Hostname:      "host1",
@@ -612,7 +624,53 @@ func TestNewSAPInstanceApp(t *testing.T) {
 		},
 	}, nil)
 
-	sapInstance, _ := NewSAPInstance(mockWebService)
+	mockWebService.On("GetVersionInfo", mock.Anything).Return(&sapcontrol.GetVersionInfoResponse{
+		Versions: []*sapcontrol.InstanceVersionInfo{
+			{
+				Filename:    "/usr/sap/PRD/ASCS00/exe/sapstartsrv",
+				VersionInfo: "753, patch 900, changelist 2094654, RKS compatibility level 1, optU (Oct 15 2021, 05:59:40), linuxx86_64",
+				Time:        "2021 10 15 05:59:40",
+			},
+		},
+	}, nil)
+
+	mockWebService.On("HAGetFailoverConfig", mock.Anything).Return(&sapcontrol.HAGetFailoverConfigResponse{
+		HAFailoverConfig: sapcontrol.HAFailoverConfig{
+			HAActive:              true,
+			HAProductVersion:      "SUSE Linux Enterprise Server for SAP Applications 15 SP3",
+			HASAPInterfaceVersion: "SUSE Linux Enterprise Server for SAP Applications 15 SP3 (sap_suse_cluster_connector 3.1.2)",
+			HADocumentation:       "https://www.suse.com/products/sles-for-sap/resource-library/sap-best-practices/",
+			HAActiveNode:          "host1",
+			HANodes:               []string{"host1", "host2"},
+		},
+	}, nil)
+
+	mockWebService.On("HACheckConfig", mock.Anything).Return(&sapcontrol.HACheckConfigResponse{
+		Checks: []*sapcontrol.HACheck{
+			{
+				State:       sapcontrol.HAVerificationStateSUCCESS,
+				Category:    sapcontrol.HACheckCategoryHASTATE,
+				Description: "SAP instance PRD_ASCS00 status",
+				Comment:     "0 warning(s), 0 error(s)",
+			},
+		},
+	}, nil)
+
+	mockWebService.On("EnqGetStatistic", mock.Anything).Return(&sapcontrol.EnqGetStatisticResponse{
+		EnqStatistic: sapcontrol.EnqStatistic{
+			OwnerNow:         2,
+			OwnerHigh:        10,
+			OwnerMax:         1000,
+			OwnerState:       sapcontrol.STATECOLOR_GREEN,
+			LocksNow:         3,
+			LocksHigh:        20,
+			LocksMax:         2000,
+			LocksState:       sapcontrol.STATECOLOR_GREEN,
+			ReplicationState: sapcontrol.STATECOLOR_GREEN,
+		},
+	}, nil)
+
+	sapInstance, _ := NewSAPInstance(context.Background(), mockWebService)
 	host, _ := os.Hostname()
 
 	expectedInstance := &SAPInstance{
@@ -683,6 +741,40 @@ func TestNewSAPInstanceApp(t *testing.T) {
 					Dispstatus:    sapcontrol.STATECOLOR_YELLOW,
 				},
 			},
+			VersionInfo: []*sapcontrol.InstanceVersionInfo{
+				{
+					Filename:    "/usr/sap/PRD/ASCS00/exe/sapstartsrv",
+					VersionInfo: "753, patch 900, changelist 2094654, RKS compatibility level 1, optU (Oct 15 2021, 05:59:40), linuxx86_64",
+					Time:        "2021 10 15 05:59:40",
+				},
+			},
+			HAFailoverConfig: &sapcontrol.HAFailoverConfig{
+				HAActive:              true,
+				HAProductVersion:      "SUSE Linux Enterprise Server for SAP Applications 15 SP3",
+				HASAPInterfaceVersion: "SUSE Linux Enterprise Server for SAP Applications 15 SP3 (sap_suse_cluster_connector 3.1.2)",
+				HADocumentation:       "https://www.suse.com/products/sles-for-sap/resource-library/sap-best-practices/",
+				HAActiveNode:          "host1",
+				HANodes:               []string{"host1", "host2"},
+			},
+			HAChecks: []*sapcontrol.HACheck{
+				{
+					State:       sapcontrol.HAVerificationStateSUCCESS,
+					Category:    sapcontrol.HACheckCategoryHASTATE,
+					Description: "SAP instance PRD_ASCS00 status",
+					Comment:     "0 warning(s), 0 error(s)",
+				},
+			},
+			EnqStatistic: &sapcontrol.EnqStatistic{
+				OwnerNow:         2,
+				OwnerHigh:        10,
+				OwnerMax:         1000,
+				OwnerState:       sapcontrol.STATECOLOR_GREEN,
+				LocksNow:         3,
+				LocksHigh:        20,
+				LocksMax:         2000,
+				LocksState:       sapcontrol.STATECOLOR_GREEN,
+				ReplicationState: sapcontrol.STATECOLOR_GREEN,
+			},
 		},
 		SystemReplication: SystemReplication(nil),
 		HostConfiguration: HostConfiguration(nil),
@@ -690,6 +782,45 @@ func TestNewSAPInstanceApp(t *testing.T) {
 	}
 
 	assert.Equal(t, expectedInstance, sapInstance)
+	mockWebService.AssertNotCalled(t, "ABAPGetWPTable", mock.Anything)
+}
+
+func TestNewSAPControlWorkProcesses(t *testing.T) {
+	mockWebService := new(sapControlMocks.WebService)
+
+	mockWebService.On("GetInstanceProperties", mock.Anything).Return(&sapcontrol.GetInstancePropertiesResponse{}, nil)
+	mockWebService.On("GetProcessList", mock.Anything).Return(&sapcontrol.GetProcessListResponse{
+		Processes: []*sapcontrol.OSProcess{
+			{
+				Name:       "disp+work",
+				Dispstatus: sapcontrol.STATECOLOR_GREEN,
+				Textstatus: "Running",
+				Pid:        30787,
+			},
+		},
+	}, nil)
+	mockWebService.On("GetSystemInstanceList", mock.Anything).Return(&sapcontrol.GetSystemInstanceListResponse{}, nil)
+	mockWebService.On("GetVersionInfo", mock.Anything).Return(&sapcontrol.GetVersionInfoResponse{}, nil)
+	mockWebService.On("HAGetFailoverConfig", mock.Anything).Return(nil, fmt.Errorf("some error"))
+	mockWebService.On("HACheckConfig", mock.Anything).Return(nil, fmt.Errorf("some error"))
+	mockWebService.On("ABAPGetWPTable", mock.Anything).Return(&sapcontrol.ABAPGetWPTableResponse{
+		WorkProcesses: []*sapcontrol.WorkProcess{
+			{No: 0, Typ: "DIA", Pid: 4711, Status: "Wait", Time: "", Cpu: "0:00:01"},
+			{No: 1, Typ: "BTC", Pid: 4712, Status: "Run", Time: "30", Program: "RSBTCRTE", Client: "001", User: "DDIC"},
+		},
+	}, nil)
+
+	sapControl, err := NewSAPControl(context.Background(), mockWebService)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*sapcontrol.WorkProcess{
+		{No: 0, Typ: "DIA", Pid: 4711, Status: "Wait", Time: "", Cpu: "0:00:01"},
+		{No: 1, Typ: "BTC", Pid: 4712, Status: "Run", Time: "30", Program: "RSBTCRTE", Client: "001", User: "DDIC"},
+	}, sapControl.WorkProcesses)
+	assert.Nil(t, sapControl.HAFailoverConfig)
+	assert.Nil(t, sapControl.HAChecks)
+	assert.Nil(t, sapControl.EnqStatistic)
+	mockWebService.AssertNotCalled(t, "EnqGetStatistic", mock.Anything)
 }
 
 func TestGetSIDsString(t *testing.T) {
@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	context "context"
 	exec "os/exec"
 
 	mock "github.com/stretchr/testify/mock"
@@ -13,20 +14,20 @@ type CustomCommand struct {
 	mock.Mock
 }
 
-// Execute provides a mock function with given fields: name, arg
-func (_m *CustomCommand) Execute(name string, arg ...string) *exec.Cmd {
+// Execute provides a mock function with given fields: ctx, name, arg
+func (_m *CustomCommand) Execute(ctx context.Context, name string, arg ...string) *exec.Cmd {
 	_va := make([]interface{}, len(arg))
 	for _i := range arg {
 		_va[_i] = arg[_i]
 	}
 	var _ca []interface{}
-	_ca = append(_ca, name)
+	_ca = append(_ca, ctx, name)
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
 	var r0 *exec.Cmd
-	if rf, ok := ret.Get(0).(func(string, ...string) *exec.Cmd); ok {
-		r0 = rf(name, arg...)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) *exec.Cmd); ok {
+		r0 = rf(ctx, name, arg...)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*exec.Cmd)
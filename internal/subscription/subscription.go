@@ -1,6 +1,7 @@
 package subscription
 
 import (
+	"context"
 	"encoding/json"
 	"os/exec"
 
@@ -24,15 +25,15 @@ type Subscription struct {
 	Type               string `json:"type,omitempty" mapstructure:"type,omitempty"`
 }
 
-type CustomCommand func(name string, arg ...string) *exec.Cmd
+type CustomCommand func(ctx context.Context, name string, arg ...string) *exec.Cmd
 
-var customExecCommand CustomCommand = exec.Command
+var customExecCommand CustomCommand = exec.CommandContext
 
-func NewSubscriptions() (Subscriptions, error) {
+func NewSubscriptions(ctx context.Context) (Subscriptions, error) {
 	var subs Subscriptions
 
 	log.Info("Identifying the SUSE subscription details...")
-	output, err := customExecCommand("SUSEConnect", "-s").Output()
+	output, err := customExecCommand(ctx, "SUSEConnect", "-s").Output()
 	if err != nil {
 		return nil, err
 	}
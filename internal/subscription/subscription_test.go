@@ -1,11 +1,12 @@
 package subscription
 
 import (
+	"context"
 	"os/exec"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	//"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/mock"
 	"github.com/trento-project/trento/internal/subscription/mocks"
 )
 
@@ -27,11 +28,11 @@ func TestNewSubscriptions(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockCommand.On("Execute", "SUSEConnect", "-s").Return(
+	mockCommand.On("Execute", mock.Anything, "SUSEConnect", "-s").Return(
 		mockSUSEConnect(),
 	)
 
-	subs, err := NewSubscriptions()
+	subs, err := NewSubscriptions(context.Background())
 
 	expectedSubs := Subscriptions{
 		&Subscription{
@@ -61,11 +62,11 @@ func TestNewSubscriptionsErr(t *testing.T) {
 
 	customExecCommand = mockCommand.Execute
 
-	mockCommand.On("Execute", "SUSEConnect", "-s").Return(
+	mockCommand.On("Execute", mock.Anything, "SUSEConnect", "-s").Return(
 		mockSUSEConnectErr(),
 	)
 
-	subs, err := NewSubscriptions()
+	subs, err := NewSubscriptions(context.Background())
 
 	assert.Equal(t, Subscriptions(nil), subs)
 	assert.EqualError(t, err, "exec: \"error\": executable file not found in $PATH")
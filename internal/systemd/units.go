@@ -0,0 +1,108 @@
+package systemd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:generate mockery --all
+
+const (
+	ActiveStateFailed = "failed"
+	LoadStateNotFound = "not-found"
+)
+
+// DefaultUnits is the list of the units of the SAP and HA stack discovered when none is configured
+var DefaultUnits = []string{
+	"pacemaker.service",
+	"corosync.service",
+	"sbd.service",
+	"sapinit.service",
+	"prometheus-node_exporter.service",
+	"prometheus-ha_cluster_exporter.service",
+}
+
+type Units []*Unit
+
+type Unit struct {
+	Name          string `json:"name" mapstructure:"name"`
+	Description   string `json:"description,omitempty" mapstructure:"description,omitempty"`
+	LoadState     string `json:"load_state" mapstructure:"load_state"`
+	ActiveState   string `json:"active_state" mapstructure:"active_state"`
+	SubState      string `json:"sub_state" mapstructure:"sub_state"`
+	UnitFileState string `json:"unit_file_state,omitempty" mapstructure:"unit_file_state,omitempty"`
+}
+
+type CustomCommand func(ctx context.Context, name string, arg ...string) *exec.Cmd
+
+var customExecCommand CustomCommand = exec.CommandContext
+
+// NewUnits returns the state of the given units, as reported by systemctl.
+// Units which are not installed are returned anyway, with the not-found load state
+func NewUnits(ctx context.Context, names []string) (Units, error) {
+	if len(names) == 0 {
+		return Units{}, nil
+	}
+
+	log.Info("Identifying the systemd units state...")
+	args := append([]string{"show", "--property=Id,Description,LoadState,ActiveState,SubState,UnitFileState", "--"}, names...)
+	output, err := customExecCommand(ctx, "systemctl", args...).Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "error while running systemctl")
+	}
+
+	log.Debugf("systemctl output: %s", string(output))
+
+	units := parseSystemctlShow(output)
+	log.Infof("Systemd units (%d entries) discovered", len(units))
+
+	return units, nil
+}
+
+// parseSystemctlShow parses the properties printed by systemctl show, one block separated by an empty line per unit
+func parseSystemctlShow(output []byte) Units {
+	units := Units{}
+
+	var unit *Unit
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			unit = nil
+			continue
+		}
+
+		if unit == nil {
+			unit = &Unit{}
+			units = append(units, unit)
+		}
+
+		property := strings.SplitN(line, "=", 2)
+		if len(property) != 2 {
+			continue
+		}
+
+		switch property[0] {
+		case "Id":
+			unit.Name = property[1]
+		case "Description":
+			unit.Description = property[1]
+		case "LoadState":
+			unit.LoadState = property[1]
+		case "ActiveState":
+			unit.ActiveState = property[1]
+		case "SubState":
+			unit.SubState = property[1]
+		case "UnitFileState":
+			unit.UnitFileState = property[1]
+		}
+	}
+
+	return units
+}
@@ -0,0 +1,100 @@
+package systemd
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/internal/systemd/mocks"
+	_ "github.com/trento-project/trento/test"
+)
+
+func mockSystemctlShow() *exec.Cmd {
+	return exec.Command("cat", "./test/fixtures/discovery/systemd/systemctl_show.output")
+}
+
+func mockSystemctlShowErr() *exec.Cmd {
+	return exec.Command("error")
+}
+
+func TestNewUnits(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", mock.Anything, "systemctl", "show",
+		"--property=Id,Description,LoadState,ActiveState,SubState,UnitFileState", "--",
+		"pacemaker.service", "corosync.service", "sbd.service", "prometheus-hanadb_exporter@PRD.service").Return(
+		mockSystemctlShow(),
+	)
+
+	units, err := NewUnits(context.Background(), []string{
+		"pacemaker.service", "corosync.service", "sbd.service", "prometheus-hanadb_exporter@PRD.service",
+	})
+
+	expectedUnits := Units{
+		&Unit{
+			Name:          "pacemaker.service",
+			Description:   "Pacemaker High Availability Cluster Manager",
+			LoadState:     "loaded",
+			ActiveState:   "active",
+			SubState:      "running",
+			UnitFileState: "enabled",
+		},
+		&Unit{
+			Name:          "corosync.service",
+			Description:   "Corosync Cluster Engine",
+			LoadState:     "loaded",
+			ActiveState:   "active",
+			SubState:      "running",
+			UnitFileState: "disabled",
+		},
+		&Unit{
+			Name:          "sbd.service",
+			Description:   "Shared-storage based fencing daemon",
+			LoadState:     "loaded",
+			ActiveState:   "failed",
+			SubState:      "failed",
+			UnitFileState: "enabled",
+		},
+		&Unit{
+			Name:        "prometheus-hanadb_exporter@PRD.service",
+			Description: "prometheus-hanadb_exporter@PRD.service",
+			LoadState:   "not-found",
+			ActiveState: "inactive",
+			SubState:    "dead",
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUnits, units)
+}
+
+func TestNewUnitsEmpty(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	units, err := NewUnits(context.Background(), []string{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, units)
+	mockCommand.AssertNotCalled(t, "Execute")
+}
+
+func TestNewUnitsErr(t *testing.T) {
+	mockCommand := new(mocks.CustomCommand)
+
+	customExecCommand = mockCommand.Execute
+
+	mockCommand.On("Execute", mock.Anything, "systemctl", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		mockSystemctlShowErr(),
+	)
+
+	units, err := NewUnits(context.Background(), []string{"pacemaker.service"})
+
+	assert.Nil(t, units)
+	assert.Error(t, err)
+}
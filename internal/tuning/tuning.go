@@ -0,0 +1,143 @@
+package tuning
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//go:generate mockery --all
+
+const (
+	ToolSaptune = "saptune"
+	ToolSapconf = "sapconf"
+)
+
+// Status is the tuning state of the host, made by saptune or sapconf
+type Status struct {
+	// Tool is the tool tuning the host, empty if it's not tuned
+	Tool                     string   `json:"tool" mapstructure:"tool"`
+	SaptuneVersion           string   `json:"saptune_version,omitempty" mapstructure:"saptune_version,omitempty"`
+	SaptuneConfiguredVersion string   `json:"saptune_configured_version,omitempty" mapstructure:"saptune_configured_version,omitempty"`
+	Solution                 string   `json:"solution,omitempty" mapstructure:"solution,omitempty"`
+	Notes                    []string `json:"notes,omitempty" mapstructure:"notes,omitempty"`
+	AppliedNotes             []string `json:"applied_notes,omitempty" mapstructure:"applied_notes,omitempty"`
+	Compliant                bool     `json:"compliant" mapstructure:"compliant"`
+	SapconfVersion           string   `json:"sapconf_version,omitempty" mapstructure:"sapconf_version,omitempty"`
+	SapconfActive            bool     `json:"sapconf_active" mapstructure:"sapconf_active"`
+	SapconfProfile           string   `json:"sapconf_profile,omitempty" mapstructure:"sapconf_profile,omitempty"`
+}
+
+type CustomCommand func(ctx context.Context, name string, arg ...string) *exec.Cmd
+
+var customExecCommand CustomCommand = exec.CommandContext
+
+// NewStatus returns the tuning state of the host.
+// saptune takes precedence when it has a solution or notes enabled, sapconf is considered otherwise
+func NewStatus(ctx context.Context) (*Status, error) {
+	log.Info("Identifying the SAP tuning state...")
+	status := &Status{}
+
+	status.SaptuneVersion = getPackageVersion(ctx, ToolSaptune)
+	if status.SaptuneVersion != "" {
+		discoverSaptune(ctx, status)
+	}
+
+	status.SapconfVersion = getPackageVersion(ctx, ToolSapconf)
+	if status.SapconfVersion != "" {
+		discoverSapconf(ctx, status)
+	}
+
+	switch {
+	case status.Solution != "" || len(status.Notes) > 0:
+		status.Tool = ToolSaptune
+	case status.SapconfActive:
+		status.Tool = ToolSapconf
+	}
+
+	log.Infof("SAP tuning state discovered, tuned by: %s", status.Tool)
+
+	return status, nil
+}
+
+func getPackageVersion(ctx context.Context, name string) string {
+	output, err := customExecCommand(ctx, "rpm", "-q", "--qf", "%{VERSION}", name).Output()
+	if err != nil {
+		log.Debugf("%s package is not installed: %s", name, err)
+		return ""
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+func discoverSaptune(ctx context.Context, status *Status) {
+	// Output: current active saptune version is '3'
+	output, err := customExecCommand(ctx, "saptune", "version").Output()
+	if err != nil {
+		log.Warnf("Error running saptune version: %s", err)
+	}
+	if match := regexp.MustCompile(`'(\w+)'`).FindSubmatch(output); match != nil {
+		status.SaptuneConfiguredVersion = string(match[1])
+	}
+
+	output, err = customExecCommand(ctx, "saptune", "solution", "enabled").Output()
+	if err != nil {
+		log.Warnf("Error running saptune solution enabled: %s", err)
+	}
+	if solutions := parseSaptuneList(output); len(solutions) > 0 {
+		status.Solution = solutions[0]
+	}
+
+	output, err = customExecCommand(ctx, "saptune", "note", "enabled").Output()
+	if err != nil {
+		log.Warnf("Error running saptune note enabled: %s", err)
+	}
+	status.Notes = parseSaptuneList(output)
+
+	output, err = customExecCommand(ctx, "saptune", "note", "applied").Output()
+	if err != nil {
+		log.Warnf("Error running saptune note applied: %s", err)
+	}
+	status.AppliedNotes = parseSaptuneList(output)
+
+	// saptune note verify exits with an error when any of the enabled notes is not compliant
+	if len(status.Notes) > 0 {
+		err = customExecCommand(ctx, "saptune", "note", "verify").Run()
+		status.Compliant = err == nil
+	}
+}
+
+func discoverSapconf(ctx context.Context, status *Status) {
+	output, _ := customExecCommand(ctx, "systemctl", "is-active", "sapconf.service").Output()
+	status.SapconfActive = strings.TrimSpace(string(output)) == "active"
+
+	// sapconf 4 relies on a tuned profile, sapconf 5 doesn't, so the profile may not be found
+	// Output: Current active profile: sap-hana
+	output, err := customExecCommand(ctx, "tuned-adm", "active").Output()
+	if err != nil {
+		log.Debugf("No tuned profile found: %s", err)
+		return
+	}
+	if match := regexp.MustCompile(`profile:\s*(\S+)`).FindSubmatch(output); match != nil {
+		status.SapconfProfile = string(match[1])
+	}
+}
+
+// parseSaptuneList returns the items printed by saptune one per line, e.g. the enabled notes
+func parseSaptuneList(output []byte) []string {
+	items := []string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		for _, item := range strings.Fields(scanner.Text()) {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
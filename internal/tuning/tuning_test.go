@@ -0,0 +1,124 @@
+package tuning
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/tuning/mocks"
+)
+
+type TuningTestSuite struct {
+	suite.Suite
+	mockCommand *mocks.CustomCommand
+}
+
+func TestTuningTestSuite(t *testing.T) {
+	suite.Run(t, new(TuningTestSuite))
+}
+
+func (suite *TuningTestSuite) SetupTest() {
+	suite.mockCommand = new(mocks.CustomCommand)
+	customExecCommand = suite.mockCommand.Execute
+}
+
+func (suite *TuningTestSuite) TearDownTest() {
+	customExecCommand = exec.CommandContext
+}
+
+func mockOutput(output string) *exec.Cmd {
+	return exec.Command("echo", output)
+}
+
+func mockError() *exec.Cmd {
+	return exec.Command("false")
+}
+
+func (suite *TuningTestSuite) mockSaptune(verify *exec.Cmd) {
+	suite.mockCommand.On("Execute", mock.Anything, "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		mockOutput("3.1.0"))
+	suite.mockCommand.On("Execute", mock.Anything, "saptune", "version").Return(
+		mockOutput("current active saptune version is '3'"))
+	suite.mockCommand.On("Execute", mock.Anything, "saptune", "solution", "enabled").Return(
+		mockOutput("HANA"))
+	suite.mockCommand.On("Execute", mock.Anything, "saptune", "note", "enabled").Return(
+		mockOutput("941735\n1771258\n1980196"))
+	suite.mockCommand.On("Execute", mock.Anything, "saptune", "note", "applied").Return(
+		mockOutput("941735\n1771258"))
+	suite.mockCommand.On("Execute", mock.Anything, "saptune", "note", "verify").Return(verify)
+	suite.mockCommand.On("Execute", mock.Anything, "rpm", "-q", "--qf", "%{VERSION}", "sapconf").Return(
+		mockError())
+}
+
+func (suite *TuningTestSuite) TestNewStatusSaptune() {
+	suite.mockSaptune(mockOutput("all notes compliant"))
+
+	status, err := NewStatus(context.Background())
+
+	suite.NoError(err)
+	suite.Equal(&Status{
+		Tool:                     ToolSaptune,
+		SaptuneVersion:           "3.1.0",
+		SaptuneConfiguredVersion: "3",
+		Solution:                 "HANA",
+		Notes:                    []string{"941735", "1771258", "1980196"},
+		AppliedNotes:             []string{"941735", "1771258"},
+		Compliant:                true,
+	}, status)
+}
+
+func (suite *TuningTestSuite) TestNewStatusSaptuneNotCompliant() {
+	suite.mockSaptune(mockError())
+
+	status, err := NewStatus(context.Background())
+
+	suite.NoError(err)
+	suite.Equal(ToolSaptune, status.Tool)
+	suite.False(status.Compliant)
+}
+
+func (suite *TuningTestSuite) TestNewStatusSapconf() {
+	suite.mockCommand.On("Execute", mock.Anything, "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		mockError())
+	suite.mockCommand.On("Execute", mock.Anything, "rpm", "-q", "--qf", "%{VERSION}", "sapconf").Return(
+		mockOutput("4.2.2"))
+	suite.mockCommand.On("Execute", mock.Anything, "systemctl", "is-active", "sapconf.service").Return(
+		mockOutput("active"))
+	suite.mockCommand.On("Execute", mock.Anything, "tuned-adm", "active").Return(
+		mockOutput("Current active profile: sap-netweaver"))
+
+	status, err := NewStatus(context.Background())
+
+	suite.NoError(err)
+	suite.Equal(&Status{
+		Tool:           ToolSapconf,
+		SapconfVersion: "4.2.2",
+		SapconfActive:  true,
+		SapconfProfile: "sap-netweaver",
+	}, status)
+}
+
+func (suite *TuningTestSuite) TestNewStatusNotTuned() {
+	suite.mockCommand.On("Execute", mock.Anything, "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		mockOutput("3.1.0"))
+	suite.mockCommand.On("Execute", mock.Anything, "saptune", "version").Return(
+		mockOutput("current active saptune version is '3'"))
+	suite.mockCommand.On("Execute", mock.Anything, "saptune", "solution", "enabled").Return(
+		mockOutput(""))
+	suite.mockCommand.On("Execute", mock.Anything, "saptune", "note", "enabled").Return(
+		mockOutput(""))
+	suite.mockCommand.On("Execute", mock.Anything, "saptune", "note", "applied").Return(
+		mockOutput(""))
+	suite.mockCommand.On("Execute", mock.Anything, "rpm", "-q", "--qf", "%{VERSION}", "sapconf").Return(
+		mockError())
+
+	status, err := NewStatus(context.Background())
+
+	suite.NoError(err)
+	suite.Equal("", status.Tool)
+	suite.Empty(status.Notes)
+	suite.False(status.Compliant)
+	suite.mockCommand.AssertNotCalled(suite.T(), "Execute", mock.Anything, "saptune", "note", "verify")
+}
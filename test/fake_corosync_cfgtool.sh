@@ -0,0 +1,15 @@
+#!/bin/bash
+
+# /usr/sbin/corosync-cfgtool -s
+cat <<RESULT
+Printing ring status.
+Local node ID 1
+RING ID 0
+	id	= 10.80.1.11
+	status	= ring 0 active with no faults
+RING ID 1
+	id	= 10.80.2.11
+	status	= Marking ringid 1 interface 10.80.2.11 FAULTY
+RESULT
+
+exit 1
@@ -0,0 +1,214 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// Discoveries the agents can be asked to run right away
+var commandDiscoveryTypes = []string{
+	datapipeline.ClusterDiscovery,
+	datapipeline.SAPsystemDiscovery,
+	datapipeline.HostDiscovery,
+	datapipeline.SubscriptionDiscovery,
+	datapipeline.CloudDiscovery,
+	datapipeline.SystemdDiscovery,
+	datapipeline.TuningDiscovery,
+}
+
+type JSONRunDiscoveryRequest struct {
+	DiscoveryType string `json:"discovery_type" binding:"required"`
+}
+
+type JSONAgentCommand struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	DiscoveryType string `json:"discovery_type"`
+}
+
+type JSONAgentCommandCompletion struct {
+	// Error message of a failed command, empty when it succeeded
+	Error string `json:"error"`
+}
+
+// ApiHostRunDiscoveryHandler godoc
+// @Summary Ask the agent of a host to run a discovery right away
+// @Accept json
+// @Produce json
+// @Param id path string true "Host id"
+// @Param Body body JSONRunDiscoveryRequest true "The discovery to run"
+// @Success 202 {object} models.AgentCommand
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/commands [post]
+func ApiHostRunDiscoveryHandler(agentCommandsService services.AgentCommandsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		discoveryType, ok := bindRunDiscoveryRequest(c)
+		if !ok {
+			return
+		}
+
+		command, err := agentCommandsService.QueueHostDiscovery(c.Param("id"), discoveryType)
+		if errors.Is(err, services.ErrAgentCommandNoTargets) {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, command)
+	}
+}
+
+// ApiClusterRunDiscoveryHandler godoc
+// @Summary Ask the agents of all the cluster nodes to run a discovery right away
+// @Accept json
+// @Produce json
+// @Param id path string true "Cluster id"
+// @Param Body body JSONRunDiscoveryRequest true "The discovery to run"
+// @Success 202 {array} models.AgentCommand
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /clusters/{id}/commands [post]
+func ApiClusterRunDiscoveryHandler(agentCommandsService services.AgentCommandsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		discoveryType, ok := bindRunDiscoveryRequest(c)
+		if !ok {
+			return
+		}
+
+		commands, err := agentCommandsService.QueueClusterDiscovery(c.Param("id"), discoveryType)
+		if errors.Is(err, services.ErrAgentCommandNoTargets) {
+			_ = c.Error(NotFoundError("could not find cluster"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, commands)
+	}
+}
+
+// ApiHostCommandsHandler godoc
+// @Summary List the most recent commands queued for the agent of a host
+// @Produce json
+// @Param id path string true "Host id"
+// @Success 200 {array} models.AgentCommand
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/commands [get]
+func ApiHostCommandsHandler(agentCommandsService services.AgentCommandsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		commands, err := agentCommandsService.GetAllByAgentID(c.Param("id"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if commands == nil {
+			commands = []*models.AgentCommand{}
+		}
+
+		c.JSON(http.StatusOK, commands)
+	}
+}
+
+// ApiAgentCommandHandler godoc
+// @Summary Retrieve an agent command and its status
+// @Produce json
+// @Param id path string true "Command id"
+// @Success 200 {object} models.AgentCommand
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /commands/{id} [get]
+func ApiAgentCommandHandler(agentCommandsService services.AgentCommandsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		command, err := agentCommandsService.GetByID(c.Param("id"))
+		if errors.Is(err, services.ErrAgentCommandNotFound) {
+			_ = c.Error(NotFoundError("could not find the command"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, command)
+	}
+}
+
+// ApiPickUpAgentCommandsHandler delivers to the agent the commands queued for it, only once
+func ApiPickUpAgentCommandsHandler(agentCommandsService services.AgentCommandsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		commands, err := agentCommandsService.PickUpPending(c.Param("id"))
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		jsonCommands := []*JSONAgentCommand{}
+		for _, command := range commands {
+			jsonCommands = append(jsonCommands, &JSONAgentCommand{
+				ID:            command.ID,
+				Type:          command.Type,
+				DiscoveryType: command.DiscoveryType,
+			})
+		}
+
+		c.JSON(http.StatusOK, jsonCommands)
+	}
+}
+
+// ApiCompleteAgentCommandHandler records the outcome of a command the agent picked up
+func ApiCompleteAgentCommandHandler(agentCommandsService services.AgentCommandsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONAgentCommandCompletion
+
+		err := c.ShouldBindJSON(&r)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		err = agentCommandsService.Complete(c.Param("id"), c.Param("command_id"), r.Error)
+		if errors.Is(err, services.ErrAgentCommandNotFound) {
+			_ = c.AbortWithError(http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func bindRunDiscoveryRequest(c *gin.Context) (string, bool) {
+	var r JSONRunDiscoveryRequest
+
+	err := c.BindJSON(&r)
+	if err != nil {
+		_ = c.Error(BadRequestError("unable to parse JSON body"))
+		return "", false
+	}
+
+	for _, discoveryType := range commandDiscoveryTypes {
+		if r.DiscoveryType == discoveryType {
+			return discoveryType, true
+		}
+	}
+
+	_ = c.Error(BadRequestError("unknown discovery type"))
+	return "", false
+}
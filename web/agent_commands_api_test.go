@@ -0,0 +1,228 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func TestApiHostRunDiscoveryHandler(t *testing.T) {
+	createdAt := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	agentCommandsService := new(services.MockAgentCommandsService)
+	agentCommandsService.On("QueueHostDiscovery", "agent_id", "host_discovery").Return(&models.AgentCommand{
+		ID:            "command_id",
+		AgentID:       "agent_id",
+		Type:          models.AgentCommandRunDiscovery,
+		DiscoveryType: "host_discovery",
+		Status:        models.AgentCommandQueued,
+		CreatedAt:     createdAt,
+	}, nil)
+	agentCommandsService.On("QueueHostDiscovery", "unknown_agent_id", "host_discovery").Return(nil, services.ErrAgentCommandNoTargets)
+
+	deps := setupTestDependencies()
+	deps.agentCommandsService = agentCommandsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		url           string
+		discoveryType string
+		expectedCode  int
+	}{
+		{"/api/hosts/agent_id/commands", "host_discovery", 202},
+		{"/api/hosts/unknown_agent_id/commands", "host_discovery", 404},
+		{"/api/hosts/agent_id/commands", "unknown_discovery", 400},
+		{"/api/hosts/agent_id/commands", "", 400},
+	}
+
+	for _, c := range cases {
+		resp := httptest.NewRecorder()
+		body, _ := json.Marshal(&JSONRunDiscoveryRequest{DiscoveryType: c.discoveryType})
+		req := httptest.NewRequest("POST", c.url, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, c.expectedCode, resp.Code, c)
+		if c.expectedCode == 202 {
+			assert.JSONEq(t, `{
+				"id": "command_id",
+				"agent_id": "agent_id",
+				"type": "run_discovery",
+				"discovery_type": "host_discovery",
+				"status": "queued",
+				"created_at": "2022-04-01T10:00:00Z",
+				"picked_up_at": null,
+				"completed_at": null
+			}`, resp.Body.String())
+		}
+	}
+}
+
+func TestApiClusterRunDiscoveryHandler(t *testing.T) {
+	agentCommandsService := new(services.MockAgentCommandsService)
+	agentCommandsService.On("QueueClusterDiscovery", "cluster_id", "ha_cluster_discovery").Return([]*models.AgentCommand{
+		{ID: "command_1", AgentID: "agent_1", Status: models.AgentCommandQueued},
+		{ID: "command_2", AgentID: "agent_2", Status: models.AgentCommandQueued},
+	}, nil)
+	agentCommandsService.On("QueueClusterDiscovery", "unknown_cluster_id", "ha_cluster_discovery").Return(nil, services.ErrAgentCommandNoTargets)
+
+	deps := setupTestDependencies()
+	deps.agentCommandsService = agentCommandsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(&JSONRunDiscoveryRequest{DiscoveryType: "ha_cluster_discovery"})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/clusters/cluster_id/commands", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+
+	var commands []*models.AgentCommand
+	json.Unmarshal(resp.Body.Bytes(), &commands)
+	assert.Len(t, commands, 2)
+	assert.Equal(t, "command_1", commands[0].ID)
+	assert.Equal(t, "command_2", commands[1].ID)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/clusters/unknown_cluster_id/commands", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiAgentCommandHandler(t *testing.T) {
+	agentCommandsService := new(services.MockAgentCommandsService)
+	agentCommandsService.On("GetByID", "command_id").Return(&models.AgentCommand{
+		ID:     "command_id",
+		Status: models.AgentCommandFailed,
+		Error:  "discovery failed",
+	}, nil)
+	agentCommandsService.On("GetByID", "unknown_command_id").Return(nil, services.ErrAgentCommandNotFound)
+	agentCommandsService.On("GetAllByAgentID", "agent_id").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.agentCommandsService = agentCommandsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/commands/command_id", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+
+	var command models.AgentCommand
+	json.Unmarshal(resp.Body.Bytes(), &command)
+	assert.Equal(t, models.AgentCommandFailed, command.Status)
+	assert.Equal(t, "discovery failed", command.Error)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/commands/unknown_command_id", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/hosts/agent_id/commands", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, "[]", resp.Body.String())
+}
+
+func TestApiPickUpAgentCommandsHandler(t *testing.T) {
+	agentCommandsService := new(services.MockAgentCommandsService)
+	agentCommandsService.On("PickUpPending", "agent_id").Return([]*models.AgentCommand{
+		{
+			ID:            "command_id",
+			AgentID:       "agent_id",
+			Type:          models.AgentCommandRunDiscovery,
+			DiscoveryType: "host_discovery",
+			Status:        models.AgentCommandPickedUp,
+		},
+	}, nil)
+	agentCommandsService.On("PickUpPending", "other_agent_id").Return(nil, nil)
+
+	deps := setupTestDependencies()
+	deps.agentCommandsService = agentCommandsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/hosts/agent_id/commands/pickup", nil)
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `[{"id": "command_id", "type": "run_discovery", "discovery_type": "host_discovery"}]`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/hosts/other_agent_id/commands/pickup", nil)
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, "[]", resp.Body.String())
+}
+
+func TestApiCompleteAgentCommandHandler(t *testing.T) {
+	agentCommandsService := new(services.MockAgentCommandsService)
+	agentCommandsService.On("Complete", "agent_id", "command_id", "").Return(nil)
+	agentCommandsService.On("Complete", "agent_id", "failed_command_id", "discovery failed").Return(nil)
+	agentCommandsService.On("Complete", "agent_id", "unknown_command_id", "").Return(services.ErrAgentCommandNotFound)
+
+	deps := setupTestDependencies()
+	deps.agentCommandsService = agentCommandsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		commandID    string
+		error        string
+		expectedCode int
+	}{
+		{"command_id", "", 204},
+		{"failed_command_id", "discovery failed", 204},
+		{"unknown_command_id", "", 404},
+	}
+
+	for _, c := range cases {
+		resp := httptest.NewRecorder()
+		body, _ := json.Marshal(&JSONAgentCommandCompletion{Error: c.error})
+		req := httptest.NewRequest("POST", "/api/hosts/agent_id/commands/"+c.commandID+"/complete", bytes.NewBuffer(body))
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, c.expectedCode, resp.Code, c.commandID)
+	}
+
+	agentCommandsService.AssertExpectations(t)
+}
@@ -20,6 +20,10 @@ import (
 // @BasePath /api
 // @schemes http
 
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+
 func ApiPingHandler(c *gin.Context) {
 	c.String(http.StatusOK, "pong")
 }
@@ -45,9 +45,14 @@ var DBTables = []interface{}{
 	&entities.Check{}, &datapipeline.DataCollectedEvent{}, &datapipeline.Subscription{},
 	&entities.HostTelemetry{}, &entities.Cluster{}, &entities.Host{}, &entities.HostHeartbeat{},
 	&entities.SlesSubscription{}, &entities.SAPSystemInstance{}, &entities.ChecksResult{},
-	&entities.HealthState{},
+	&entities.HealthState{}, &entities.SystemdUnit{}, &entities.HostTuning{},
+	&entities.EnrollmentToken{}, &entities.AgentCredential{}, &entities.AgentCommand{},
+	&entities.HostIdentity{},
 }
 
+// Maximum size of the decompressed requests accepted by the collector
+const maxCollectorRequestSize int64 = 64 * 1024 * 1024
+
 type App struct {
 	InstallationID uuid.UUID
 	config         *Config
@@ -59,9 +64,15 @@ type Config struct {
 	Port          int
 	CollectorPort int
 	EnablemTLS    bool
-	Cert          string
-	Key           string
-	CA            string
+	// Serve the collector over TLS without requiring a client certificate
+	CollectorTLS bool
+	Cert         string
+	Key          string
+	CA           string
+	// Require the agents to authenticate to the collector with the credential obtained by enrolling
+	EnableAgentAuth bool
+	// Bearer token required to manage the enrollment, the enrollment API is disabled without it
+	AdminToken    string
 	DBConfig      *trentoDB.Config
 	GrafanaConfig *grafana.Config
 	PrometheusURL string
@@ -85,6 +96,8 @@ type Dependencies struct {
 	telemetryPublisher      telemetry.Publisher
 	premiumDetectionService services.PremiumDetectionService
 	prometheusService       services.PrometheusService
+	enrollmentService       services.EnrollmentService
+	agentCommandsService    services.AgentCommandsService
 }
 
 func DefaultDependencies(ctx context.Context, config *Config) Dependencies {
@@ -129,12 +142,15 @@ func DefaultDependencies(ctx context.Context, config *Config) Dependencies {
 	telemetryRegistry := telemetry.NewTelemetryRegistry(db)
 	telemetryPublisher := telemetry.NewTelemetryPublisher()
 	healthSummaryService := services.NewHealthSummaryService(sapSystemsService, clustersService, hostsService)
+	enrollmentService := services.NewEnrollmentService(db)
+	agentCommandsService := services.NewAgentCommandsService(db)
 
 	return Dependencies{
 		webEngine, collectorEngine, store, projectorWorkersPool,
 		checksService, subscriptionsService, tagsService,
 		collectorService, sapSystemsService, clustersService, hostsService, settingsService, healthSummaryService,
-		telemetryRegistry, telemetryPublisher, premiumDetection, prometheusService,
+		telemetryRegistry, telemetryPublisher, premiumDetection, prometheusService, enrollmentService,
+		agentCommandsService,
 	}
 }
 
@@ -200,11 +216,18 @@ func NewAppWithDeps(config *Config, deps Dependencies) (*App, error) {
 		apiGroup.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 		apiGroup.GET("/ping", ApiPingHandler)
 		apiGroup.GET("/tags", ApiListTag(deps.tagsService))
+		apiGroup.GET("/hosts/identity_conflicts", ApiHostsIdentityConflictsHandler(deps.hostsService))
 		apiGroup.POST("/hosts/:id/tags", ApiHostCreateTagHandler(deps.hostsService, deps.tagsService))
 		apiGroup.DELETE("/hosts/:id/tags/:tag", ApiHostDeleteTagHandler(deps.hostsService, deps.tagsService))
+		apiGroup.GET("/hosts/:id/systemd_units", ApiHostSystemdUnitsHandler(deps.hostsService))
+		apiGroup.GET("/hosts/:id/tuning", ApiHostTuningHandler(deps.hostsService))
+		apiGroup.POST("/hosts/:id/commands", ApiHostRunDiscoveryHandler(deps.agentCommandsService))
+		apiGroup.GET("/hosts/:id/commands", ApiHostCommandsHandler(deps.agentCommandsService))
 		apiGroup.POST("/clusters/:id/tags", ApiClusterCreateTagHandler(deps.clustersService, deps.tagsService))
 		apiGroup.DELETE("/clusters/:id/tags/:tag", ApiClusterDeleteTagHandler(deps.clustersService, deps.tagsService))
+		apiGroup.POST("/clusters/:id/commands", ApiClusterRunDiscoveryHandler(deps.agentCommandsService))
 		apiGroup.GET("/clusters/:cluster_id/results", ApiClusterCheckResultsHandler(deps.checksService))
+		apiGroup.GET("/clusters/:cluster_id/health", ApiClusterHealthHandler(deps.clustersService))
 		apiGroup.GET("/clusters/settings", ApiGetClustersSettingsHandler(deps.clustersService))
 		apiGroup.POST("/sapsystems/:id/tags", ApiSAPSystemCreateTagHandler(deps.sapSystemsService, deps.tagsService))
 		apiGroup.DELETE("/sapsystems/:id/tags/:tag", ApiSAPSystemDeleteTagHandler(deps.sapSystemsService, deps.tagsService))
@@ -217,12 +240,34 @@ func NewAppWithDeps(config *Config, deps Dependencies) (*App, error) {
 		apiGroup.GET("/checks/catalog", ApiChecksCatalogHandler(deps.checksService))
 		apiGroup.POST("/checks/:id/results", ApiCreateChecksResultHandler(deps.checksService))
 		apiGroup.GET("/prometheus/targets", ApiGetPrometheusHttpSdTargets(deps.prometheusService))
+		apiGroup.GET("/commands/:id", ApiAgentCommandHandler(deps.agentCommandsService))
+	}
+
+	enrollmentGroup := apiGroup.Group("/enrollment", AdminAuthMiddleware(config.AdminToken))
+	{
+		enrollmentGroup.POST("/tokens", ApiCreateEnrollmentTokenHandler(deps.enrollmentService))
+		enrollmentGroup.GET("/tokens", ApiListEnrollmentTokensHandler(deps.enrollmentService))
+		enrollmentGroup.DELETE("/tokens/:id", ApiDeleteEnrollmentTokenHandler(deps.enrollmentService))
+		enrollmentGroup.GET("/agents", ApiListEnrolledAgentsHandler(deps.enrollmentService))
+		enrollmentGroup.DELETE("/agents/:id", ApiRevokeAgentHandler(deps.enrollmentService))
+		enrollmentGroup.POST("/agents/:id/reset", ApiResetAgentHandler(deps.enrollmentService))
 	}
 
 	collectorEngine := deps.collectorEngine
-	collectorEngine.POST("/api/collect", ApiCollectDataHandler(deps.collectorService))
-	collectorEngine.POST("/api/hosts/:id/heartbeat", ApiHostHeartbeatHandler(deps.hostsService))
+	collectorEngine.Use(DecompressRequestMiddleware(maxCollectorRequestSize))
 	collectorEngine.GET("/api/ping", ApiPingHandler)
+	collectorEngine.POST("/api/enroll", ApiEnrollAgentHandler(deps.enrollmentService))
+
+	agentGroup := collectorEngine.Group("/api")
+	if config.EnableAgentAuth {
+		agentGroup.Use(AgentAuthMiddleware(deps.enrollmentService))
+	}
+	{
+		agentGroup.POST("/collect", ApiCollectDataHandler(deps.collectorService))
+		agentGroup.POST("/hosts/:id/heartbeat", ApiHostHeartbeatHandler(deps.hostsService))
+		agentGroup.POST("/hosts/:id/commands/pickup", ApiPickUpAgentCommandsHandler(deps.agentCommandsService))
+		agentGroup.POST("/hosts/:id/commands/:command_id/complete", ApiCompleteAgentCommandHandler(deps.agentCommandsService))
+	}
 
 	return app, nil
 }
@@ -239,11 +284,17 @@ func (a *App) Start(ctx context.Context) error {
 	var tlsConfig *tls.Config
 	var err error
 
-	if a.config.EnablemTLS {
+	switch {
+	case a.config.EnablemTLS:
 		tlsConfig, err = getTLSConfig(a.config.Cert, a.config.Key, a.config.CA)
 		if err != nil {
 			return err
 		}
+	case a.config.CollectorTLS:
+		tlsConfig, err = getServerTLSConfig(a.config.Cert, a.config.Key)
+		if err != nil {
+			return err
+		}
 	}
 
 	collectorServer := &http.Server{
@@ -327,3 +378,15 @@ func getTLSConfig(cert string, key string, ca string) (*tls.Config, error) {
 		Certificates: []tls.Certificate{certificate},
 	}, nil
 }
+
+// getServerTLSConfig serves the certificate without asking the agents for theirs
+func getServerTLSConfig(cert string, key string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+	}, nil
+}
@@ -131,7 +131,12 @@ func NewClusterHandler(clusterService services.ClustersService) gin.HandlerFunc
 			Layout:        "vertical",
 		}
 
-		c.HTML(http.StatusOK, "cluster_hana.html.tmpl", gin.H{
+		template := "cluster_hana.html.tmpl"
+		if cluster.ClusterType == models.ClusterTypeASCSERS {
+			template = "cluster_ascs_ers.html.tmpl"
+		}
+
+		c.HTML(http.StatusOK, template, gin.H{
 			"Cluster":         cluster,
 			"HealthContainer": hContainer,
 			"Alerts":          GetAlerts(c),
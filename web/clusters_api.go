@@ -29,3 +29,31 @@ func ApiGetClustersSettingsHandler(clusters services.ClustersService) gin.Handle
 		c.JSON(http.StatusOK, clustersSettings)
 	}
 }
+
+// ApiClusterHealthHandler godoc
+// @Summary Retrieve the health of a cluster, with its partial healths and the reasons they are not passing
+// @Accept json
+// @Produce json
+// @Param cluster_id path string true "Cluster id"
+// @Success 200 {object} models.HealthState
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /clusters/{cluster_id}/health [get]
+func ApiClusterHealthHandler(clusters services.ClustersService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterID := c.Param("cluster_id")
+
+		health, err := clusters.GetHealthByID(clusterID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if health == nil {
+			_ = c.Error(NotFoundError("could not find the health of the cluster"))
+			return
+		}
+
+		c.JSON(http.StatusOK, health)
+	}
+}
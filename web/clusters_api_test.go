@@ -83,6 +83,54 @@ func (suite *ClustersApiTestCase) Test_AnErrorOccurs() {
 	suite.JSONEq(`{"error":"KABOOM"}`, resp.Body.String())
 }
 
+func (suite *ClustersApiTestCase) Test_ClusterHealth() {
+	suite.mockClusterService.On("GetHealthByID", "cluster1").Return(&models.HealthState{
+		Health: models.HealthSummaryHealthCritical,
+		PartialHealths: map[string]*models.PartialHealth{
+			"hana_sr_health": {Health: models.HealthSummaryHealthPassing},
+			"sbd_health": {
+				Health: models.HealthSummaryHealthCritical,
+				Reason: "SBD device /dev/sdb is unhealthy",
+			},
+		},
+	}, nil)
+	suite.deps.clustersService = suite.mockClusterService
+
+	app, err := NewAppWithDeps(suite.config, suite.deps)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/clusters/cluster1/health", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	suite.Equal(200, resp.Code)
+	suite.JSONEq(`{
+		"health": "critical",
+		"partial_healths": {
+			"hana_sr_health": {"health": "passing"},
+			"sbd_health": {"health": "critical", "reason": "SBD device /dev/sdb is unhealthy"}
+		}
+	}`, resp.Body.String())
+}
+
+func (suite *ClustersApiTestCase) Test_ClusterHealthNotFound() {
+	suite.mockClusterService.On("GetHealthByID", "cluster1").Return(nil, nil)
+	suite.deps.clustersService = suite.mockClusterService
+
+	app, err := NewAppWithDeps(suite.config, suite.deps)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/clusters/cluster1/health", nil)
+	app.webEngine.ServeHTTP(resp, req)
+
+	suite.Equal(404, resp.Code)
+}
+
 func mockedClustersSettings() models.ClustersSettings {
 	return models.ClustersSettings{
 		{
@@ -281,7 +281,7 @@ func TestClustersListHandler(t *testing.T) {
 			ID:                "47d1190ffb4f781974c8356d7f863b03",
 			Name:              "hana_cluster",
 			ClusterType:       models.ClusterTypeHANAScaleUp,
-			SID:               "PRD",
+			SIDs:              []string{"PRD"},
 			ResourcesNumber:   5,
 			HostsNumber:       3,
 			Tags:              []string{"tag1"},
@@ -292,7 +292,6 @@ func TestClustersListHandler(t *testing.T) {
 			ID:                "a615a35f65627be5a757319a0741127f",
 			Name:              "other_cluster",
 			ClusterType:       models.ClusterTypeUnknown,
-			SID:               "",
 			Tags:              []string{"tag1"},
 			Health:            models.CheckCritical,
 			HasDuplicatedName: false,
@@ -301,7 +300,6 @@ func TestClustersListHandler(t *testing.T) {
 			ID:                "e2f2eb50aef748e586a7baa85e0162cf",
 			Name:              "netweaver_cluster",
 			ClusterType:       models.ClusterTypeUnknown,
-			SID:               "",
 			ResourcesNumber:   10,
 			HostsNumber:       2,
 			Tags:              []string{"tag1"},
@@ -312,7 +310,6 @@ func TestClustersListHandler(t *testing.T) {
 			ID:                "e27d313a674375b2066777a89ee346b9",
 			Name:              "netweaver_cluster",
 			ClusterType:       models.ClusterTypeUnknown,
-			SID:               "",
 			Tags:              []string{"tag1"},
 			Health:            models.CheckUndefined,
 			HasDuplicatedName: true,
@@ -372,7 +369,7 @@ func TestClusterHandlerHANA(t *testing.T) {
 		ID:            clusterID,
 		Name:          "hana_cluster",
 		ClusterType:   models.ClusterTypeHANAScaleUp,
-		SID:           "PRD",
+		SIDs:          []string{"PRD", "QAS"},
 		Tags:          []string{"tag1"},
 		Health:        models.CheckCritical,
 		PassingCount:  2,
@@ -383,7 +380,43 @@ func TestClusterHandlerHANA(t *testing.T) {
 			SecondarySyncState:             "SFAIL",
 			SRHealthState:                  "1",
 			FencingType:                    "external/sbd",
-			CIBLastWritten:                 time.Date(2021, time.June, 30, 18, 11, 37, 0, time.UTC),
+			FencingResources: []*models.FencingResource{
+				{
+					ID:                 "rsc_st_azure",
+					Agent:              "fence_azure_arm",
+					Status:             "stopped",
+					Disabled:           true,
+					InstanceAttributes: map[string]string{"password": "********", "pcmk_delay_max": "15"},
+					MetaAttributes:     map[string]string{"target-role": "Stopped"},
+				},
+			},
+			CIBLastWritten: time.Date(2021, time.June, 30, 18, 11, 37, 0, time.UTC),
+			SystemReplications: []*models.HANASystemReplication{
+				{
+					SID:                "PRD",
+					Mode:               "sync",
+					OperationMode:      "logreplay",
+					SecondarySyncState: "SFAIL",
+					SRHealthState:      "1",
+				},
+				{
+					SID:                "QAS",
+					Mode:               "async",
+					OperationMode:      "delta_datashipping",
+					SecondarySyncState: "SOK",
+					SRHealthState:      "4",
+				},
+			},
+			LocationConstraints: []*models.LocationConstraint{
+				{
+					ID:       "cli-prefer-msl_SAPHana_PRD_HDB00",
+					Type:     models.LocationConstraintTypeMove,
+					Resource: "msl_SAPHana_PRD_HDB00",
+					Node:     "test_node_2",
+					Role:     "Started",
+					Score:    "INFINITY",
+				},
+			},
 			StoppedResources: []*models.ClusterResource{
 				{
 					ID:        "dummy_failed",
@@ -401,6 +434,7 @@ func TestClusterHandlerHANA(t *testing.T) {
 					VirtualIPs:  []string{"10.123.123.123"},
 					HANAStatus:  "Primary",
 					Health:      models.HostHealthPassing,
+					Online:      true,
 					Resources: []*models.ClusterResource{
 						{
 							ID:        "dummy_failed",
@@ -410,11 +444,12 @@ func TestClusterHandlerHANA(t *testing.T) {
 							FailCount: 0,
 						},
 						{
-							ID:        "sbd",
-							Type:      "stonith:external/sbd",
-							Role:      "Started",
-							Status:    "active",
-							FailCount: 0,
+							ID:                 "sbd",
+							Type:               "stonith:external/sbd",
+							Role:               "Started",
+							Status:             "active",
+							FailCount:          0,
+							MigrationThreshold: 5000,
 						},
 					},
 				},
@@ -424,6 +459,23 @@ func TestClusterHandlerHANA(t *testing.T) {
 					IPAddresses: []string{"192.168.1.2"},
 					HANAStatus:  "Failed",
 					Health:      models.HostHealthCritical,
+					Online:      true,
+					Standby:     true,
+				},
+			},
+			Corosync: &models.CorosyncDetails{
+				Transport:     "udpu",
+				RRPMode:       "passive",
+				Token:         5000,
+				Consensus:     6000,
+				ExpectedVotes: 2,
+				TwoNode:       true,
+				Nodes: []*models.CorosyncNode{
+					{ID: 1, Name: "test_node_1", Addresses: []string{"192.168.1.1", "10.0.0.1"}},
+				},
+				Rings: []*models.CorosyncRing{
+					{ID: 0, Address: "192.168.1.1", Status: "ring 0 active with no faults"},
+					{ID: 1, Address: "10.0.0.1", Status: "Marking ringid 1 interface 10.0.0.1 FAULTY", Faulty: true},
 				},
 			},
 		},
@@ -458,24 +510,143 @@ func TestClusterHandlerHANA(t *testing.T) {
 	assert.Equal(t, 200, resp.Code)
 	assert.Contains(t, resp.Body.String(), "Cluster details")
 	// Summary
-	assert.Regexp(t, regexp.MustCompile("<strong>SID:</strong><br><span.*>PRD</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>SID:</strong><br><span.*>PRD, QAS</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>Cluster name:</strong><br><span.*>hana_cluster</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<span id=run-discovery-button data-resource=clusters data-id=47d1190ffb4f781974c8356d7f863b03></span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>Cluster type:</strong><br><span.*>HANA scale-up</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>HANA system replication mode:</strong><br><span.*>sync</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>Fencing type:</strong><br><span.*>external/sbd</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>HANA system replication operation mode:</strong><br><span.*>logreplay</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>CIB last written:</strong><br><span.*>Jun 30, 2021 18:11:37 UTC</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>SAPHanaSR health state:</strong>.*text-danger.*"), minified)
+	// Leftover location constraints
+	assert.Regexp(t, regexp.MustCompile("<td>cli-prefer-msl_SAPHana_PRD_HDB00</td><td>move</td><td>msl_SAPHana_PRD_HDB00</td><td>test_node_2</td><td>Started</td><td>INFINITY</td>"), minified)
+	// Fencing
+	assert.Regexp(t, regexp.MustCompile("<td.*error</i></td><td.*>rsc_st_azure</td><td.*>fence_azure_arm</td><td.*><span .*badge-danger.*>disabled</span></td><td><span .*>password=\\*{8}</span>\\s*<span .*>pcmk_delay_max=15</span>\\s*<span .*>target-role=Stopped</span></td>"), minified)
+	// HANA system replication
+	assert.Regexp(t, regexp.MustCompile("<td>PRD</td><td>sync</td><td>logreplay</td><td>1</td><td>SFAIL</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td>QAS</td><td>async</td><td>delta_datashipping</td><td>4</td><td>SOK</td>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<strong>HANA secondary sync state:</strong><br><span.*>SFAIL</span>"), minified)
 	// Health
 	assert.Regexp(t, regexp.MustCompile(".*check_circle.*alert-body.*Passing.*2"), minified)
 	assert.Regexp(t, regexp.MustCompile(".*error.*alert-body.*Critical.*1"), minified)
 
 	// Nodes
-	assert.Regexp(t, regexp.MustCompile("<td.*check_circle.*<td.*><a.*href=/hosts/host1.*>test_node_1</a></td><td.*>192\\.168\\.1\\.1</td><td.*>10\\.123\\.123\\.123</td><td.*><span .*>HANA Primary</span>"), minified)
-	assert.Regexp(t, regexp.MustCompile("<td.*error.*<td.*><a.*href=/hosts/host2.*>test_node_2</a></td><td.*>192\\.168\\.1\\.2</td>.*<span .*danger.*>HANA Failed</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td.*check_circle.*<td.*><a.*href=/hosts/host1.*>test_node_1</a></td><td.*>192\\.168\\.1\\.1</td><td.*>10\\.123\\.123\\.123</td><td.*><span .*badge-primary.*>online</span></td><td.*><span .*>HANA Primary</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td.*error.*<td.*><a.*href=/hosts/host2.*>test_node_2</a></td><td.*>192\\.168\\.1\\.2</td>.*<span .*badge-warning.*>standby</span>.*<span .*danger.*>HANA Failed</span>"), minified)
 	// Resources
-	assert.Regexp(t, regexp.MustCompile("<td>sbd</td><td>stonith:external/sbd</td><td>Started</td><td>active</td><td>0</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td>sbd</td><td>stonith:external/sbd</td><td>Started</td><td>active</td><td>0</td><td>5000</td>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<td>dummy_failed</td><td>dummy</td><td>Started</td><td>failed</td><td>0</td>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<h4>Stopped resources</h4><div.*><div.*><span .*>dummy_failed</span>"), minified)
+	// Corosync
+	assert.Contains(t, minified, "One or more corosync rings are faulty")
+	assert.Regexp(t, regexp.MustCompile("<strong>Transport:</strong><br><span.*>udpu \\(passive\\)</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td>1</td><td>test_node_1</td><td>192\\.168\\.1\\.1, 10\\.0\\.0\\.1</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td.*error.*<td>1</td><td>10\\.0\\.0\\.1</td><td>Marking ringid 1 interface 10\\.0\\.0\\.1 FAULTY</td>"), minified)
+}
+
+func TestClusterHandlerASCSERS(t *testing.T) {
+	clusterID := "e27d313a674375b2066777a89ee346b9"
+
+	clustersService := new(services.MockClustersService)
+	clustersService.On("GetByID", clusterID).Return(&models.Cluster{
+		ID:          clusterID,
+		Name:        "netweaver_cluster",
+		ClusterType: models.ClusterTypeASCSERS,
+		SIDs:        []string{"NWP"},
+		Health:      models.CheckPassing,
+		Details: &models.ASCSERSClusterDetails{
+			SID:            "NWP",
+			EnsaVersion:    models.EnsaVersion2,
+			FencingType:    "external/sbd",
+			CIBLastWritten: time.Date(2022, time.March, 9, 10, 21, 45, 0, time.UTC),
+			Instances: []*models.ASCSERSInstance{
+				{
+					Role:            models.ASCSERSRoleASCS,
+					InstanceName:    "NWP_ASCS00_sapnwpas",
+					InstanceNumber:  "00",
+					VirtualHostname: "sapnwpas",
+					ResourceID:      "rsc_sap_NWP_ASCS00",
+					Node:            "vmnetweaver01",
+					HostID:          "host1",
+					Status:          "active",
+					VirtualIPs:      []string{"10.80.1.25"},
+					Filesystems: []*models.ClusterFilesystem{
+						{
+							ResourceID: "rsc_fs_NWP_ASCS00",
+							Device:     "10.80.1.30:/NWP/ASCS",
+							Directory:  "/usr/sap/NWP/ASCS00",
+							FSType:     "nfs4",
+						},
+					},
+					HAConnector: &models.SAPHAConnector{
+						Active:              true,
+						SAPInterfaceVersion: "sap_suse_cluster_connector 3.1.2",
+						ChecksState:         "SAPControl-HA-SUCCESS",
+					},
+				},
+				{
+					Role:            models.ASCSERSRoleERS,
+					InstanceName:    "NWP_ERS10_sapnwper",
+					InstanceNumber:  "10",
+					VirtualHostname: "sapnwper",
+					ResourceID:      "rsc_sap_NWP_ERS10",
+					Status:          "failed",
+				},
+			},
+			Nodes: []*models.HANAClusterNode{
+				{
+					HostID:      "host1",
+					Name:        "vmnetweaver01",
+					IPAddresses: []string{"192.168.1.1"},
+					VirtualIPs:  []string{"10.80.1.25"},
+					Health:      models.HostHealthPassing,
+					Online:      true,
+					Maintenance: true,
+				},
+			},
+		},
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.clustersService = clustersService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/clusters/"+clusterID, nil)
+	req.Header.Set("Accept", "text/html")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	clustersService.AssertExpectations(t)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 200, resp.Code)
+	// Summary
+	assert.Regexp(t, regexp.MustCompile("<strong>Cluster type:</strong><br><span.*>ASCS/ERS</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Enqueue server:</strong><br><span.*>ENSA2</span>"), minified)
+	assert.NotContains(t, minified, "Leftover location constraints")
+	assert.Regexp(t, regexp.MustCompile("<strong>SID:</strong><br><span.*>NWP</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>CIB last written:</strong><br><span.*>Mar 09, 2022 10:21:45 UTC</span>"), minified)
+	// Instances
+	assert.Regexp(t, regexp.MustCompile("<span class=eos-table-card-title>ASCS 00</span><span .*>NWP_ASCS00_sapnwpas</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Node:</strong><br><a href=/hosts/host1>vmnetweaver01</a>"), minified)
+	assert.Regexp(t, regexp.MustCompile("(?s)<strong>SAP HA interface:</strong><br><i .*text-success.*</i>\\s*<span .*>sap_suse_cluster_connector 3\\.1\\.2</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<td>rsc_fs_NWP_ASCS00</td><td>10\\.80\\.1\\.30:/NWP/ASCS</td><td>/usr/sap/NWP/ASCS00</td><td>nfs4</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("(?s)<span class=eos-table-card-title>ERS 10</span>.*badge-danger.*>failed</span>.*<strong>SAP HA interface:</strong><br><span .*>Not available</span>"), minified)
+	// Nodes
+	assert.Regexp(t, regexp.MustCompile("<td.*check_circle.*<td.*><a.*href=/hosts/host1.*>vmnetweaver01</a></td><td.*>192\\.168\\.1\\.1</td><td.*>10\\.80\\.1\\.25</td><td.*><span .*badge-warning.*>maintenance</span></td>"), minified)
 }
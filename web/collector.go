@@ -1,21 +1,67 @@
 package web
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/trento-project/trento/web/datapipeline"
 	"github.com/trento-project/trento/web/services"
 )
 
+const (
+	// maxReportedAge is the age of the oldest data the agents can replay, older data is stale anyway
+	maxReportedAge = 7 * 24 * time.Hour
+	// maxClockSkew is how far in the future the agent clocks can be, the times within it count as now
+	maxClockSkew = 5 * time.Minute
+)
+
+// errInvalidReportedAt is returned for the times reported by an agent whose clock is wrong
+var errInvalidReportedAt = errors.New("the reported time is too far in the past or in the future")
+
+// reportedAt returns the time the agent reported the data at, or now when the agent didn't send it.
+// The time is never in the future, otherwise an agent with a skewed clock would look alive after it stopped
+func reportedAt(timestamp time.Time) (time.Time, error) {
+	now := time.Now()
+
+	switch {
+	case timestamp.IsZero():
+		return now, nil
+	case timestamp.Before(now.Add(-maxReportedAge)), timestamp.After(now.Add(maxClockSkew)):
+		return time.Time{}, errInvalidReportedAt
+	case timestamp.After(now):
+		return now, nil
+	}
+
+	return timestamp, nil
+}
+
 // ApiCollectDataHandler handles the request to collect agent data from the API
 func ApiCollectDataHandler(collectorService services.CollectorService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var e datapipeline.DataCollectedEvent
 
-		err := c.BindJSON(&e)
+		err := c.ShouldBindJSON(&e)
+		if errors.Is(err, errRequestBodyTooLarge) {
+			_ = c.AbortWithError(http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		// Authenticated agents publish only their own data
+		if agentID, ok := c.Get(authenticatedAgentKey); ok && agentID != e.AgentID {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		// Agents send the discovery time only when replaying spooled data
+		e.DiscoveredAt, err = reportedAt(e.DiscoveredAt)
 		if err != nil {
-			_ = c.Error(err)
+			_ = c.AbortWithError(http.StatusBadRequest, err)
 			return
 		}
 
@@ -2,9 +2,13 @@ package web
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -14,7 +18,9 @@ import (
 
 func TestApiCollectDataHandler(t *testing.T) {
 	collectorService := new(services.MockCollectorService)
-	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return !e.DiscoveredAt.IsZero()
+	})).Return(nil)
 
 	deps := setupTestDependencies()
 	deps.collectorService = collectorService
@@ -37,3 +43,157 @@ func TestApiCollectDataHandler(t *testing.T) {
 
 	assert.Equal(t, 202, resp.Code)
 }
+
+func TestApiCollectDataHandlerReplayed(t *testing.T) {
+	discoveredAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return e.DiscoveredAt.Equal(discoveredAt)
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "agent_id",
+		DiscoveryType: "discovery",
+		DiscoveredAt:  discoveredAt,
+		Payload:       []byte("{}"),
+	})
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	collectorService.AssertExpectations(t)
+}
+
+func TestApiCollectDataHandlerSkewedClock(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return !e.DiscoveredAt.After(time.Now())
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		discoveredAt time.Time
+		expectedCode int
+	}{
+		{time.Now().Add(time.Minute), 202},
+		{time.Now().Add(time.Hour), 400},
+		{time.Now().Add(-30 * 24 * time.Hour), 400},
+	}
+
+	for _, c := range cases {
+		resp := httptest.NewRecorder()
+		body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+			AgentID:       "agent_id",
+			DiscoveryType: "discovery",
+			DiscoveredAt:  c.discoveredAt,
+			Payload:       []byte("{}"),
+		})
+		req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, c.expectedCode, resp.Code, c.discoveredAt)
+	}
+
+	collectorService.AssertNumberOfCalls(t, "StoreEvent", 1)
+}
+
+func TestApiCollectDataHandlerCompressed(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.MatchedBy(func(e *datapipeline.DataCollectedEvent) bool {
+		return e.AgentID == "agent_id" && string(e.Payload) == `{"key":"value"}`
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	writer := gzip.NewWriter(&body)
+	writer.Write([]byte(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {"key":"value"}}`))
+	writer.Close()
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", &body)
+	req.Header.Set("Content-Encoding", "gzip")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 202, resp.Code)
+	assert.Equal(t, "gzip", resp.Header().Get("Accept-Encoding"))
+	collectorService.AssertExpectations(t)
+}
+
+func TestApiCollectDataHandlerTooLargeAfterDecompression(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := fmt.Sprintf(`{"agent_id": "agent_id", "discovery_type": "discovery", "payload": {"key":"%s"}}`,
+		strings.Repeat("a", int(maxCollectorRequestSize)))
+
+	var body bytes.Buffer
+	writer := gzip.NewWriter(&body)
+	writer.Write([]byte(payload))
+	writer.Close()
+
+	assert.Less(t, int64(body.Len()), maxCollectorRequestSize)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", &body)
+	req.Header.Set("Content-Encoding", "gzip")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 413, resp.Code)
+	collectorService.AssertNotCalled(t, "StoreEvent", mock.Anything)
+}
+
+func TestApiCollectDataHandlerUnsupportedEncoding(t *testing.T) {
+	collectorService := new(services.MockCollectorService)
+
+	deps := setupTestDependencies()
+	deps.collectorService = collectorService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/collect", strings.NewReader("compressed"))
+	req.Header.Set("Content-Encoding", "br")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 415, resp.Code)
+	collectorService.AssertNotCalled(t, "StoreEvent", mock.Anything)
+}
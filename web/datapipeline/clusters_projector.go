@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/trento/internal"
 	"github.com/trento-project/trento/internal/cluster"
 	"github.com/trento-project/trento/internal/cluster/cib"
+	"github.com/trento-project/trento/internal/cluster/crmmon"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 	"gorm.io/datatypes"
@@ -18,9 +22,28 @@ import (
 )
 
 const (
-	partialSrHealth = "hana_sr_health"
+	partialSrHealth                  = "hana_sr_health"
+	partialASCSERSHealth             = "ascs_ers_health"
+	partialNodesHealth               = "cluster_nodes_health"
+	partialResourcesHealth           = "resources_health"
+	partialLocationConstraintsHealth = "location_constraints"
+	partialSBDHealth                 = "sbd_health"
+	partialFencingHealth             = "fencing_health"
+	// Location constraints created by crm resource move/ban have a well known id prefix
+	moveConstraintPrefix = "cli-prefer-"
+	banConstraintPrefix  = "cli-ban-"
+	// A resource is about to be moved away from a node when its fail count gets this close to the migration threshold
+	failCountWarningMargin = 1
+	resourceStatusFailed   = "failed"
+	resourceStatusBlocked  = "blocked"
+	resourceStatusOrphaned = "orphaned"
+	resourceStatusStopped  = "stopped"
 )
 
+// sapInstanceNameRegexp matches the SAP instance names used by the SAPInstance and SAPStartSrv
+// resource agents, e.g. NWP_ASCS00_sapnwpas: <SID>_<instance type><instance number>_<virtual hostname>
+var sapInstanceNameRegexp = regexp.MustCompile(`^([A-Z][A-Z0-9]{2})_([A-Z]+)([0-9]{2})_(.+)$`)
+
 func NewClustersProjector(db *gorm.DB) *projector {
 	clusterProjector := NewProjector("clusters", db)
 	clusterProjector.AddHandler(ClusterDiscovery, clustersProjector_ClusterDiscoveryHandler)
@@ -56,7 +79,64 @@ func clustersProjector_ClusterDiscoveryHandler(event *DataCollectedEvent, db *go
 		return err
 	}
 
-	err = ProjectHealth(db, clusterReadModel.ID, partialSrHealth, discoveredHealth)
+	// The cluster type may have changed since the last discovery, so the partial health which no longer applies is removed
+	err = ProjectHealthReplacing(db, clusterReadModel.ID, discoveredHealthType(clusterReadModel), discoveredHealth,
+		partialSrHealth, partialASCSERSHealth)
+	if err != nil {
+		log.Errorf("can't project health: %s", err)
+		return err
+	}
+
+	nodesHealth, err := computeNodesHealth(clusterReadModel)
+	if err != nil {
+		return err
+	}
+
+	err = ProjectHealth(db, clusterReadModel.ID, partialNodesHealth, nodesHealth)
+	if err != nil {
+		log.Errorf("can't project health: %s", err)
+		return err
+	}
+
+	resourcesHealth, err := computeResourcesHealth(clusterReadModel)
+	if err != nil {
+		return err
+	}
+
+	err = ProjectHealth(db, clusterReadModel.ID, partialResourcesHealth, resourcesHealth)
+	if err != nil {
+		log.Errorf("can't project health: %s", err)
+		return err
+	}
+
+	sbdHealth, sbdHealthReason, err := computeSBDHealth(clusterReadModel)
+	if err != nil {
+		return err
+	}
+
+	err = ProjectHealthWithReason(db, clusterReadModel.ID, partialSBDHealth, sbdHealth, sbdHealthReason)
+	if err != nil {
+		log.Errorf("can't project health: %s", err)
+		return err
+	}
+
+	fencingHealth, fencingHealthReason, err := computeFencingHealth(clusterReadModel)
+	if err != nil {
+		return err
+	}
+
+	err = ProjectHealthWithReason(db, clusterReadModel.ID, partialFencingHealth, fencingHealth, fencingHealthReason)
+	if err != nil {
+		log.Errorf("can't project health: %s", err)
+		return err
+	}
+
+	locationConstraintsHealth, err := computeLocationConstraintsHealth(clusterReadModel)
+	if err != nil {
+		return err
+	}
+
+	err = ProjectHealth(db, clusterReadModel.ID, partialLocationConstraintsHealth, locationConstraintsHealth)
 	if err != nil {
 		log.Errorf("can't project health: %s", err)
 		return err
@@ -77,11 +157,10 @@ func transformClusterData(cluster *cluster.Cluster) (*entities.Cluster, error) {
 	log.Debugf("%s", clusterDetail)
 
 	return &entities.Cluster{
-		ID:          cluster.Id,
-		Name:        cluster.Name,
-		ClusterType: detectClusterType(cluster),
-		// TODO: Cost-optimized has multiple SIDs, we will need to implement this in the future
-		SID:             parseClusterSID(cluster),
+		ID:              cluster.Id,
+		Name:            cluster.Name,
+		ClusterType:     detectClusterType(cluster),
+		SIDs:            parseClusterSIDs(cluster),
 		ResourcesNumber: cluster.Crmmon.Summary.Resources.Number,
 		HostsNumber:     cluster.Crmmon.Summary.Nodes.Number,
 		Details:         (datatypes.JSON)(clusterDetail),
@@ -110,72 +189,368 @@ func detectClusterType(cluster *cluster.Cluster) string {
 		return models.ClusterTypeHANAScaleUp
 	case hasSapHanaTopology && hasSAPHanaController:
 		return models.ClusterTypeHANAScaleOut
+	case hasASCSERSInstances(cluster):
+		return models.ClusterTypeASCSERS
 	default:
 		return models.ClusterTypeUnknown
 	}
 }
 
-// parseClusterSID returns the SIDs of the cluster
-// TODO: HANA scale-out has multiple SIDs, we will need to implement this in the future
-func parseClusterSID(c *cluster.Cluster) string {
+// hasASCSERSInstances returns true when the cluster manages both an ASCS and an ERS instance
+func hasASCSERSInstances(c *cluster.Cluster) bool {
+	var hasASCS, hasERS bool
+
+	for _, i := range parseASCSERSInstances(c) {
+		switch i.Role {
+		case models.ASCSERSRoleASCS:
+			hasASCS = true
+		case models.ASCSERSRoleERS:
+			hasERS = true
+		}
+	}
+
+	return hasASCS && hasERS
+}
+
+// parseClusterSIDs returns the SIDs of all the SAP systems managed by the cluster,
+// e.g. both the production and the non-replicated QAS systems of a cost-optimized HANA cluster
+func parseClusterSIDs(c *cluster.Cluster) []string {
+	sids := parseHANASIDs(c)
+
+	for _, p := range getCIBPrimitives(c) {
+		if p.Type == "SAPDatabase" {
+			sids = appendSID(sids, getPrimitiveAttribute(&p, "SID"))
+		}
+	}
+
+	for _, i := range parseASCSERSInstances(c) {
+		if matches := sapInstanceNameRegexp.FindStringSubmatch(i.InstanceName); matches != nil {
+			sids = appendSID(sids, matches[1])
+		}
+	}
+
+	sort.Strings(sids)
+
+	return sids
+}
+
+// parseHANASIDs returns the SIDs of the HANA systems replicated by the cluster, in CIB order
+func parseHANASIDs(c *cluster.Cluster) []string {
+	var sids []string
+
 	for _, r := range c.Cib.Configuration.Resources.Clones {
 		if r.Primitive.Type == "SAPHanaTopology" {
-			for _, a := range r.Primitive.InstanceAttributes {
-				if a.Name == "SID" && a.Value != "" {
-					return a.Value
-				}
-			}
+			sids = appendSID(sids, getPrimitiveAttribute(&r.Primitive, "SID"))
 		}
 	}
 
+	return sids
+}
+
+// parseHANAPrimarySID returns the SID the node sites and HANA statuses are based on,
+// which is the first replicated HANA system of the cluster
+func parseHANAPrimarySID(c *cluster.Cluster) string {
+	if sids := parseHANASIDs(c); len(sids) > 0 {
+		return sids[0]
+	}
+
 	return ""
 }
 
+func appendSID(sids []string, sid string) []string {
+	if sid == "" || internal.Contains(sids, sid) {
+		return sids
+	}
+
+	return append(sids, sid)
+}
+
 // parseClusterDetails parses the cluster details depending on the cluster type
 func parseClusterDetails(c *cluster.Cluster) (json.RawMessage, error) {
 	switch detectClusterType(c) {
 	case models.ClusterTypeHANAScaleUp, models.ClusterTypeHANAScaleOut:
 		return parseHANAClusterDetails(c)
+	case models.ClusterTypeASCSERS:
+		return parseASCSERSClusterDetails(c)
 	default:
-		return json.RawMessage{}, nil
+		return parseGenericClusterDetails(c)
 	}
 }
 
+// parseGenericClusterDetails parses the details available for any kind of cluster
+func parseGenericClusterDetails(c *cluster.Cluster) (json.RawMessage, error) {
+	dateLayout := "Mon Jan 2 15:04:05 2006"
+	cibLastWritten, _ := time.Parse(dateLayout, c.Crmmon.Summary.LastChange.Time)
+
+	clusterDetail := &entities.GenericClusterDetails{
+		CIBLastWritten:      cibLastWritten,
+		FencingType:         parseClusterFencingType(c),
+		FencingResources:    parseFencingResources(c),
+		StoppedResources:    parseClusterStoppedResources(c),
+		LocationConstraints: parseLocationConstraints(c),
+		Nodes:               parseClusterNodes(c),
+		SBDDevices:          parseSBDDevices(c),
+		Corosync:            parseCorosync(c),
+	}
+
+	return json.Marshal(clusterDetail)
+}
+
 // parseHANAClusterDetails parses the HANA cluster details
 func parseHANAClusterDetails(c *cluster.Cluster) (json.RawMessage, error) {
-	sid := parseClusterSID(c)
 	nodes := parseClusterNodes(c)
 
-	var systemReplicationMode, systemReplicationOperationMode, secondarySyncState, srHealthState string
-	if len(nodes) > 0 {
-		systemReplicationMode, _ = parseHANAAttribute(nodes[0], "srmode", sid)
-		systemReplicationOperationMode, _ = parseHANAAttribute(nodes[0], "op_mode", sid)
-		secondarySyncState = parseHANASecondarySyncState(nodes, sid)
-		srHealthState = parseHANAHealthState(nodes, sid)
+	var systemReplications []*entities.HANASystemReplication
+	for _, sid := range parseHANASIDs(c) {
+		systemReplications = append(systemReplications, parseHANASystemReplication(nodes, sid))
 	}
 
 	dateLayout := "Mon Jan 2 15:04:05 2006"
 	cibLastWritten, _ := time.Parse(dateLayout, c.Crmmon.Summary.LastChange.Time)
 
 	clusterDetail := &entities.HANAClusterDetails{
-		SystemReplicationMode:          systemReplicationMode,
-		SecondarySyncState:             secondarySyncState,
-		SystemReplicationOperationMode: systemReplicationOperationMode,
-		SRHealthState:                  srHealthState,
-		CIBLastWritten:                 cibLastWritten,
-		FencingType:                    parseClusterFencingType(c),
-		StoppedResources:               parseClusterStoppedResources(c),
-		Nodes:                          nodes,
-		SBDDevices:                     parseSBDDevices(c),
+		CIBLastWritten:      cibLastWritten,
+		FencingType:         parseClusterFencingType(c),
+		FencingResources:    parseFencingResources(c),
+		StoppedResources:    parseClusterStoppedResources(c),
+		LocationConstraints: parseLocationConstraints(c),
+		Nodes:               nodes,
+		SBDDevices:          parseSBDDevices(c),
+		Corosync:            parseCorosync(c),
+		SystemReplications:  systemReplications,
+	}
+
+	// The top level system replication state is the one of the primary SID
+	if len(systemReplications) > 0 {
+		clusterDetail.SystemReplicationMode = systemReplications[0].Mode
+		clusterDetail.SystemReplicationOperationMode = systemReplications[0].OperationMode
+		clusterDetail.SecondarySyncState = systemReplications[0].SecondarySyncState
+		clusterDetail.SRHealthState = systemReplications[0].SRHealthState
+	}
+
+	return json.Marshal(clusterDetail)
+}
+
+// parseHANASystemReplication returns the system replication state of a HANA system managed by the cluster
+func parseHANASystemReplication(nodes []*entities.HANAClusterNode, sid string) *entities.HANASystemReplication {
+	systemReplication := &entities.HANASystemReplication{
+		SID: sid,
+	}
+
+	if len(nodes) > 0 {
+		systemReplication.Mode, _ = parseHANAAttribute(nodes[0], "srmode", sid)
+		systemReplication.OperationMode, _ = parseHANAAttribute(nodes[0], "op_mode", sid)
+		systemReplication.SecondarySyncState = parseHANASecondarySyncState(nodes, sid)
+		systemReplication.SRHealthState = parseHANAHealthState(nodes, sid)
+	}
+
+	return systemReplication
+}
+
+// parseASCSERSClusterDetails parses the ASCS/ERS cluster details
+func parseASCSERSClusterDetails(c *cluster.Cluster) (json.RawMessage, error) {
+	dateLayout := "Mon Jan 2 15:04:05 2006"
+	cibLastWritten, _ := time.Parse(dateLayout, c.Crmmon.Summary.LastChange.Time)
+
+	clusterDetail := &entities.ASCSERSClusterDetails{
+		SID:                 parseASCSERSSID(c),
+		EnsaVersion:         parseEnsaVersion(c),
+		CIBLastWritten:      cibLastWritten,
+		FencingType:         parseClusterFencingType(c),
+		FencingResources:    parseFencingResources(c),
+		StoppedResources:    parseClusterStoppedResources(c),
+		LocationConstraints: parseLocationConstraints(c),
+		Instances:           parseASCSERSInstances(c),
+		Nodes:               parseClusterNodes(c),
+		SBDDevices:          parseSBDDevices(c),
+		Corosync:            parseCorosync(c),
 	}
 
 	return json.Marshal(clusterDetail)
 }
 
+// parseASCSERSSID returns the SID of the ASCS instance managed by the cluster
+func parseASCSERSSID(c *cluster.Cluster) string {
+	for _, i := range parseASCSERSInstances(c) {
+		matches := sapInstanceNameRegexp.FindStringSubmatch(i.InstanceName)
+		if i.Role == models.ASCSERSRoleASCS && matches != nil {
+			return matches[1]
+		}
+	}
+
+	return ""
+}
+
+// parseASCSERSInstances returns the ASCS and ERS instances managed by the cluster.
+// Each instance is expected to be grouped together with its virtual IP and filesystem resources,
+// as described in the SUSE and SAP best practices
+func parseASCSERSInstances(c *cluster.Cluster) []*entities.ASCSERSInstance {
+	var instances []*entities.ASCSERSInstance
+
+	for _, g := range c.Cib.Configuration.Resources.Groups {
+		if instance := parseASCSERSInstance(c, g.Primitives); instance != nil {
+			instances = append(instances, instance)
+		}
+	}
+
+	for _, p := range c.Cib.Configuration.Resources.Primitives {
+		if instance := parseASCSERSInstance(c, []cib.Primitive{p}); instance != nil {
+			instances = append(instances, instance)
+		}
+	}
+
+	return instances
+}
+
+// parseASCSERSInstance returns the ASCS or ERS instance managed by a set of grouped primitives,
+// or nil if none of them is a SAPInstance/SAPStartSrv resource of an ASCS/SCS or ERS instance
+func parseASCSERSInstance(c *cluster.Cluster, primitives []cib.Primitive) *entities.ASCSERSInstance {
+	var sapPrimitive *cib.Primitive
+	for i, p := range primitives {
+		switch {
+		case p.Type == "SAPInstance":
+			sapPrimitive = &primitives[i]
+		case p.Type == "SAPStartSrv" && sapPrimitive == nil:
+			sapPrimitive = &primitives[i]
+		}
+	}
+
+	if sapPrimitive == nil {
+		return nil
+	}
+
+	instanceName := getPrimitiveAttribute(sapPrimitive, "InstanceName")
+	matches := sapInstanceNameRegexp.FindStringSubmatch(instanceName)
+	if matches == nil {
+		return nil
+	}
+
+	instance := &entities.ASCSERSInstance{
+		InstanceName:    instanceName,
+		InstanceNumber:  matches[3],
+		VirtualHostname: matches[4],
+		ResourceID:      sapPrimitive.Id,
+	}
+
+	switch {
+	case matches[2] == "ERS" || strings.EqualFold(getPrimitiveAttribute(sapPrimitive, "IS_ERS"), "true"):
+		instance.Role = models.ASCSERSRoleERS
+	case matches[2] == "ASCS" || matches[2] == "SCS":
+		instance.Role = models.ASCSERSRoleASCS
+	default:
+		return nil
+	}
+
+	if r := findCrmmonResource(c, sapPrimitive.Id); r != nil {
+		instance.Status = parseResourceStatus(*r)
+		if r.Node != nil {
+			instance.Node = r.Node.Name
+		}
+	}
+
+	for i, p := range primitives {
+		switch p.Type {
+		case "IPaddr2":
+			if ip := getPrimitiveAttribute(&primitives[i], "ip"); ip != "" {
+				instance.VirtualIPs = append(instance.VirtualIPs, ip)
+			}
+		case "Filesystem":
+			instance.Filesystems = append(instance.Filesystems, &entities.ClusterFilesystem{
+				ResourceID: p.Id,
+				Device:     getPrimitiveAttribute(&primitives[i], "device"),
+				Directory:  getPrimitiveAttribute(&primitives[i], "directory"),
+				FSType:     getPrimitiveAttribute(&primitives[i], "fstype"),
+			})
+		}
+	}
+
+	return instance
+}
+
+// parseEnsaVersion returns the standalone enqueue server version used by an ASCS/ERS cluster.
+// With ENSA1 the ASCS instance must fail over to the node running the ERS instance to take over the
+// replicated lock table, which requires a migration-threshold of 1 on the ASCS resource.
+// ENSA2 can restart the ASCS instance on any node, so it doesn't need it
+func parseEnsaVersion(c *cluster.Cluster) string {
+	for _, i := range parseASCSERSInstances(c) {
+		if i.Role != models.ASCSERSRoleASCS {
+			continue
+		}
+
+		for _, p := range getCIBPrimitives(c) {
+			if p.Id != i.ResourceID {
+				continue
+			}
+
+			for _, a := range p.MetaAttributes {
+				if a.Name == "migration-threshold" && a.Value == "1" {
+					return models.EnsaVersion1
+				}
+			}
+		}
+
+		return models.EnsaVersion2
+	}
+
+	return ""
+}
+
+// getCIBPrimitives returns all the primitives configured in the CIB, including the grouped and cloned ones
+func getCIBPrimitives(c *cluster.Cluster) []cib.Primitive {
+	resources := c.Cib.Configuration.Resources
+
+	primitives := append([]cib.Primitive{}, resources.Primitives...)
+	for _, g := range resources.Groups {
+		primitives = append(primitives, g.Primitives...)
+	}
+
+	for _, clone := range resources.Clones {
+		primitives = append(primitives, clone.Primitive)
+	}
+
+	for _, master := range resources.Masters {
+		primitives = append(primitives, master.Primitive)
+	}
+
+	return primitives
+}
+
+// getPrimitiveAttribute returns the value of a primitive instance attribute,
+// or an empty string if the attribute is not set
+func getPrimitiveAttribute(p *cib.Primitive, name string) string {
+	for _, a := range p.InstanceAttributes {
+		if a.Name == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+// findCrmmonResource returns the crm_mon status of a primitive resource, looking into groups and clones as well
+func findCrmmonResource(c *cluster.Cluster, id string) *crmmon.Resource {
+	resources := c.Crmmon.Resources
+	for _, g := range c.Crmmon.Groups {
+		resources = append(resources, g.Resources...)
+	}
+
+	for _, clone := range c.Crmmon.Clones {
+		resources = append(resources, clone.Resources...)
+	}
+
+	for i, r := range resources {
+		if r.Id == id {
+			return &resources[i]
+		}
+	}
+
+	return nil
+}
+
 // parseClusterNodes parses the cluster nodes from the crmmon/cib data
 func parseClusterNodes(c *cluster.Cluster) []*entities.HANAClusterNode {
 	var nodes []*entities.HANAClusterNode
-	sid := parseClusterSID(c)
+	sid := parseHANAPrimarySID(c)
 
 	// TODO: remove plain resources grouping as in the future we'll need to distinguish between Cloned and Groups
 	resources := c.Crmmon.Resources
@@ -187,38 +562,52 @@ func parseClusterNodes(c *cluster.Cluster) []*entities.HANAClusterNode {
 		resources = append(resources, c.Resources...)
 	}
 
+	var nodeNames []string
+	nodeAttributes := make(map[string]map[string]string)
 	for _, n := range c.Crmmon.NodeAttributes.Nodes {
+		nodeNames = append(nodeNames, n.Name)
+		nodeAttributes[n.Name] = make(map[string]string)
+		for _, a := range n.Attributes {
+			nodeAttributes[n.Name][a.Name] = a.Value
+		}
+	}
+
+	// Nodes which are not online don't report any attribute, they are listed anyway
+	for _, n := range c.Crmmon.Nodes {
+		if _, ok := nodeAttributes[n.Name]; !ok {
+			nodeNames = append(nodeNames, n.Name)
+			nodeAttributes[n.Name] = make(map[string]string)
+		}
+	}
+
+	for _, name := range nodeNames {
 		node := &entities.HANAClusterNode{
-			Name:       n.Name,
-			Attributes: make(map[string]string),
+			Name:       name,
+			Attributes: nodeAttributes[name],
 		}
 
-		for _, a := range n.Attributes {
-			node.Attributes[a.Name] = a.Value
+		for _, cn := range c.Crmmon.Nodes {
+			if cn.Name == name {
+				node.Online = cn.Online
+				node.Standby = cn.Standby
+				node.Maintenance = cn.Maintenance
+				node.Unclean = cn.Unclean
+				node.Pending = cn.Pending
+				node.Shutdown = cn.Shutdown
+				break
+			}
 		}
 
 		for _, r := range resources {
 			if r.Node == nil {
 				continue
 			}
-			if r.Node.Name == n.Name {
+			if r.Node.Name == name {
 				resource := &entities.ClusterResource{
-					ID:   r.Id,
-					Type: r.Agent,
-					Role: r.Role,
-				}
-
-				switch {
-				case r.Active:
-					resource.Status = "active"
-				case r.Blocked:
-					resource.Status = "blocked"
-				case r.Failed:
-					resource.Status = "failed"
-				case r.FailureIgnored:
-					resource.Status = "failure_ignored"
-				case r.Orphaned:
-					resource.Status = "orphaned"
+					ID:     r.Id,
+					Type:   r.Agent,
+					Role:   r.Role,
+					Status: parseResourceStatus(r),
 				}
 
 				var primitives []cib.Primitive
@@ -239,18 +628,34 @@ func parseClusterNodes(c *cluster.Cluster) []*entities.HANAClusterNode {
 					}
 				}
 
-				for _, nh := range c.Crmmon.NodeHistory.Nodes {
-					if nh.Name == n.Name {
-						for _, rh := range nh.ResourceHistory {
-							if rh.Name == resource.ID {
-								resource.FailCount = rh.FailCount
-								break
-							}
-						}
+				node.Resources = append(node.Resources, resource)
+			}
+		}
+
+		// Fail counts stay on the node where a resource failed even after it moved away, preventing its failback,
+		// so the resources with a fail count are listed also when they are not running on the node anymore
+		for _, nh := range c.Crmmon.NodeHistory.Nodes {
+			if nh.Name != name {
+				continue
+			}
+
+			for _, rh := range nh.ResourceHistory {
+				resource := findNodeResource(node.Resources, rh.Name)
+				if resource == nil {
+					if rh.FailCount == 0 {
+						continue
+					}
+
+					resource = &entities.ClusterResource{
+						ID:   rh.Name,
+						Type: resourceAgent(resources, rh.Name),
+						Role: "Stopped",
 					}
+					node.Resources = append(node.Resources, resource)
 				}
 
-				node.Resources = append(node.Resources, resource)
+				resource.FailCount = rh.FailCount
+				resource.MigrationThreshold = rh.MigrationThreshold
 			}
 		}
 
@@ -262,6 +667,46 @@ func parseClusterNodes(c *cluster.Cluster) []*entities.HANAClusterNode {
 	return nodes
 }
 
+func findNodeResource(resources []*entities.ClusterResource, id string) *entities.ClusterResource {
+	for _, r := range resources {
+		if r.ID == id {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// resourceAgent returns the agent of the crm_mon resource with the given ID, if any
+func resourceAgent(resources []crmmon.Resource, id string) string {
+	for _, r := range resources {
+		if r.Id == id {
+			return r.Agent
+		}
+	}
+
+	return ""
+}
+
+// parseResourceStatus returns the status of a crm_mon resource.
+// Failures take precedence, as failed resources are still reported as active while running on a node
+func parseResourceStatus(r crmmon.Resource) string {
+	switch {
+	case r.Failed:
+		return resourceStatusFailed
+	case r.Blocked:
+		return resourceStatusBlocked
+	case r.Orphaned:
+		return resourceStatusOrphaned
+	case r.FailureIgnored:
+		return "failure_ignored"
+	case r.Active:
+		return "active"
+	default:
+		return ""
+	}
+}
+
 // parseHANAAttribute returns an HANA attribute value
 func parseHANAAttribute(node *entities.HANAClusterNode, attributeName string, sid string) (string, bool) {
 	hanaAttributeName := fmt.Sprintf("hana_%s_%s", strings.ToLower(sid), attributeName)
@@ -342,6 +787,79 @@ func parseClusterFencingType(c *cluster.Cluster) string {
 	return ""
 }
 
+// parseFencingResources returns the stonith resources configured in the CIB along with their status.
+// The credentials are redacted here as well, as older agents publish them
+func parseFencingResources(c *cluster.Cluster) []*entities.FencingResource {
+	var fencingResources []*entities.FencingResource
+
+	for _, p := range getCIBPrimitives(c) {
+		if p.Class != "stonith" {
+			continue
+		}
+
+		fencingResource := &entities.FencingResource{
+			ID:                 p.Id,
+			Agent:              p.Type,
+			Status:             resourceStatusStopped,
+			InstanceAttributes: make(map[string]string),
+			MetaAttributes:     make(map[string]string),
+		}
+
+		for _, a := range p.InstanceAttributes {
+			value := a.Value
+			if cluster.IsSecretAttribute(a.Name) {
+				value = cluster.RedactedValue
+			}
+			fencingResource.InstanceAttributes[a.Name] = value
+		}
+
+		for _, a := range p.MetaAttributes {
+			fencingResource.MetaAttributes[a.Name] = a.Value
+			if a.Name == "target-role" && strings.EqualFold(a.Value, "Stopped") {
+				fencingResource.Disabled = true
+			}
+		}
+
+		if r := findCrmmonResource(c, p.Id); r != nil {
+			if status := parseResourceStatus(*r); status != "" {
+				fencingResource.Status = status
+			}
+		}
+
+		fencingResources = append(fencingResources, fencingResource)
+	}
+
+	return fencingResources
+}
+
+// parseLocationConstraints returns the location constraints left behind by manual resource migrations
+func parseLocationConstraints(c *cluster.Cluster) []*entities.LocationConstraint {
+	var constraints []*entities.LocationConstraint
+
+	for _, l := range c.Cib.Configuration.Constraints.RscLocations {
+		var constraintType string
+		switch {
+		case strings.HasPrefix(l.Id, moveConstraintPrefix):
+			constraintType = models.LocationConstraintTypeMove
+		case strings.HasPrefix(l.Id, banConstraintPrefix):
+			constraintType = models.LocationConstraintTypeBan
+		default:
+			continue
+		}
+
+		constraints = append(constraints, &entities.LocationConstraint{
+			ID:       l.Id,
+			Type:     constraintType,
+			Resource: l.Resource,
+			Node:     l.Node,
+			Role:     l.Role,
+			Score:    l.Score,
+		})
+	}
+
+	return constraints
+}
+
 // parseClusterStoppedResources returns all the stopped resources in a cluster
 func parseClusterStoppedResources(c *cluster.Cluster) []*entities.ClusterResource {
 	var stoppedResources []*entities.ClusterResource
@@ -359,7 +877,8 @@ func parseClusterStoppedResources(c *cluster.Cluster) []*entities.ClusterResourc
 	for _, r := range resources {
 		if r.NodesRunningOn == 0 && !r.Active {
 			resource := &entities.ClusterResource{
-				ID: r.Id,
+				ID:     r.Id,
+				Status: parseResourceStatus(r),
 			}
 			stoppedResources = append(stoppedResources, resource)
 		}
@@ -373,26 +892,98 @@ func parseSBDDevices(c *cluster.Cluster) []*entities.SBDDevice {
 	var sbdDevices []*entities.SBDDevice
 	for _, s := range c.SBD.Devices {
 		sbdDevice := &entities.SBDDevice{
-			Device: s.Device,
-			Status: s.Status,
+			Device:          s.Device,
+			Status:          s.Status,
+			TimeoutWatchdog: s.Dump.TimeoutWatchdog,
+			TimeoutMsgwait:  s.Dump.TimeoutMsgwait,
 		}
+
+		for _, n := range s.List {
+			sbdDevice.Nodes = append(sbdDevice.Nodes, &entities.SBDNode{
+				ID:     n.Id,
+				Name:   n.Name,
+				Status: n.Status,
+			})
+		}
+
 		sbdDevices = append(sbdDevices, sbdDevice)
 	}
 
 	return sbdDevices
 }
 
+// parseCorosync returns the corosync settings and the rings status, as seen by the discovering node
+func parseCorosync(c *cluster.Cluster) *entities.CorosyncDetails {
+	config := c.Corosync.Config
+	if config.Totem.Transport == "" && len(config.Nodes) == 0 && len(c.Corosync.Links) == 0 {
+		return nil
+	}
+
+	corosync := &entities.CorosyncDetails{
+		Transport:     config.Totem.Transport,
+		RRPMode:       config.Totem.RRPMode,
+		Token:         config.Totem.Token,
+		Consensus:     config.Totem.Consensus,
+		ExpectedVotes: config.Quorum.ExpectedVotes,
+		TwoNode:       config.Quorum.TwoNode,
+	}
+
+	for _, n := range config.Nodes {
+		corosync.Nodes = append(corosync.Nodes, &entities.CorosyncNode{
+			ID:        n.NodeID,
+			Name:      n.Name,
+			Addresses: n.Addresses,
+		})
+	}
+
+	for _, l := range c.Corosync.Links {
+		corosync.Rings = append(corosync.Rings, &entities.CorosyncRing{
+			ID:      l.ID,
+			Address: l.Address,
+			Status:  parseCorosyncLinkStatus(l),
+			Faulty:  l.Faulty,
+		})
+	}
+
+	return corosync
+}
+
+// parseCorosyncLinkStatus returns the status of a ring (corosync 2), or summarizes the status of the nodes in a link (corosync 3)
+func parseCorosyncLinkStatus(l *cluster.CorosyncLink) string {
+	if l.Status != "" || len(l.Nodes) == 0 {
+		return l.Status
+	}
+
+	var statuses []string
+	for _, n := range l.Nodes {
+		statuses = append(statuses, fmt.Sprintf("node %d: %s", n.NodeID, n.Status))
+	}
+
+	return strings.Join(statuses, ", ")
+}
+
+// discoveredHealthType returns the partial health type the discovered health of a cluster is stored as
+func discoveredHealthType(c *entities.Cluster) string {
+	switch c.ClusterType {
+	case models.ClusterTypeASCSERS:
+		return partialASCSERSHealth
+	default:
+		return partialSrHealth
+	}
+}
+
 func computeDiscoveredHealth(c *entities.Cluster) (string, error) {
 	switch c.ClusterType {
 	case models.ClusterTypeHANAScaleUp, models.ClusterTypeHANAScaleOut:
 		return computeDiscoveredHANAHealth(c)
+	case models.ClusterTypeASCSERS:
+		return computeDiscoveredASCSERSHealth(c)
 	default:
 		return models.HealthSummaryHealthUnknown, nil
 	}
 }
 
 func computeDiscoveredHANAHealth(c *entities.Cluster) (string, error) {
-	var srHealth string
 	var clusterDetailHANA entities.HANAClusterDetails
 
 	err := json.Unmarshal(c.Details, &clusterDetailHANA)
@@ -400,18 +991,222 @@ func computeDiscoveredHANAHealth(c *entities.Cluster) (string, error) {
 		return "", err
 	}
 
-	srHealthState := clusterDetailHANA.SRHealthState
-	srSyncState := clusterDetailHANA.SecondarySyncState
+	if len(clusterDetailHANA.SystemReplications) == 0 {
+		return models.HealthSummaryHealthUnknown, nil
+	}
+
+	// The cluster health is the worst system replication health among its HANA systems
+	var partialHealths = make(map[string]string)
+	for _, r := range clusterDetailHANA.SystemReplications {
+		partialHealths[r.SID] = computeHANASystemReplicationHealth(r)
+	}
+
+	return computeOverallHealth(partialHealths), nil
+}
+
+func computeHANASystemReplicationHealth(r *entities.HANASystemReplication) string {
+	srHealthState := r.SRHealthState
+	srSyncState := r.SecondarySyncState
 
 	// Passing state if SR Health state is 4 and Sync state is SOK, everything else is critical
 	// If data is not present for some reason the state goes to unknown
 	if srHealthState == models.HANASrHealthOK && srSyncState == models.HANASrSyncSOK {
-		srHealth = models.HealthSummaryHealthPassing
+		return models.HealthSummaryHealthPassing
 	} else if srHealthState == "" || srSyncState == "" {
-		srHealth = models.HealthSummaryHealthUnknown
+		return models.HealthSummaryHealthUnknown
 	} else {
-		srHealth = models.HealthSummaryHealthCritical
+		return models.HealthSummaryHealthCritical
+	}
+}
+
+// computeNodesHealth computes the health of the cluster based on the Pacemaker state of its nodes:
+// unclean or offline nodes are critical, nodes in standby are a warning
+func computeNodesHealth(c *entities.Cluster) (string, error) {
+	var clusterDetail entities.GenericClusterDetails
+
+	err := json.Unmarshal(c.Details, &clusterDetail)
+	if err != nil {
+		return "", err
+	}
+
+	if len(clusterDetail.Nodes) == 0 {
+		return models.HealthSummaryHealthUnknown, nil
+	}
+
+	health := models.HealthSummaryHealthPassing
+	for _, n := range clusterDetail.Nodes {
+		if n.Unclean || !n.Online {
+			return models.HealthSummaryHealthCritical, nil
+		}
+
+		if n.Standby {
+			health = models.HealthSummaryHealthWarning
+		}
+	}
+
+	return health, nil
+}
+
+// computeResourcesHealth returns critical if any resource is failed, blocked or orphaned,
+// and warning if any resource fail count is close to its migration threshold
+func computeResourcesHealth(c *entities.Cluster) (string, error) {
+	var clusterDetail entities.GenericClusterDetails
+
+	err := json.Unmarshal(c.Details, &clusterDetail)
+	if err != nil {
+		return "", err
+	}
+
+	resources := clusterDetail.StoppedResources
+	for _, n := range clusterDetail.Nodes {
+		resources = append(resources, n.Resources...)
+	}
+
+	health := models.HealthSummaryHealthPassing
+	for _, r := range resources {
+		switch r.Status {
+		case resourceStatusFailed, resourceStatusBlocked, resourceStatusOrphaned:
+			return models.HealthSummaryHealthCritical, nil
+		}
+
+		if r.FailCount > 0 && r.MigrationThreshold > 0 && r.MigrationThreshold-r.FailCount <= failCountWarningMargin {
+			health = models.HealthSummaryHealthWarning
+		}
+	}
+
+	return health, nil
+}
+
+// computeSBDHealth returns critical if any SBD device is unhealthy, and warning if any cluster node
+// is missing from the device slots or the watchdog and msgwait timeouts disagree with each other.
+// The reason of a non passing health is returned as well
+func computeSBDHealth(c *entities.Cluster) (string, string, error) {
+	var clusterDetail entities.GenericClusterDetails
+
+	err := json.Unmarshal(c.Details, &clusterDetail)
+	if err != nil {
+		return "", "", err
+	}
+
+	health := models.HealthSummaryHealthPassing
+	var reasons []string
+
+	setHealth := func(h string, reason string, args ...interface{}) {
+		if h == models.HealthSummaryHealthCritical || health == models.HealthSummaryHealthPassing {
+			health = h
+		}
+		reasons = append(reasons, fmt.Sprintf(reason, args...))
+	}
+
+	var reference *entities.SBDDevice
+	for _, d := range clusterDetail.SBDDevices {
+		if d.Status != cluster.SBDStatusHealthy {
+			setHealth(models.HealthSummaryHealthCritical, "SBD device %s is %s", d.Device, d.Status)
+			continue
+		}
+
+		for _, n := range clusterDetail.Nodes {
+			found := false
+			for _, sn := range d.Nodes {
+				if sn.Name == n.Name {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				setHealth(models.HealthSummaryHealthWarning, "node %s is missing from the slots of the SBD device %s", n.Name, d.Device)
+			}
+		}
+
+		// The msgwait timeout must give the fenced node the time to notice the message and self-fence
+		if d.TimeoutMsgwait < 2*d.TimeoutWatchdog {
+			setHealth(models.HealthSummaryHealthWarning,
+				"SBD device %s msgwait timeout (%ds) should be at least twice the watchdog timeout (%ds)",
+				d.Device, d.TimeoutMsgwait, d.TimeoutWatchdog)
+		}
+
+		if reference == nil {
+			reference = d
+		} else if d.TimeoutWatchdog != reference.TimeoutWatchdog || d.TimeoutMsgwait != reference.TimeoutMsgwait {
+			setHealth(models.HealthSummaryHealthWarning,
+				"SBD devices %s and %s have different watchdog or msgwait timeouts", reference.Device, d.Device)
+		}
+	}
+
+	return health, strings.Join(reasons, "; "), nil
+}
+
+// computeFencingHealth returns critical if any stonith resource is disabled or not running,
+// as the cluster is not able to fence its nodes. The reason of a non passing health is returned as well
+func computeFencingHealth(c *entities.Cluster) (string, string, error) {
+	var clusterDetail entities.GenericClusterDetails
+
+	err := json.Unmarshal(c.Details, &clusterDetail)
+	if err != nil {
+		return "", "", err
+	}
+
+	var reasons []string
+	for _, f := range clusterDetail.FencingResources {
+		switch {
+		case f.Disabled:
+			reasons = append(reasons, fmt.Sprintf("stonith resource %s is disabled", f.ID))
+		case f.Status != "active":
+			reasons = append(reasons, fmt.Sprintf("stonith resource %s is %s", f.ID, f.Status))
+		}
+	}
+
+	if len(reasons) > 0 {
+		return models.HealthSummaryHealthCritical, strings.Join(reasons, "; "), nil
 	}
 
-	return srHealth, nil
+	return models.HealthSummaryHealthPassing, "", nil
+}
+
+// computeLocationConstraintsHealth returns warning while there are location constraints
+// left behind by manual resource migrations, as they prevent the cluster from failing over
+func computeLocationConstraintsHealth(c *entities.Cluster) (string, error) {
+	var clusterDetail entities.GenericClusterDetails
+
+	err := json.Unmarshal(c.Details, &clusterDetail)
+	if err != nil {
+		return "", err
+	}
+
+	if len(clusterDetail.LocationConstraints) > 0 {
+		return models.HealthSummaryHealthWarning, nil
+	}
+
+	return models.HealthSummaryHealthPassing, nil
+}
+
+// computeDiscoveredASCSERSHealth returns critical if the ASCS instance is not running,
+// and warning if the lock table is not replicated to another node by a running ERS instance
+func computeDiscoveredASCSERSHealth(c *entities.Cluster) (string, error) {
+	var clusterDetailASCSERS entities.ASCSERSClusterDetails
+
+	err := json.Unmarshal(c.Details, &clusterDetailASCSERS)
+	if err != nil {
+		return "", err
+	}
+
+	var ascs, ers *entities.ASCSERSInstance
+	for _, i := range clusterDetailASCSERS.Instances {
+		switch i.Role {
+		case models.ASCSERSRoleASCS:
+			ascs = i
+		case models.ASCSERSRoleERS:
+			ers = i
+		}
+	}
+
+	switch {
+	case ascs == nil || ascs.Status != "active":
+		return models.HealthSummaryHealthCritical, nil
+	case ers == nil || ers.Status != "active" || ers.Node == ascs.Node:
+		return models.HealthSummaryHealthWarning, nil
+	default:
+		return models.HealthSummaryHealthPassing, nil
+	}
 }
@@ -7,8 +7,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/trento-project/trento/internal/cluster"
+	"github.com/trento-project/trento/internal/cluster/cib"
+	"github.com/trento-project/trento/internal/cluster/crmmon"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
@@ -53,13 +56,95 @@ func TestClustersProjector_ClusterDiscoveryHandler(t *testing.T) {
 
 	assert.Equal(t, "5dfbd28f35cbfb38969f9b99243ae8d4", cluster.ID)
 	assert.Equal(t, models.ClusterTypeHANAScaleUp, cluster.ClusterType)
-	assert.Equal(t, "PRD", cluster.SID)
+	assert.Equal(t, pq.StringArray{"PRD"}, cluster.SIDs)
 	assert.Equal(t, 8, cluster.ResourcesNumber)
 	assert.Equal(t, 2, cluster.HostsNumber)
 	assert.NotNil(t, cluster.Details)
 	assert.Equal(t, health.ID, cluster.ID)
 	assert.Equal(t, "critical", health.Health)
-	assert.Equal(t, map[string]string{"hana_sr_health": "critical"}, partialHealth)
+	assert.Equal(t, map[string]string{"hana_sr_health": "critical", "cluster_nodes_health": "passing", "resources_health": "passing", "sbd_health": "critical", "fencing_health": "passing", "location_constraints": "passing"}, partialHealth)
+}
+
+func TestClustersProjector_ClusterDiscoveryHandler_ASCSERS(t *testing.T) {
+	db := helpers.SetupTestDatabase(t)
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	tx.AutoMigrate(&entities.Cluster{}, &entities.HealthState{})
+
+	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_ascs_ers.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+	dataCollectedEvent := &DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: ClusterDiscovery,
+		Payload:       byteValue,
+	}
+
+	clustersProjector_ClusterDiscoveryHandler(dataCollectedEvent, tx)
+
+	var cluster entities.Cluster
+	tx.First(&cluster)
+
+	var health entities.HealthState
+	tx.First(&health)
+
+	var partialHealth map[string]string
+	json.Unmarshal(health.PartialHealths, &partialHealth)
+
+	assert.Equal(t, "e27d313a674375b2066777a89ee346b9", cluster.ID)
+	assert.Equal(t, models.ClusterTypeASCSERS, cluster.ClusterType)
+	assert.Equal(t, pq.StringArray{"NWP"}, cluster.SIDs)
+	assert.Equal(t, health.ID, cluster.ID)
+	assert.Equal(t, "passing", health.Health)
+	assert.Equal(t, map[string]string{"ascs_ers_health": "passing", "cluster_nodes_health": "passing", "resources_health": "passing", "sbd_health": "passing", "fencing_health": "passing", "location_constraints": "passing"}, partialHealth)
+}
+
+func TestClustersProjector_ClusterDiscoveryHandler_ClusterTypeChanged(t *testing.T) {
+	db := helpers.SetupTestDatabase(t)
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	tx.AutoMigrate(&entities.Cluster{}, &entities.HealthState{})
+	tx.Create(&entities.HealthState{
+		ID:             "e27d313a674375b2066777a89ee346b9",
+		Health:         "critical",
+		PartialHealths: datatypes.JSON(`{"hana_sr_health": "critical"}`),
+		Reasons:        datatypes.JSON(`{"hana_sr_health": "the system replication is broken"}`),
+	})
+
+	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_ascs_ers.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+	dataCollectedEvent := &DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: ClusterDiscovery,
+		Payload:       byteValue,
+	}
+
+	err = clustersProjector_ClusterDiscoveryHandler(dataCollectedEvent, tx)
+	assert.NoError(t, err)
+
+	var health entities.HealthState
+	tx.First(&health)
+
+	var partialHealth map[string]string
+	json.Unmarshal(health.PartialHealths, &partialHealth)
+	var reasons map[string]string
+	json.Unmarshal(health.Reasons, &reasons)
+
+	assert.Equal(t, "passing", health.Health)
+	assert.Equal(t, "passing", partialHealth["ascs_ers_health"])
+	assert.NotContains(t, partialHealth, "hana_sr_health")
+	assert.NotContains(t, reasons, "hana_sr_health")
 }
 
 func TestTransformClusterData_HANAScaleUp(t *testing.T) {
@@ -79,8 +164,26 @@ func TestTransformClusterData_HANAScaleUp(t *testing.T) {
 			SystemReplicationOperationMode: "logreplay",
 			SecondarySyncState:             "SFAIL",
 			SRHealthState:                  "4",
-			CIBLastWritten:                 time.Date(2021, time.November, 6, 19, 8, 41, 0, time.UTC),
-			FencingType:                    "external/sbd",
+			SystemReplications: []*entities.HANASystemReplication{
+				{
+					SID:                "PRD",
+					Mode:               "sync",
+					OperationMode:      "logreplay",
+					SecondarySyncState: "SFAIL",
+					SRHealthState:      "4",
+				},
+			},
+			CIBLastWritten: time.Date(2021, time.November, 6, 19, 8, 41, 0, time.UTC),
+			FencingType:    "external/sbd",
+			FencingResources: []*entities.FencingResource{
+				{
+					ID:                 "stonith-sbd",
+					Agent:              "external/sbd",
+					Status:             "active",
+					InstanceAttributes: map[string]string{"pcmk_delay_max": "15"},
+					MetaAttributes:     map[string]string{},
+				},
+			},
 			StoppedResources: []*entities.ClusterResource{
 				{
 					ID:        "stopped_dummy_resource",
@@ -96,6 +199,7 @@ func TestTransformClusterData_HANAScaleUp(t *testing.T) {
 					Site:       "Site1",
 					VirtualIPs: []string{"10.74.1.12"},
 					HANAStatus: models.HANAStatusPrimary,
+					Online:     true,
 					Attributes: map[string]string{
 						"hana_prd_clone_state":         "PROMOTED",
 						"hana_prd_op_mode":             "logreplay",
@@ -111,46 +215,52 @@ func TestTransformClusterData_HANAScaleUp(t *testing.T) {
 					},
 					Resources: []*entities.ClusterResource{
 						{
-							ID:        "stonith-sbd",
-							Type:      "stonith:external/sbd",
-							Role:      "Started",
-							Status:    "active",
-							FailCount: 0,
+							ID:                 "stonith-sbd",
+							Type:               "stonith:external/sbd",
+							Role:               "Started",
+							Status:             "active",
+							FailCount:          0,
+							MigrationThreshold: 5000,
 						},
 						{
-							ID:        "rsc_exporter_PRD_HDB00",
-							Type:      "systemd:prometheus-hanadb_exporter@PRD_HDB00",
-							Role:      "Started",
-							Status:    "active",
-							FailCount: 0,
+							ID:                 "rsc_exporter_PRD_HDB00",
+							Type:               "systemd:prometheus-hanadb_exporter@PRD_HDB00",
+							Role:               "Started",
+							Status:             "active",
+							FailCount:          0,
+							MigrationThreshold: 5000,
 						},
 						{
-							ID:        "rsc_ip_PRD_HDB00",
-							Type:      "ocf::heartbeat:IPaddr2",
-							Role:      "Started",
-							Status:    "active",
-							FailCount: 0,
+							ID:                 "rsc_ip_PRD_HDB00",
+							Type:               "ocf::heartbeat:IPaddr2",
+							Role:               "Started",
+							Status:             "active",
+							FailCount:          0,
+							MigrationThreshold: 5000,
 						},
 						{
-							ID:        "rsc_socat_PRD_HDB00",
-							Type:      "ocf::heartbeat:azure-lb",
-							Role:      "Started",
-							Status:    "active",
-							FailCount: 0,
+							ID:                 "rsc_socat_PRD_HDB00",
+							Type:               "ocf::heartbeat:azure-lb",
+							Role:               "Started",
+							Status:             "active",
+							FailCount:          0,
+							MigrationThreshold: 5000,
 						},
 						{
-							ID:        "rsc_SAPHana_PRD_HDB00",
-							Type:      "ocf::suse:SAPHana",
-							Role:      "Master",
-							Status:    "active",
-							FailCount: 0,
+							ID:                 "rsc_SAPHana_PRD_HDB00",
+							Type:               "ocf::suse:SAPHana",
+							Role:               "Master",
+							Status:             "active",
+							FailCount:          0,
+							MigrationThreshold: 5000,
 						},
 						{
-							ID:        "rsc_SAPHanaTopology_PRD_HDB00",
-							Type:      "ocf::suse:SAPHanaTopology",
-							Role:      "Started",
-							Status:    "active",
-							FailCount: 0,
+							ID:                 "rsc_SAPHanaTopology_PRD_HDB00",
+							Type:               "ocf::suse:SAPHanaTopology",
+							Role:               "Started",
+							Status:             "active",
+							FailCount:          0,
+							MigrationThreshold: 5000,
 						},
 					},
 				},
@@ -172,34 +282,58 @@ func TestTransformClusterData_HANAScaleUp(t *testing.T) {
 					},
 					Resources: []*entities.ClusterResource{
 						{
-							ID:        "rsc_SAPHana_PRD_HDB00",
-							Type:      "ocf::suse:SAPHana",
-							Role:      "Slave",
-							Status:    "active",
-							FailCount: 1,
+							ID:                 "rsc_SAPHana_PRD_HDB00",
+							Type:               "ocf::suse:SAPHana",
+							Role:               "Slave",
+							Status:             "active",
+							FailCount:          1,
+							MigrationThreshold: 5000,
 						},
 						{
-							ID:        "rsc_SAPHanaTopology_PRD_HDB00",
-							Type:      "ocf::suse:SAPHanaTopology",
-							Role:      "Started",
-							Status:    "active",
-							FailCount: 0,
+							ID:                 "rsc_SAPHanaTopology_PRD_HDB00",
+							Type:               "ocf::suse:SAPHanaTopology",
+							Role:               "Started",
+							Status:             "active",
+							FailCount:          0,
+							MigrationThreshold: 5000,
 						},
 					},
 					VirtualIPs: nil,
 					HANAStatus: models.HANAStatusFailed,
+					Online:     true,
 				},
 			},
 			SBDDevices: []*entities.SBDDevice{
 				{
-					Device: "/dev/disk/by-id/scsi-SLIO-ORG_IBLOCK_649b292b-ae9d-49a4-8002-2e602a0ab56e",
-					Status: "healthy",
+					Device:          "/dev/disk/by-id/scsi-SLIO-ORG_IBLOCK_649b292b-ae9d-49a4-8002-2e602a0ab56e",
+					Status:          "healthy",
+					TimeoutWatchdog: 5,
+					TimeoutMsgwait:  10,
+					Nodes: []*entities.SBDNode{
+						{ID: 0, Name: "vmhana01", Status: "clear"},
+						{ID: 1, Name: "vmhana02", Status: "clear"},
+					},
 				},
 				{
 					Device: "/dev/disk/by-id/scsi-SLIO-ORG_IBLOCK_649b292b-ae9d-49a4-8002-2e602a012345",
 					Status: "unhealthy",
 				},
 			},
+			Corosync: &entities.CorosyncDetails{
+				Transport:     "knet",
+				Token:         5000,
+				Consensus:     6000,
+				ExpectedVotes: 2,
+				TwoNode:       true,
+				Nodes: []*entities.CorosyncNode{
+					{ID: 1, Name: "node01", Addresses: []string{"192.168.123.9", "10.0.0.9"}},
+					{ID: 2, Name: "node02", Addresses: []string{"192.168.123.10", "10.0.0.10"}},
+				},
+				Rings: []*entities.CorosyncRing{
+					{ID: 0, Address: "192.168.123.9", Status: "node 1: localhost, node 2: connected"},
+					{ID: 1, Address: "10.0.0.9", Status: "node 1: localhost, node 2: disconnected", Faulty: true},
+				},
+			},
 		},
 	)
 
@@ -208,13 +342,722 @@ func TestTransformClusterData_HANAScaleUp(t *testing.T) {
 			Name:            "hana_cluster",
 			ID:              "5dfbd28f35cbfb38969f9b99243ae8d4",
 			ClusterType:     models.ClusterTypeHANAScaleUp,
-			SID:             "PRD",
+			SIDs:            []string{"PRD"},
 			ResourcesNumber: 8,
 			HostsNumber:     2,
 			Details:         expectedHANAClusterDetails,
 		}, clusterOut)
 }
 
+func TestParseClusterSIDs_CostOptimized(t *testing.T) {
+	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_hana_scale_up.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var c cluster.Cluster
+	json.Unmarshal(byteValue, &c)
+
+	// The non-replicated QAS system of a cost-optimized scenario runs on the secondary node
+	c.Cib.Configuration.Resources.Primitives = append(c.Cib.Configuration.Resources.Primitives, cib.Primitive{
+		Id:   "rsc_SAP_QAS_HDB10",
+		Type: "SAPDatabase",
+		InstanceAttributes: []cib.Attribute{
+			{Name: "DBTYPE", Value: "HDB"},
+			{Name: "SID", Value: "QAS"},
+			{Name: "InstanceName", Value: "QAS_HDB10"},
+		},
+	})
+
+	assert.Equal(t, []string{"PRD", "QAS"}, parseClusterSIDs(&c))
+	assert.Equal(t, []string{"PRD"}, parseHANASIDs(&c))
+	assert.Equal(t, models.ClusterTypeHANAScaleUp, detectClusterType(&c))
+}
+
+func TestComputeDiscoveredHANAHealth_MultipleSIDs(t *testing.T) {
+	cases := []struct {
+		systemReplications []*entities.HANASystemReplication
+		expected           string
+	}{
+		{
+			[]*entities.HANASystemReplication{
+				{SID: "PRD", SRHealthState: "4", SecondarySyncState: "SOK"},
+				{SID: "QAS", SRHealthState: "4", SecondarySyncState: "SOK"},
+			},
+			models.HealthSummaryHealthPassing,
+		},
+		{
+			[]*entities.HANASystemReplication{
+				{SID: "PRD", SRHealthState: "4", SecondarySyncState: "SOK"},
+				{SID: "QAS", SRHealthState: "1", SecondarySyncState: "SFAIL"},
+			},
+			models.HealthSummaryHealthCritical,
+		},
+		{
+			[]*entities.HANASystemReplication{
+				{SID: "PRD", SRHealthState: "4", SecondarySyncState: "SOK"},
+				{SID: "QAS"},
+			},
+			models.HealthSummaryHealthUnknown,
+		},
+		{
+			nil,
+			models.HealthSummaryHealthUnknown,
+		},
+	}
+
+	for _, c := range cases {
+		details, _ := json.Marshal(&entities.HANAClusterDetails{SystemReplications: c.systemReplications})
+		health, err := computeDiscoveredHealth(&entities.Cluster{
+			ClusterType: models.ClusterTypeHANAScaleUp,
+			Details:     details,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, health)
+	}
+}
+
+func TestTransformClusterData_ASCSERS(t *testing.T) {
+	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_ascs_ers.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var clusterIn cluster.Cluster
+	json.Unmarshal(byteValue, &clusterIn)
+	clusterOut, _ := transformClusterData(&clusterIn)
+
+	expectedASCSERSClusterDetails, _ := json.Marshal(
+		&entities.ASCSERSClusterDetails{
+			SID:            "NWP",
+			EnsaVersion:    models.EnsaVersion2,
+			CIBLastWritten: time.Date(2022, time.March, 9, 10, 21, 45, 0, time.UTC),
+			FencingType:    "external/sbd",
+			FencingResources: []*entities.FencingResource{
+				{
+					ID:                 "stonith-sbd",
+					Agent:              "external/sbd",
+					Status:             "active",
+					InstanceAttributes: map[string]string{"pcmk_delay_max": "15"},
+					MetaAttributes:     map[string]string{},
+				},
+			},
+			Instances: []*entities.ASCSERSInstance{
+				{
+					Role:            models.ASCSERSRoleASCS,
+					InstanceName:    "NWP_ASCS00_sapnwpas",
+					InstanceNumber:  "00",
+					VirtualHostname: "sapnwpas",
+					ResourceID:      "rsc_sap_NWP_ASCS00",
+					Node:            "vmnetweaver01",
+					Status:          "active",
+					VirtualIPs:      []string{"10.80.1.25"},
+					Filesystems: []*entities.ClusterFilesystem{
+						{
+							ResourceID: "rsc_fs_NWP_ASCS00",
+							Device:     "10.80.1.30:/NWP/ASCS",
+							Directory:  "/usr/sap/NWP/ASCS00",
+							FSType:     "nfs4",
+						},
+					},
+				},
+				{
+					Role:            models.ASCSERSRoleERS,
+					InstanceName:    "NWP_ERS10_sapnwper",
+					InstanceNumber:  "10",
+					VirtualHostname: "sapnwper",
+					ResourceID:      "rsc_sap_NWP_ERS10",
+					Node:            "vmnetweaver02",
+					Status:          "active",
+					VirtualIPs:      []string{"10.80.1.26"},
+					Filesystems: []*entities.ClusterFilesystem{
+						{
+							ResourceID: "rsc_fs_NWP_ERS10",
+							Device:     "10.80.1.30:/NWP/ERS",
+							Directory:  "/usr/sap/NWP/ERS10",
+							FSType:     "nfs4",
+						},
+					},
+				},
+			},
+			Nodes: []*entities.HANAClusterNode{
+				{
+					Name:       "vmnetweaver01",
+					Attributes: map[string]string{},
+					Resources: []*entities.ClusterResource{
+						{ID: "stonith-sbd", Type: "stonith:external/sbd", Role: "Started", Status: "active", MigrationThreshold: 5000},
+						{ID: "rsc_ip_NWP_ASCS00", Type: "ocf::heartbeat:IPaddr2", Role: "Started", Status: "active", MigrationThreshold: 5000},
+						{ID: "rsc_fs_NWP_ASCS00", Type: "ocf::heartbeat:Filesystem", Role: "Started", Status: "active", MigrationThreshold: 5000},
+						{ID: "rsc_sap_NWP_ASCS00", Type: "ocf::heartbeat:SAPInstance", Role: "Started", Status: "active", MigrationThreshold: 5000},
+					},
+					VirtualIPs: []string{"10.80.1.25"},
+					HANAStatus: models.HANAStatusUnknown,
+					Online:     true,
+				},
+				{
+					Name: "vmnetweaver02",
+					Attributes: map[string]string{
+						"runs_ers_NWP": "1",
+					},
+					Resources: []*entities.ClusterResource{
+						{ID: "rsc_ip_NWP_ERS10", Type: "ocf::heartbeat:IPaddr2", Role: "Started", Status: "active", MigrationThreshold: 5000},
+						{ID: "rsc_fs_NWP_ERS10", Type: "ocf::heartbeat:Filesystem", Role: "Started", Status: "active", MigrationThreshold: 5000},
+						{ID: "rsc_sap_NWP_ERS10", Type: "ocf::heartbeat:SAPInstance", Role: "Started", Status: "active", MigrationThreshold: 5000},
+					},
+					VirtualIPs: []string{"10.80.1.26"},
+					HANAStatus: models.HANAStatusUnknown,
+					Online:     true,
+				},
+			},
+			SBDDevices: []*entities.SBDDevice{
+				{
+					Device:          "/dev/disk/by-id/scsi-SLIO-ORG_IBLOCK_649b292b-ae9d-49a4-8002-2e602a0ab56e",
+					Status:          "healthy",
+					TimeoutWatchdog: 5,
+					TimeoutMsgwait:  10,
+					Nodes: []*entities.SBDNode{
+						{ID: 0, Name: "vmnetweaver01", Status: "clear"},
+						{ID: 1, Name: "vmnetweaver02", Status: "clear"},
+					},
+				},
+			},
+			Corosync: &entities.CorosyncDetails{
+				Transport:     "knet",
+				Token:         5000,
+				Consensus:     6000,
+				ExpectedVotes: 2,
+				TwoNode:       true,
+				Nodes: []*entities.CorosyncNode{
+					{ID: 1, Name: "vmnetweaver01", Addresses: []string{"192.168.123.9"}},
+					{ID: 2, Name: "vmnetweaver02", Addresses: []string{"192.168.123.10"}},
+				},
+				Rings: []*entities.CorosyncRing{
+					{ID: 0, Address: "192.168.123.9", Status: "node 1: localhost, node 2: connected"},
+				},
+			},
+		},
+	)
+
+	assert.EqualValues(t,
+		&entities.Cluster{
+			Name:            "netweaver_cluster",
+			ID:              "e27d313a674375b2066777a89ee346b9",
+			ClusterType:     models.ClusterTypeASCSERS,
+			SIDs:            []string{"NWP"},
+			ResourcesNumber: 7,
+			HostsNumber:     2,
+			Details:         expectedASCSERSClusterDetails,
+		}, clusterOut)
+}
+
+func TestParseEnsaVersion(t *testing.T) {
+	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_ascs_ers.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var c cluster.Cluster
+	json.Unmarshal(byteValue, &c)
+
+	assert.Equal(t, models.EnsaVersion2, parseEnsaVersion(&c))
+
+	ascs := &c.Cib.Configuration.Resources.Groups[0].Primitives[2]
+	ascs.MetaAttributes = append(ascs.MetaAttributes, cib.Attribute{Name: "migration-threshold", Value: "1"})
+
+	assert.Equal(t, models.EnsaVersion1, parseEnsaVersion(&c))
+}
+
+func TestDetectClusterType_ASCSOnly(t *testing.T) {
+	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_ascs_ers.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var c cluster.Cluster
+	json.Unmarshal(byteValue, &c)
+	c.Cib.Configuration.Resources.Groups = c.Cib.Configuration.Resources.Groups[:1]
+
+	assert.Equal(t, models.ClusterTypeUnknown, detectClusterType(&c))
+}
+
+func TestComputeDiscoveredASCSERSHealth(t *testing.T) {
+	ascs := func(node, status string) *entities.ASCSERSInstance {
+		return &entities.ASCSERSInstance{Role: models.ASCSERSRoleASCS, Node: node, Status: status}
+	}
+	ers := func(node, status string) *entities.ASCSERSInstance {
+		return &entities.ASCSERSInstance{Role: models.ASCSERSRoleERS, Node: node, Status: status}
+	}
+
+	cases := []struct {
+		instances []*entities.ASCSERSInstance
+		expected  string
+	}{
+		{[]*entities.ASCSERSInstance{ascs("node1", "active"), ers("node2", "active")}, models.HealthSummaryHealthPassing},
+		{[]*entities.ASCSERSInstance{ascs("node1", "active"), ers("node1", "active")}, models.HealthSummaryHealthWarning},
+		{[]*entities.ASCSERSInstance{ascs("node1", "active"), ers("", "")}, models.HealthSummaryHealthWarning},
+		{[]*entities.ASCSERSInstance{ascs("node1", "active")}, models.HealthSummaryHealthWarning},
+		{[]*entities.ASCSERSInstance{ascs("node1", "failed"), ers("node2", "active")}, models.HealthSummaryHealthCritical},
+		{[]*entities.ASCSERSInstance{ers("node2", "active")}, models.HealthSummaryHealthCritical},
+	}
+
+	for _, c := range cases {
+		details, _ := json.Marshal(&entities.ASCSERSClusterDetails{Instances: c.instances})
+		health, err := computeDiscoveredHealth(&entities.Cluster{
+			ClusterType: models.ClusterTypeASCSERS,
+			Details:     details,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, health)
+	}
+}
+
+func TestParseClusterNodes_States(t *testing.T) {
+	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_hana_scale_up.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var c cluster.Cluster
+	json.Unmarshal(byteValue, &c)
+
+	c.Crmmon.Nodes[1].Standby = true
+	c.Crmmon.Nodes[1].Maintenance = true
+	// Offline nodes don't report any node attribute
+	c.Crmmon.Nodes = append(c.Crmmon.Nodes, crmmon.Node{Name: "vmhana03", Unclean: true})
+
+	nodes := parseClusterNodes(&c)
+
+	assert.Equal(t, 3, len(nodes))
+	assert.Equal(t, "vmhana01", nodes[0].Name)
+	assert.True(t, nodes[0].Online)
+	assert.False(t, nodes[0].Standby)
+	assert.Equal(t, "vmhana02", nodes[1].Name)
+	assert.True(t, nodes[1].Online)
+	assert.True(t, nodes[1].Standby)
+	assert.True(t, nodes[1].Maintenance)
+	assert.Equal(t, "vmhana03", nodes[2].Name)
+	assert.False(t, nodes[2].Online)
+	assert.True(t, nodes[2].Unclean)
+	assert.Equal(t, map[string]string{}, nodes[2].Attributes)
+}
+
+func TestParseClusterNodes_FailCountsOfMovedResources(t *testing.T) {
+	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_hana_scale_up.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var c cluster.Cluster
+	json.Unmarshal(byteValue, &c)
+
+	// The exporter failed on vmhana02 before moving to vmhana01
+	history := &c.Crmmon.NodeHistory.Nodes[1]
+	history.ResourceHistory = append(history.ResourceHistory, history.ResourceHistory[0])
+	history.ResourceHistory[2].Name = "rsc_exporter_PRD_HDB00"
+	history.ResourceHistory[2].FailCount = 3
+	history.ResourceHistory[2].MigrationThreshold = 3
+
+	nodes := parseClusterNodes(&c)
+
+	exporter := findNodeResource(nodes[0].Resources, "rsc_exporter_PRD_HDB00")
+	assert.Equal(t, "active", exporter.Status)
+	assert.Equal(t, 0, exporter.FailCount)
+
+	exporter = findNodeResource(nodes[1].Resources, "rsc_exporter_PRD_HDB00")
+	assert.Equal(t, &entities.ClusterResource{
+		ID:                 "rsc_exporter_PRD_HDB00",
+		Type:               "systemd:prometheus-hanadb_exporter@PRD_HDB00",
+		Role:               "Stopped",
+		FailCount:          3,
+		MigrationThreshold: 3,
+	}, exporter)
+
+	// Resources without fail count are listed only on the nodes running them
+	assert.Nil(t, findNodeResource(nodes[1].Resources, "stonith-sbd"))
+
+	details, _ := json.Marshal(&entities.HANAClusterDetails{Nodes: nodes})
+	health, err := computeResourcesHealth(&entities.Cluster{Details: details})
+	assert.NoError(t, err)
+	assert.Equal(t, models.HealthSummaryHealthWarning, health)
+}
+
+func TestParseClusterStoppedResources_Failed(t *testing.T) {
+	c := &cluster.Cluster{}
+	c.Crmmon.Resources = []crmmon.Resource{
+		{Id: "rsc_stopped", Role: "Stopped"},
+		{Id: "rsc_failed", Role: "Stopped", Failed: true},
+		{Id: "rsc_running", Role: "Started", Active: true, NodesRunningOn: 1},
+	}
+
+	assert.Equal(t, []*entities.ClusterResource{
+		{ID: "rsc_stopped"},
+		{ID: "rsc_failed", Status: "failed"},
+	}, parseClusterStoppedResources(c))
+}
+
+func TestComputeNodesHealth(t *testing.T) {
+	cases := []struct {
+		nodes    []*entities.HANAClusterNode
+		expected string
+	}{
+		{
+			[]*entities.HANAClusterNode{{Online: true}, {Online: true}},
+			models.HealthSummaryHealthPassing,
+		},
+		{
+			[]*entities.HANAClusterNode{{Online: true}, {Online: true, Maintenance: true}},
+			models.HealthSummaryHealthPassing,
+		},
+		{
+			[]*entities.HANAClusterNode{{Online: true}, {Online: true, Standby: true}},
+			models.HealthSummaryHealthWarning,
+		},
+		{
+			[]*entities.HANAClusterNode{{Online: true, Standby: true}, {Online: false}},
+			models.HealthSummaryHealthCritical,
+		},
+		{
+			[]*entities.HANAClusterNode{{Online: true}, {Online: true, Unclean: true}},
+			models.HealthSummaryHealthCritical,
+		},
+		{
+			nil,
+			models.HealthSummaryHealthUnknown,
+		},
+	}
+
+	for _, c := range cases {
+		details, _ := json.Marshal(&entities.HANAClusterDetails{Nodes: c.nodes})
+		health, err := computeNodesHealth(&entities.Cluster{
+			ClusterType: models.ClusterTypeHANAScaleUp,
+			Details:     details,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, health)
+	}
+}
+
+func TestParseResourceStatus(t *testing.T) {
+	assert.Equal(t, "active", parseResourceStatus(crmmon.Resource{Active: true}))
+	assert.Equal(t, "failed", parseResourceStatus(crmmon.Resource{Active: true, Failed: true}))
+	assert.Equal(t, "blocked", parseResourceStatus(crmmon.Resource{Active: true, Blocked: true}))
+	assert.Equal(t, "orphaned", parseResourceStatus(crmmon.Resource{Orphaned: true}))
+	assert.Equal(t, "failure_ignored", parseResourceStatus(crmmon.Resource{Active: true, FailureIgnored: true}))
+	assert.Equal(t, "", parseResourceStatus(crmmon.Resource{}))
+}
+
+func TestComputeResourcesHealth(t *testing.T) {
+	node := func(resources ...*entities.ClusterResource) *entities.HANAClusterNode {
+		return &entities.HANAClusterNode{Online: true, Resources: resources}
+	}
+
+	cases := []struct {
+		details  *entities.HANAClusterDetails
+		expected string
+	}{
+		{
+			&entities.HANAClusterDetails{
+				Nodes: []*entities.HANAClusterNode{
+					node(&entities.ClusterResource{Status: "active", FailCount: 1, MigrationThreshold: 5000}),
+				},
+			},
+			models.HealthSummaryHealthPassing,
+		},
+		{
+			&entities.HANAClusterDetails{
+				Nodes: []*entities.HANAClusterNode{
+					node(&entities.ClusterResource{Status: "active", FailCount: 0, MigrationThreshold: 1}),
+					node(&entities.ClusterResource{Status: "active", FailCount: 2, MigrationThreshold: 3}),
+				},
+			},
+			models.HealthSummaryHealthWarning,
+		},
+		{
+			&entities.HANAClusterDetails{
+				Nodes: []*entities.HANAClusterNode{
+					node(&entities.ClusterResource{Status: "active", FailCount: 3, MigrationThreshold: 3}),
+				},
+			},
+			models.HealthSummaryHealthWarning,
+		},
+		{
+			&entities.HANAClusterDetails{
+				Nodes: []*entities.HANAClusterNode{
+					node(&entities.ClusterResource{Status: "active", FailCount: 2, MigrationThreshold: 3}),
+					node(&entities.ClusterResource{Status: "failed"}),
+				},
+			},
+			models.HealthSummaryHealthCritical,
+		},
+		{
+			&entities.HANAClusterDetails{
+				StoppedResources: []*entities.ClusterResource{{Status: "failed"}},
+			},
+			models.HealthSummaryHealthCritical,
+		},
+		{
+			&entities.HANAClusterDetails{
+				Nodes: []*entities.HANAClusterNode{
+					node(&entities.ClusterResource{Role: "Stopped", FailCount: 3, MigrationThreshold: 3}),
+				},
+			},
+			models.HealthSummaryHealthWarning,
+		},
+		{
+			&entities.HANAClusterDetails{
+				StoppedResources: []*entities.ClusterResource{{Status: "blocked"}},
+			},
+			models.HealthSummaryHealthCritical,
+		},
+		{
+			&entities.HANAClusterDetails{
+				StoppedResources: []*entities.ClusterResource{{Status: "orphaned"}},
+			},
+			models.HealthSummaryHealthCritical,
+		},
+	}
+
+	for _, c := range cases {
+		details, _ := json.Marshal(c.details)
+		health, err := computeResourcesHealth(&entities.Cluster{Details: details})
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, health)
+	}
+}
+
+func TestComputeSBDHealth(t *testing.T) {
+	nodes := []*entities.HANAClusterNode{{Name: "node01"}, {Name: "node02"}}
+	sbdNodes := []*entities.SBDNode{{ID: 0, Name: "node01", Status: "clear"}, {ID: 1, Name: "node02", Status: "clear"}}
+
+	cases := []struct {
+		devices        []*entities.SBDDevice
+		expectedHealth string
+		expectedReason string
+	}{
+		{
+			nil,
+			models.HealthSummaryHealthPassing,
+			"",
+		},
+		{
+			[]*entities.SBDDevice{
+				{Device: "/dev/sda", Status: "healthy", TimeoutWatchdog: 5, TimeoutMsgwait: 10, Nodes: sbdNodes},
+				{Device: "/dev/sdb", Status: "healthy", TimeoutWatchdog: 5, TimeoutMsgwait: 10, Nodes: sbdNodes},
+			},
+			models.HealthSummaryHealthPassing,
+			"",
+		},
+		{
+			[]*entities.SBDDevice{
+				{Device: "/dev/sda", Status: "healthy", TimeoutWatchdog: 5, TimeoutMsgwait: 10, Nodes: sbdNodes[:1]},
+			},
+			models.HealthSummaryHealthWarning,
+			"node node02 is missing from the slots of the SBD device /dev/sda",
+		},
+		{
+			[]*entities.SBDDevice{
+				{Device: "/dev/sda", Status: "healthy", TimeoutWatchdog: 15, TimeoutMsgwait: 20, Nodes: sbdNodes},
+			},
+			models.HealthSummaryHealthWarning,
+			"SBD device /dev/sda msgwait timeout (20s) should be at least twice the watchdog timeout (15s)",
+		},
+		{
+			[]*entities.SBDDevice{
+				{Device: "/dev/sda", Status: "healthy", TimeoutWatchdog: 5, TimeoutMsgwait: 10, Nodes: sbdNodes},
+				{Device: "/dev/sdb", Status: "healthy", TimeoutWatchdog: 10, TimeoutMsgwait: 20, Nodes: sbdNodes},
+			},
+			models.HealthSummaryHealthWarning,
+			"SBD devices /dev/sda and /dev/sdb have different watchdog or msgwait timeouts",
+		},
+		{
+			[]*entities.SBDDevice{
+				{Device: "/dev/sda", Status: "healthy", TimeoutWatchdog: 5, TimeoutMsgwait: 10, Nodes: sbdNodes[:1]},
+				{Device: "/dev/sdb", Status: "unhealthy"},
+			},
+			models.HealthSummaryHealthCritical,
+			"node node02 is missing from the slots of the SBD device /dev/sda; SBD device /dev/sdb is unhealthy",
+		},
+	}
+
+	for _, c := range cases {
+		details, _ := json.Marshal(&entities.HANAClusterDetails{Nodes: nodes, SBDDevices: c.devices})
+		health, reason, err := computeSBDHealth(&entities.Cluster{Details: details})
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.expectedHealth, health)
+		assert.Equal(t, c.expectedReason, reason)
+	}
+}
+
+func TestParseFencingResources(t *testing.T) {
+	c := &cluster.Cluster{}
+	c.Cib.Configuration.Resources.Primitives = []cib.Primitive{
+		{
+			Id:    "rsc_st_azure",
+			Class: "stonith",
+			Type:  "fence_azure_arm",
+			InstanceAttributes: []cib.Attribute{
+				{Name: "login", Value: "app-id"},
+				{Name: "password", Value: "app-secret"},
+				{Name: "pcmk_monitor_retries", Value: "4"},
+			},
+			MetaAttributes: []cib.Attribute{
+				{Name: "target-role", Value: "Stopped"},
+			},
+		},
+		{
+			Id:    "rsc_dummy",
+			Class: "ocf",
+			Type:  "Dummy",
+		},
+	}
+	c.Cib.Configuration.Resources.Groups = []cib.Group{
+		{
+			Id: "grp_fencing",
+			Primitives: []cib.Primitive{
+				{
+					Id:    "rsc_st_aws",
+					Class: "stonith",
+					Type:  "fence_aws",
+					InstanceAttributes: []cib.Attribute{
+						{Name: "secret_key", Value: "aws-secret"},
+					},
+				},
+			},
+		},
+	}
+	c.Crmmon.Groups = []crmmon.Group{
+		{
+			Id:        "grp_fencing",
+			Resources: []crmmon.Resource{{Id: "rsc_st_aws", Active: true, NodesRunningOn: 1}},
+		},
+	}
+
+	assert.Equal(t, []*entities.FencingResource{
+		{
+			ID:       "rsc_st_azure",
+			Agent:    "fence_azure_arm",
+			Status:   "stopped",
+			Disabled: true,
+			InstanceAttributes: map[string]string{
+				"login":                "app-id",
+				"password":             cluster.RedactedValue,
+				"pcmk_monitor_retries": "4",
+			},
+			MetaAttributes: map[string]string{"target-role": "Stopped"},
+		},
+		{
+			ID:                 "rsc_st_aws",
+			Agent:              "fence_aws",
+			Status:             "active",
+			InstanceAttributes: map[string]string{"secret_key": cluster.RedactedValue},
+			MetaAttributes:     map[string]string{},
+		},
+	}, parseFencingResources(c))
+}
+
+func TestComputeFencingHealth(t *testing.T) {
+	cases := []struct {
+		fencingResources []*entities.FencingResource
+		expectedHealth   string
+		expectedReason   string
+	}{
+		{
+			nil,
+			models.HealthSummaryHealthPassing,
+			"",
+		},
+		{
+			[]*entities.FencingResource{{ID: "rsc_st_azure", Status: "active"}},
+			models.HealthSummaryHealthPassing,
+			"",
+		},
+		{
+			[]*entities.FencingResource{
+				{ID: "rsc_st_azure", Status: "stopped", Disabled: true},
+				{ID: "rsc_st_aws", Status: "failed"},
+			},
+			models.HealthSummaryHealthCritical,
+			"stonith resource rsc_st_azure is disabled; stonith resource rsc_st_aws is failed",
+		},
+	}
+
+	for _, c := range cases {
+		details, _ := json.Marshal(&entities.GenericClusterDetails{FencingResources: c.fencingResources})
+		health, reason, err := computeFencingHealth(&entities.Cluster{Details: details})
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.expectedHealth, health)
+		assert.Equal(t, c.expectedReason, reason)
+	}
+}
+
+func TestParseLocationConstraints(t *testing.T) {
+	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_hana_scale_up.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var c cluster.Cluster
+	json.Unmarshal(byteValue, &c)
+
+	constraints := `{
+		"RscLocations": [
+			{"Id": "cli-prefer-msl_SAPHana_PRD_HDB00", "Node": "vmhana02", "Resource": "msl_SAPHana_PRD_HDB00", "Role": "Started", "Score": "INFINITY"},
+			{"Id": "cli-ban-rsc_ip_PRD_HDB00-on-vmhana01", "Node": "vmhana01", "Resource": "rsc_ip_PRD_HDB00", "Role": "", "Score": "-INFINITY"},
+			{"Id": "loc_prefer_vmhana01", "Node": "vmhana01", "Resource": "rsc_ip_PRD_HDB00", "Role": "", "Score": "100"}
+		]
+	}`
+	json.Unmarshal([]byte(constraints), &c.Cib.Configuration.Constraints)
+
+	assert.Equal(t, []*entities.LocationConstraint{
+		{
+			ID:       "cli-prefer-msl_SAPHana_PRD_HDB00",
+			Type:     models.LocationConstraintTypeMove,
+			Resource: "msl_SAPHana_PRD_HDB00",
+			Node:     "vmhana02",
+			Role:     "Started",
+			Score:    "INFINITY",
+		},
+		{
+			ID:       "cli-ban-rsc_ip_PRD_HDB00-on-vmhana01",
+			Type:     models.LocationConstraintTypeBan,
+			Resource: "rsc_ip_PRD_HDB00",
+			Node:     "vmhana01",
+			Score:    "-INFINITY",
+		},
+	}, parseLocationConstraints(&c))
+}
+
+func TestComputeLocationConstraintsHealth(t *testing.T) {
+	details, _ := json.Marshal(&entities.HANAClusterDetails{})
+	health, err := computeLocationConstraintsHealth(&entities.Cluster{Details: details})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.HealthSummaryHealthPassing, health)
+
+	details, _ = json.Marshal(&entities.ASCSERSClusterDetails{
+		LocationConstraints: []*entities.LocationConstraint{
+			{ID: "cli-ban-rsc_sap_NWP_ASCS00-on-vmnetweaver01", Type: models.LocationConstraintTypeBan},
+		},
+	})
+	health, err = computeLocationConstraintsHealth(&entities.Cluster{Details: details})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.HealthSummaryHealthWarning, health)
+}
+
 func TestTransformClusterData_Unknown(t *testing.T) {
 	jsonFile, err := os.Open("./test/fixtures/discovery/cluster/cluster_discovery_unknown.json")
 	if err != nil {
@@ -226,12 +1069,16 @@ func TestTransformClusterData_Unknown(t *testing.T) {
 	json.Unmarshal(byteValue, &clusterIn)
 	clusterOut, _ := transformClusterData(&clusterIn)
 
+	expectedGenericClusterDetails, _ := json.Marshal(
+		&entities.GenericClusterDetails{},
+	)
+
 	assert.EqualValues(t,
 		&entities.Cluster{
 			Name:        "test_cluster",
 			ID:          "test_id",
 			ClusterType: models.ClusterTypeUnknown,
-			Details:     datatypes.JSON{},
+			Details:     expectedGenericClusterDetails,
 		}, clusterOut)
 }
 
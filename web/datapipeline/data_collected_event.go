@@ -12,11 +12,15 @@ const (
 	HostDiscovery         = "host_discovery"
 	SubscriptionDiscovery = "subscription_discovery"
 	CloudDiscovery        = "cloud_discovery"
+	SystemdDiscovery      = "systemd_discovery"
+	TuningDiscovery       = "tuning_discovery"
 )
 
 type DataCollectedEvent struct {
-	ID            int64
-	CreatedAt     time.Time
+	ID        int64
+	CreatedAt time.Time
+	// Time of the discovery, it differs from CreatedAt when the agent replays the data it could not publish
+	DiscoveredAt  time.Time      `json:"discovered_at"`
 	AgentID       string         `json:"agent_id" binding:"required"`
 	DiscoveryType string         `json:"discovery_type" binding:"required"`
 	Payload       datatypes.JSON `json:"payload" binding:"required"`
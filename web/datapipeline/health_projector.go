@@ -2,7 +2,6 @@ package datapipeline
 
 import (
 	"encoding/json"
-	"errors"
 
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
@@ -12,33 +11,87 @@ import (
 )
 
 func ProjectHealth(db *gorm.DB, healthID, healthType, healthValue string) error {
-	var healthState entities.HealthState
-	var partialHealths map[string]string
+	return ProjectHealthWithReason(db, healthID, healthType, healthValue, "")
+}
 
-	err := db.Where("id = ?", healthID).First(&healthState).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			healthState.ID = healthID
-			partialHealths = make(map[string]string)
-		} else {
+// ProjectHealthWithReason projects a partial health along with the reason it has its value,
+// an empty reason clears the previous one.
+// Several projectors project partial healths of the same resource concurrently,
+// so the health state is locked while they are merged
+func ProjectHealthWithReason(db *gorm.DB, healthID, healthType, healthValue, reason string) error {
+	return projectHealth(db, healthID, healthType, healthValue, reason, nil)
+}
+
+// ProjectHealthReplacing projects a partial health removing the ones it replaces,
+// so a partial health which no longer applies to the resource doesn't affect its overall health anymore
+func ProjectHealthReplacing(db *gorm.DB, healthID, healthType, healthValue string, replacedHealthTypes ...string) error {
+	return projectHealth(db, healthID, healthType, healthValue, "", replacedHealthTypes)
+}
+
+func projectHealth(db *gorm.DB, healthID, healthType, healthValue, reason string, replacedHealthTypes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// The health state is created first if missing, so there is always a row to lock
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.HealthState{
+			ID:             healthID,
+			Health:         models.HealthSummaryHealthUnknown,
+			PartialHealths: datatypes.JSON("{}"),
+			Reasons:        datatypes.JSON("{}"),
+		}).Error
+		if err != nil {
 			return err
 		}
-	} else {
-		err = json.Unmarshal(healthState.PartialHealths, &partialHealths)
+
+		var healthState entities.HealthState
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", healthID).First(&healthState).Error
 		if err != nil {
 			return err
 		}
-	}
 
-	partialHealths[healthType] = healthValue
+		var partialHealths map[string]string
+		var reasons map[string]string
+
+		if len(healthState.PartialHealths) > 0 {
+			err = json.Unmarshal(healthState.PartialHealths, &partialHealths)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(healthState.Reasons) > 0 {
+			err = json.Unmarshal(healthState.Reasons, &reasons)
+			if err != nil {
+				return err
+			}
+		}
+
+		if partialHealths == nil {
+			partialHealths = make(map[string]string)
+		}
+
+		if reasons == nil {
+			reasons = make(map[string]string)
+		}
+
+		for _, replacedHealthType := range replacedHealthTypes {
+			delete(partialHealths, replacedHealthType)
+			delete(reasons, replacedHealthType)
+		}
+
+		partialHealths[healthType] = healthValue
+		if reason != "" {
+			reasons[healthType] = reason
+		} else {
+			delete(reasons, healthType)
+		}
 
-	partialHealthsJson, _ := json.Marshal(partialHealths)
-	healthState.Health = computeOverallHealth(partialHealths)
-	healthState.PartialHealths = (datatypes.JSON)(partialHealthsJson)
+		partialHealthsJson, _ := json.Marshal(partialHealths)
+		reasonsJson, _ := json.Marshal(reasons)
+		healthState.Health = computeOverallHealth(partialHealths)
+		healthState.PartialHealths = (datatypes.JSON)(partialHealthsJson)
+		healthState.Reasons = (datatypes.JSON)(reasonsJson)
 
-	return db.Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(healthState).Error
+		return tx.Save(&healthState).Error
+	})
 }
 
 func computeOverallHealth(partialHealths map[string]string) string {
@@ -2,6 +2,8 @@ package datapipeline
 
 import (
 	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -104,6 +106,94 @@ func (suite *HealthProjectorTestSuite) Test_ProjectHealth_New() {
 	)
 }
 
+func (suite *HealthProjectorTestSuite) Test_ProjectHealthWithReason() {
+	err := ProjectHealthWithReason(suite.tx, "1", "my_health_value", "critical", "something is broken")
+	suite.NoError(err)
+
+	err = ProjectHealthWithReason(suite.tx, "1", "my_other_health", "warning", "something is odd")
+	suite.NoError(err)
+
+	var health entities.HealthState
+	suite.tx.First(&health)
+
+	var reasons map[string]string
+	json.Unmarshal(health.Reasons, &reasons)
+
+	suite.Equal("critical", health.Health)
+	suite.Equal(
+		map[string]string{
+			"my_health_value": "something is broken",
+			"my_other_health": "something is odd",
+		},
+		reasons,
+	)
+
+	err = ProjectHealth(suite.tx, "1", "my_health_value", "passing")
+	suite.NoError(err)
+
+	var updatedHealth entities.HealthState
+	suite.tx.First(&updatedHealth)
+
+	var updatedReasons map[string]string
+	json.Unmarshal(updatedHealth.Reasons, &updatedReasons)
+
+	suite.Equal("warning", updatedHealth.Health)
+	suite.Equal(map[string]string{"my_other_health": "something is odd"}, updatedReasons)
+}
+
+func (suite *HealthProjectorTestSuite) Test_ProjectHealthReplacing() {
+	err := ProjectHealthWithReason(suite.tx, "1", "my_old_health", "critical", "something is broken")
+	suite.NoError(err)
+
+	err = ProjectHealth(suite.tx, "1", "my_other_health", "passing")
+	suite.NoError(err)
+
+	err = ProjectHealthReplacing(suite.tx, "1", "my_new_health", "passing", "my_old_health", "my_new_health")
+	suite.NoError(err)
+
+	var health entities.HealthState
+	suite.tx.First(&health)
+
+	var partialHealths map[string]string
+	json.Unmarshal(health.PartialHealths, &partialHealths)
+	var reasons map[string]string
+	json.Unmarshal(health.Reasons, &reasons)
+
+	suite.Equal("passing", health.Health)
+	suite.Equal(map[string]string{"my_other_health": "passing", "my_new_health": "passing"}, partialHealths)
+	suite.Empty(reasons)
+}
+
+// Test_ProjectHealth_Concurrent tests the partial healths projected concurrently by different projectors are all kept
+func (suite *HealthProjectorTestSuite) Test_ProjectHealth_Concurrent() {
+	defer suite.db.Where("id = ?", "concurrent").Delete(&entities.HealthState{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := suite.db.Transaction(func(tx *gorm.DB) error {
+				return ProjectHealthWithReason(tx, "concurrent", fmt.Sprintf("health_%d", i), "warning", fmt.Sprintf("reason %d", i))
+			})
+			suite.NoError(err)
+		}(i)
+	}
+	wg.Wait()
+
+	var health entities.HealthState
+	suite.db.Where("id = ?", "concurrent").First(&health)
+
+	var partialHealths map[string]string
+	json.Unmarshal(health.PartialHealths, &partialHealths)
+	var reasons map[string]string
+	json.Unmarshal(health.Reasons, &reasons)
+
+	suite.Equal("warning", health.Health)
+	suite.Len(partialHealths, 10)
+	suite.Len(reasons, 10)
+}
+
 func (suite *HealthProjectorTestSuite) Test_ComputeOverallHealth_Passing() {
 	health := computeOverallHealth(
 		map[string]string{
@@ -2,20 +2,34 @@ package datapipeline
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
+	"path"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/trento-project/trento/internal"
 	"github.com/trento-project/trento/internal/cloud"
 	"github.com/trento-project/trento/internal/cluster"
 	"github.com/trento-project/trento/internal/hosts"
 	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+const (
+	partialScheduledEventsHealth = "azure_scheduled_events"
+	partialIdentityHealth        = "identity"
+
+	azureEventStatusCompleted = "Completed"
+)
+
 func NewHostsProjector(db *gorm.DB) *projector {
 	hostsProjector := NewProjector("hosts", db)
 
@@ -43,12 +57,22 @@ func hostsProjector_HostDiscoveryHandler(dataCollectedEvent *DataCollectedEvent,
 		AgentVersion: discoveredHost.AgentVersion,
 	}
 
-	return storeHost(db, host,
+	err := storeHost(db, host,
 		"name",
 		"ip_addresses",
 		"agent_version",
 		"ssh_address",
 	)
+	if err != nil {
+		return err
+	}
+
+	reportedAt := dataCollectedEvent.DiscoveredAt
+	if reportedAt.IsZero() {
+		reportedAt = time.Now()
+	}
+
+	return projectHostIdentity(db, &host, reportedAt)
 }
 
 func hostsProjector_CloudDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
@@ -73,7 +97,29 @@ func hostsProjector_CloudDiscoveryHandler(dataCollectedEvent *DataCollectedEvent
 		CloudData:     (datatypes.JSON)(jsonCloudData),
 	}
 
-	return storeHost(db, host, "cloud_provider", "cloud_data")
+	err = storeHost(db, host, "cloud_provider", "cloud_data")
+	if err != nil {
+		return err
+	}
+
+	scheduledEventsHealth := models.HealthSummaryHealthPassing
+	if azureCloudData, ok := parsedCloudData.(*entities.AzureCloudData); ok && len(azureCloudData.ScheduledEvents) > 0 {
+		scheduledEventsHealth = models.HealthSummaryHealthWarning
+	}
+
+	err = ProjectHealth(db, dataCollectedEvent.AgentID, partialScheduledEventsHealth, scheduledEventsHealth)
+	if err != nil {
+		log.Errorf("can't project health: %s", err)
+		return err
+	}
+
+	var storedHost entities.Host
+	err = db.Select("cluster_id").Where("agent_id = ?", dataCollectedEvent.AgentID).First(&storedHost).Error
+	if err != nil {
+		return err
+	}
+
+	return projectClusterScheduledEventsHealth(db, storedHost.ClusterID)
 }
 
 func hostsProjector_ClusterDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
@@ -92,7 +138,12 @@ func hostsProjector_ClusterDiscoveryHandler(dataCollectedEvent *DataCollectedEve
 		ClusterType: detectClusterType(&discoveredCluster),
 	}
 
-	return storeHost(db, host, "cluster_id", "cluster_name", "cluster_type")
+	err := storeHost(db, host, "cluster_id", "cluster_name", "cluster_type")
+	if err != nil {
+		return err
+	}
+
+	return projectClusterScheduledEventsHealth(db, discoveredCluster.Id)
 }
 
 func storeHost(db *gorm.DB, host entities.Host, updateColumns ...string) error {
@@ -104,6 +155,121 @@ func storeHost(db *gorm.DB, host entities.Host, updateColumns ...string) error {
 	}).Create(&host).Error
 }
 
+// projectHostIdentity records the identity reported by the agent and flags an identity conflict when the agent ID
+// switches back to a hostname it reported shortly before, as it happens with hosts cloned with the same machine ID.
+// A single change of hostname is a legit reconfiguration of the host and is not flagged.
+// The IP addresses are not part of the identity, as the cluster virtual IPs move between the nodes on failover
+func projectHostIdentity(db *gorm.DB, host *entities.Host, reportedAt time.Time) error {
+	since := reportedAt.Add(-entities.IdentityConflictWindow)
+
+	err := db.Where("agent_id = ? AND last_reported_at < ?", host.AgentID, since).Delete(&entities.HostIdentity{}).Error
+	if err != nil {
+		return err
+	}
+
+	var identities []*entities.HostIdentity
+	err = db.Where("agent_id = ?", host.AgentID).Order("last_reported_at DESC").Find(&identities).Error
+	if err != nil {
+		return err
+	}
+
+	identity := &entities.HostIdentity{
+		AgentID:        host.AgentID,
+		Identity:       host.Name,
+		Hostname:       host.Name,
+		IPAddresses:    host.IPAddresses,
+		LastReportedAt: reportedAt,
+	}
+
+	conflict := false
+	if len(identities) > 0 && identities[0].Identity != identity.Identity {
+		for _, reported := range identities[1:] {
+			if reported.Identity == identity.Identity {
+				conflict = true
+				break
+			}
+		}
+	}
+
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "agent_id"}, {Name: "identity"}},
+		DoUpdates: clause.AssignmentColumns([]string{"ip_addresses", "last_reported_at"}),
+	}).Create(identity).Error
+	if err != nil {
+		return err
+	}
+
+	if conflict {
+		log.Warnf("Agent ID %s is reported by different hosts, it might have been cloned with the same machine ID", host.AgentID)
+
+		err = db.Model(&entities.Host{}).Where("agent_id = ?", host.AgentID).Update("identity_conflict_at", reportedAt).Error
+		if err != nil {
+			return err
+		}
+
+		var hostnames []string
+		for _, reported := range append(identities, identity) {
+			if !internal.Contains(hostnames, reported.Hostname) {
+				hostnames = append(hostnames, reported.Hostname)
+			}
+		}
+		sort.Strings(hostnames)
+
+		reason := fmt.Sprintf("Identity conflict, the agent ID is reported by: %s", strings.Join(hostnames, ", "))
+		return ProjectHealthWithReason(db, host.AgentID, partialIdentityHealth, models.HealthSummaryHealthCritical, reason)
+	}
+
+	var storedHost entities.Host
+	err = db.Select("identity_conflict_at").Where("agent_id = ?", host.AgentID).First(&storedHost).Error
+	if err != nil {
+		return err
+	}
+
+	if storedHost.IdentityConflictAt != nil && storedHost.IdentityConflictAt.After(since) {
+		return nil
+	}
+
+	return ProjectHealth(db, host.AgentID, partialIdentityHealth, models.HealthSummaryHealthPassing)
+}
+
+// projectClusterScheduledEventsHealth warns about the cluster when any of its hosts has pending scheduled events
+func projectClusterScheduledEventsHealth(db *gorm.DB, clusterID string) error {
+	if clusterID == "" {
+		return nil
+	}
+
+	var healthStates []entities.HealthState
+	err := db.
+		Where("id IN (?)", db.Model(&entities.Host{}).Select("agent_id").Where("cluster_id = ?", clusterID)).
+		Find(&healthStates).
+		Error
+	if err != nil {
+		return err
+	}
+
+	health := models.HealthSummaryHealthPassing
+	for _, healthState := range healthStates {
+		var partialHealths map[string]string
+		err = json.Unmarshal(healthState.PartialHealths, &partialHealths)
+		if err != nil {
+			return err
+		}
+
+		if partialHealths[partialScheduledEventsHealth] == models.HealthSummaryHealthWarning {
+			health = models.HealthSummaryHealthWarning
+			break
+		}
+	}
+
+	err = ProjectHealth(db, clusterID, partialScheduledEventsHealth, health)
+	if err != nil {
+		log.Errorf("can't project health: %s", err)
+		return err
+	}
+
+	return nil
+}
+
 // filterIPAddresses filters out non-IPv4, loopback or invalid IP addresses
 func filterIPAddresses(ipAddresses []string) []string {
 	var filtered []string
@@ -118,11 +284,17 @@ func filterIPAddresses(ipAddresses []string) []string {
 	return filtered
 }
 
-func parseCloudData(provider string, metadata interface{}) *entities.AzureCloudData {
+func parseCloudData(provider string, metadata interface{}) interface{} {
 	switch provider {
-	case "azure":
+	case cloud.Azure:
 		cloudData := parseAzureCloudData(metadata)
 		return &cloudData
+	case cloud.Aws:
+		cloudData := parseAWSCloudData(metadata)
+		return &cloudData
+	case cloud.Gcp:
+		cloudData := parseGCPCloudData(metadata)
+		return &cloudData
 	default:
 		return nil
 	}
@@ -146,5 +318,90 @@ func parseAzureCloudData(metadata interface{}) entities.AzureCloudData {
 		Offer:           azureMetadata.Compute.Offer,
 		SKU:             azureMetadata.Compute.Sku,
 		AdminUsername:   azureMetadata.Compute.OsProfile.AdminUserName,
+		ScheduledEvents: parseAzureScheduledEvents(azureMetadata.Compute.Name, azureMetadata.ScheduledEvents),
+	}
+}
+
+// parseAzureScheduledEvents returns the pending scheduled events impacting the given VM
+func parseAzureScheduledEvents(vmName string, scheduledEvents []*cloud.ScheduledEvent) []entities.AzureScheduledEvent {
+	var events []entities.AzureScheduledEvent
+	for _, event := range scheduledEvents {
+		if event.EventStatus == azureEventStatusCompleted {
+			continue
+		}
+
+		if len(event.Resources) > 0 && !internal.Contains(event.Resources, vmName) {
+			continue
+		}
+
+		events = append(events, entities.AzureScheduledEvent{
+			ID:                event.EventId,
+			Type:              event.EventType,
+			Status:            event.EventStatus,
+			NotBefore:         event.NotBefore,
+			Description:       event.Description,
+			DurationInSeconds: event.DurationInSeconds,
+		})
+	}
+
+	return events
+}
+
+func parseAWSCloudData(metadata interface{}) entities.AWSCloudData {
+	var awsMetadata cloud.AwsMetadata
+
+	err := mapstructure.Decode(metadata, &awsMetadata)
+	if err != nil {
+		log.Errorf("can't decode aws metadata: %s", err)
+		return entities.AWSCloudData{}
+	}
+
+	var ebsVolumes []string
+	for _, volume := range awsMetadata.EbsVolumes {
+		ebsVolumes = append(ebsVolumes, volume.Device)
+	}
+
+	return entities.AWSCloudData{
+		InstanceID:       awsMetadata.InstanceId,
+		InstanceType:     awsMetadata.InstanceType,
+		AccountID:        awsMetadata.AccountId,
+		Region:           awsMetadata.Region,
+		AvailabilityZone: awsMetadata.AvailabilityZone,
+		ImageID:          awsMetadata.ImageId,
+		PrivateIP:        awsMetadata.PrivateIp,
+		EBSVolumes:       ebsVolumes,
+	}
+}
+
+func parseGCPCloudData(metadata interface{}) entities.GCPCloudData {
+	var gcpMetadata cloud.GcpMetadata
+
+	err := mapstructure.Decode(metadata, &gcpMetadata)
+	if err != nil {
+		log.Errorf("can't decode gcp metadata: %s", err)
+		return entities.GCPCloudData{}
+	}
+
+	var networks []string
+	for _, networkInterface := range gcpMetadata.Instance.NetworkInterfaces {
+		networks = append(networks, gcpResourceName(networkInterface.Network))
+	}
+
+	return entities.GCPCloudData{
+		InstanceName: gcpMetadata.Instance.Name,
+		ProjectID:    gcpMetadata.Project.ProjectId,
+		Zone:         gcpResourceName(gcpMetadata.Instance.Zone),
+		MachineType:  gcpResourceName(gcpMetadata.Instance.MachineType),
+		Image:        gcpResourceName(gcpMetadata.Instance.Image),
+		DisksNumber:  len(gcpMetadata.Instance.Disks),
+		Networks:     networks,
+	}
+}
+
+// gcpResourceName returns the name of a resource out of its partial URL, e.g. projects/123456/zones/europe-west1-b
+func gcpResourceName(url string) string {
+	if url == "" {
+		return ""
 	}
+	return path.Base(url)
 }
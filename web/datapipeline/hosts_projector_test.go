@@ -3,9 +3,11 @@ package datapipeline
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/agent/discovery/mocks"
+	"github.com/trento-project/trento/internal/cloud"
 	_ "github.com/trento-project/trento/test"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
@@ -26,11 +28,11 @@ func TestHostsProjectorTestSuite(t *testing.T) {
 func (suite *HostsProjectorTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&Subscription{}, &entities.Host{})
+	suite.db.AutoMigrate(&Subscription{}, &entities.Host{}, &entities.HealthState{}, &entities.HostIdentity{})
 }
 
 func (suite *HostsProjectorTestSuite) TearDownSuite() {
-	suite.db.Migrator().DropTable(Subscription{}, entities.Host{})
+	suite.db.Migrator().DropTable(Subscription{}, entities.Host{}, entities.HealthState{}, entities.HostIdentity{})
 }
 
 func (suite *HostsProjectorTestSuite) SetupTest() {
@@ -67,6 +69,107 @@ func (s *HostsProjectorTestSuite) Test_HostDiscoveryHandler() {
 	s.Equal("", projectedHost.ClusterType)
 }
 
+func (s *HostsProjectorTestSuite) discoverHost(hostname string, ipAddresses []string, discoveredAt time.Time) {
+	discoveredHostMock := mocks.NewDiscoveredHostMock()
+	discoveredHostMock.HostName = hostname
+	discoveredHostMock.HostIpAddresses = ipAddresses
+
+	requestBody, _ := json.Marshal(discoveredHostMock)
+
+	err := hostsProjector_HostDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: HostDiscovery,
+		DiscoveredAt:  discoveredAt,
+		Payload:       requestBody,
+	}, s.tx)
+	s.NoError(err)
+}
+
+// Test_HostDiscoveryHandler_IdentityConflict tests an agent ID reported in turn by different hosts is flagged
+func (s *HostsProjectorTestSuite) Test_HostDiscoveryHandler_IdentityConflict() {
+	now := time.Now()
+
+	s.discoverHost("host1", []string{"10.1.1.4"}, now.Add(-20*time.Minute))
+	s.discoverHost("host1-clone", []string{"10.1.1.5"}, now.Add(-10*time.Minute))
+	s.discoverHost("host1", []string{"10.1.1.4"}, now)
+
+	var projectedHost entities.Host
+	s.tx.Preload("Identities").First(&projectedHost)
+
+	s.NotNil(projectedHost.IdentityConflictAt)
+	s.WithinDuration(now, *projectedHost.IdentityConflictAt, time.Second)
+	s.Len(projectedHost.Identities, 2)
+
+	var health entities.HealthState
+	s.tx.Where("id = ?", "agent_id").First(&health)
+	s.Equal(models.HealthSummaryHealthCritical, health.Health)
+	s.JSONEq(`{"identity": "Identity conflict, the agent ID is reported by: host1, host1-clone"}`, string(health.Reasons))
+}
+
+// Test_HostDiscoveryHandler_IdentityChanged tests a single change of hostname is not a conflict
+func (s *HostsProjectorTestSuite) Test_HostDiscoveryHandler_IdentityChanged() {
+	now := time.Now()
+
+	s.discoverHost("host1", []string{"10.1.1.4"}, now.Add(-30*time.Minute))
+	s.discoverHost("host1", []string{"10.1.1.4"}, now.Add(-20*time.Minute))
+	s.discoverHost("host2", []string{"10.1.1.6"}, now.Add(-10*time.Minute))
+	s.discoverHost("host2", []string{"10.1.1.6"}, now)
+
+	var projectedHost entities.Host
+	s.tx.First(&projectedHost)
+	s.Nil(projectedHost.IdentityConflictAt)
+
+	var health entities.HealthState
+	s.tx.Where("id = ?", "agent_id").First(&health)
+	s.Equal(models.HealthSummaryHealthPassing, health.Health)
+}
+
+// Test_HostDiscoveryHandler_IdentityConflictExpired tests the conflict is cleared once no other host reported
+// the agent ID within the conflict window
+func (s *HostsProjectorTestSuite) Test_HostDiscoveryHandler_IdentityConflictExpired() {
+	now := time.Now()
+	conflictAt := now.Add(-2 * entities.IdentityConflictWindow)
+
+	s.discoverHost("host1", []string{"10.1.1.4"}, conflictAt.Add(-20*time.Minute))
+	s.discoverHost("host1-clone", []string{"10.1.1.5"}, conflictAt.Add(-10*time.Minute))
+	s.discoverHost("host1", []string{"10.1.1.4"}, conflictAt)
+
+	var health entities.HealthState
+	s.tx.Where("id = ?", "agent_id").First(&health)
+	s.Equal(models.HealthSummaryHealthCritical, health.Health)
+
+	s.discoverHost("host1", []string{"10.1.1.4"}, now)
+
+	var projectedHost entities.Host
+	s.tx.Preload("Identities").First(&projectedHost)
+	s.Len(projectedHost.Identities, 1)
+	s.Nil(projectedHost.ToModel().IdentityConflict)
+
+	s.tx.Where("id = ?", "agent_id").First(&health)
+	s.Equal(models.HealthSummaryHealthPassing, health.Health)
+}
+
+// Test_HostDiscoveryHandler_VirtualIPFailover tests a cluster virtual IP moving away from the host and back
+// during a failover and failback is not a conflict
+func (s *HostsProjectorTestSuite) Test_HostDiscoveryHandler_VirtualIPFailover() {
+	now := time.Now()
+
+	s.discoverHost("host1", []string{"10.1.1.4", "10.1.1.100"}, now.Add(-30*time.Minute))
+	s.discoverHost("host1", []string{"10.1.1.4"}, now.Add(-20*time.Minute))
+	s.discoverHost("host1", []string{"10.1.1.4", "10.1.1.100"}, now)
+
+	var projectedHost entities.Host
+	s.tx.Preload("Identities").First(&projectedHost)
+	s.Nil(projectedHost.IdentityConflictAt)
+	s.Len(projectedHost.Identities, 1)
+	s.EqualValues([]string{"10.1.1.4", "10.1.1.100"}, projectedHost.Identities[0].IPAddresses)
+
+	var health entities.HealthState
+	s.tx.Where("id = ?", "agent_id").First(&health)
+	s.Equal(models.HealthSummaryHealthPassing, health.Health)
+}
+
 // Test_CloudDiscoveryHandler tests the loudDiscoveryHandler function execution on a CloudDiscovery published by an agent
 func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler() {
 	discoveredCloudMock := mocks.NewDiscoveredCloudMock()
@@ -104,6 +207,165 @@ func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler() {
 		SKU:             "gen2",
 		AdminUsername:   "cloudadmin",
 	}, projectedAzureCloudData)
+
+	var health entities.HealthState
+	s.tx.Where("id = ?", "agent_id").First(&health)
+	s.Equal(models.HealthSummaryHealthPassing, health.Health)
+}
+
+// Test_CloudDiscoveryHandler_ScheduledEvents tests the CloudDiscoveryHandler warns about the host and its cluster
+// when the Azure platform has pending scheduled events for the VM
+func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler_ScheduledEvents() {
+	discoveredCloudMock := mocks.NewDiscoveredCloudMock()
+	discoveredCloudMock.Metadata.(*cloud.AzureMetadata).ScheduledEvents = []*cloud.ScheduledEvent{
+		{
+			EventId:           "C7061BAC-AFDC-4513-B24B-AA5F13A16123",
+			EventType:         "Reboot",
+			ResourceType:      "VirtualMachine",
+			Resources:         []string{"vmhana01"},
+			EventStatus:       "Scheduled",
+			NotBefore:         "Mon, 11 Apr 2022 10:00:00 GMT",
+			Description:       "Virtual machine is going to be restarted as requested by authorized user.",
+			EventSource:       "User",
+			DurationInSeconds: -1,
+		},
+		{
+			EventId:     "D8A2A3A2-1A07-4A7C-9D8B-1F3C1C8C4D32",
+			EventType:   "Freeze",
+			Resources:   []string{"vmhana02"},
+			EventStatus: "Scheduled",
+		},
+	}
+
+	s.tx.Create(&entities.Host{
+		AgentID:   "agent_id",
+		ClusterID: "cluster_id",
+	})
+
+	requestBody, _ := json.Marshal(discoveredCloudMock)
+	err := hostsProjector_CloudDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: CloudDiscovery,
+		Payload:       requestBody,
+	}, s.tx)
+	s.NoError(err)
+
+	var projectedHost entities.Host
+	s.tx.First(&projectedHost)
+
+	var projectedAzureCloudData entities.AzureCloudData
+	json.Unmarshal(projectedHost.CloudData, &projectedAzureCloudData)
+	s.EqualValues([]entities.AzureScheduledEvent{
+		{
+			ID:                "C7061BAC-AFDC-4513-B24B-AA5F13A16123",
+			Type:              "Reboot",
+			Status:            "Scheduled",
+			NotBefore:         "Mon, 11 Apr 2022 10:00:00 GMT",
+			Description:       "Virtual machine is going to be restarted as requested by authorized user.",
+			DurationInSeconds: -1,
+		},
+	}, projectedAzureCloudData.ScheduledEvents)
+
+	var hostHealth entities.HealthState
+	s.tx.Where("id = ?", "agent_id").First(&hostHealth)
+	s.Equal(models.HealthSummaryHealthWarning, hostHealth.Health)
+	s.JSONEq(`{"azure_scheduled_events": "warning"}`, string(hostHealth.PartialHealths))
+
+	var clusterHealth entities.HealthState
+	s.tx.Where("id = ?", "cluster_id").First(&clusterHealth)
+	s.Equal(models.HealthSummaryHealthWarning, clusterHealth.Health)
+}
+
+func (s *HostsProjectorTestSuite) Test_parseAzureScheduledEvents() {
+	events := parseAzureScheduledEvents("vmhana01", []*cloud.ScheduledEvent{
+		{EventId: "1", EventType: "Freeze", Resources: []string{"vmhana01"}, EventStatus: "Scheduled"},
+		{EventId: "2", EventType: "Reboot", Resources: []string{"vmhana01"}, EventStatus: "Completed"},
+		{EventId: "3", EventType: "Redeploy", Resources: []string{"vmhana02"}, EventStatus: "Scheduled"},
+		{EventId: "4", EventType: "Preempt", EventStatus: "Started"},
+	})
+
+	s.EqualValues([]entities.AzureScheduledEvent{
+		{ID: "1", Type: "Freeze", Status: "Scheduled"},
+		{ID: "4", Type: "Preempt", Status: "Started"},
+	}, events)
+}
+
+// Test_CloudDiscoveryHandler_Aws tests the CloudDiscoveryHandler function execution on an Aws CloudDiscovery published by an agent
+func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler_Aws() {
+	discoveredCloudMock := mocks.NewDiscoveredAwsCloudMock()
+
+	requestBody, _ := json.Marshal(discoveredCloudMock)
+
+	hostsProjector_CloudDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: CloudDiscovery,
+		Payload:       requestBody,
+	}, s.tx)
+
+	var projectedHost entities.Host
+	s.tx.First(&projectedHost)
+
+	s.Equal("aws", projectedHost.CloudProvider)
+
+	var projectedAWSCloudData entities.AWSCloudData
+	err := json.Unmarshal(projectedHost.CloudData, &projectedAWSCloudData)
+
+	s.NoError(err)
+	s.EqualValues(entities.AWSCloudData{
+		InstanceID:       "i-0123456789abcdef0",
+		InstanceType:     "r5b.4xlarge",
+		AccountID:        "123456789012",
+		Region:           "eu-central-1",
+		AvailabilityZone: "eu-central-1a",
+		ImageID:          "ami-0a1b2c3d4e5f67890",
+		PrivateIP:        "10.0.1.10",
+		EBSVolumes:       []string{"/dev/sda1", "sdb", "sdc"},
+	}, projectedAWSCloudData)
+}
+
+func (s *HostsProjectorTestSuite) Test_parseAWSCloudData_Empty() {
+	awsCloudData := parseAWSCloudData(struct{}{})
+	s.EqualValues(entities.AWSCloudData{}, awsCloudData)
+}
+
+// Test_CloudDiscoveryHandler_Gcp tests the CloudDiscoveryHandler function execution on a Gcp CloudDiscovery published by an agent
+func (s *HostsProjectorTestSuite) Test_CloudDiscoveryHandler_Gcp() {
+	discoveredCloudMock := mocks.NewDiscoveredGcpCloudMock()
+
+	requestBody, _ := json.Marshal(discoveredCloudMock)
+
+	hostsProjector_CloudDiscoveryHandler(&DataCollectedEvent{
+		ID:            1,
+		AgentID:       "agent_id",
+		DiscoveryType: CloudDiscovery,
+		Payload:       requestBody,
+	}, s.tx)
+
+	var projectedHost entities.Host
+	s.tx.First(&projectedHost)
+
+	s.Equal("gcp", projectedHost.CloudProvider)
+
+	var projectedGCPCloudData entities.GCPCloudData
+	err := json.Unmarshal(projectedHost.CloudData, &projectedGCPCloudData)
+
+	s.NoError(err)
+	s.EqualValues(entities.GCPCloudData{
+		InstanceName: "vmhana01",
+		ProjectID:    "sap-project",
+		Zone:         "europe-west1-b",
+		MachineType:  "n1-highmem-32",
+		Image:        "sles-15-sp1-sap-v20220126",
+		DisksNumber:  3,
+		Networks:     []string{"network"},
+	}, projectedGCPCloudData)
+}
+
+func (s *HostsProjectorTestSuite) Test_parseGCPCloudData_Empty() {
+	gcpCloudData := parseGCPCloudData(struct{}{})
+	s.EqualValues(entities.GCPCloudData{}, gcpCloudData)
 }
 
 func (s *HostsProjectorTestSuite) Test_parseAzureCloudData_Empty() {
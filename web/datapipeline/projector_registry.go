@@ -12,5 +12,7 @@ func InitProjectorsRegistry(db *gorm.DB) ProjectorRegistry {
 		NewHostTelemetryProjector(db),
 		NewSlesSubscriptionsProjector(db),
 		NewSAPSystemsProjector(db),
+		NewSystemdUnitsProjector(db),
+		NewTuningProjector(db),
 	}
 }
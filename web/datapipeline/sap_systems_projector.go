@@ -5,6 +5,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/internal/sapsystem"
+	"github.com/trento-project/trento/internal/sapsystem/sapcontrol"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 	"gorm.io/gorm"
@@ -102,7 +103,8 @@ func SAPSystemsProjector_SAPSystemsDiscoveryHandler(dataCollectedEvent *DataColl
 			"id", "sid", "type", "features", "instance_number",
 			"system_replication", "system_replication_status",
 			"sap_hostname", "start_priority", "http_port", "https_port", "status",
-			"tenants", "db_host", "db_name", "db_address")
+			"tenants", "db_host", "db_name", "db_address",
+			"ha_active", "ha_sap_interface_version", "ha_checks_state")
 		if err != nil {
 			return err
 		}
@@ -188,4 +190,29 @@ func addSAPControlData(instance *entities.SAPSystemInstance, sapControl *sapsyst
 			instance.HttpsPort = (int)(i.HttpsPort)
 		}
 	}
+
+	if sapControl.HAFailoverConfig != nil {
+		instance.HAActive = sapControl.HAFailoverConfig.HAActive
+		instance.HASAPInterfaceVersion = sapControl.HAFailoverConfig.HASAPInterfaceVersion
+	}
+	instance.HAChecksState = parseHAChecksState(sapControl.HAChecks)
+}
+
+// parseHAChecksState returns the worst state among the SAP HA interface checks,
+// or an empty string if the checks are not available
+func parseHAChecksState(checks []*sapcontrol.HACheck) string {
+	var state sapcontrol.HAVerificationState
+
+	for _, c := range checks {
+		switch {
+		case c.State == sapcontrol.HAVerificationStateERROR:
+			state = c.State
+		case c.State == sapcontrol.HAVerificationStateWARNING && state != sapcontrol.HAVerificationStateERROR:
+			state = c.State
+		case c.State == sapcontrol.HAVerificationStateSUCCESS && state == "":
+			state = c.State
+		}
+	}
+
+	return string(state)
 }
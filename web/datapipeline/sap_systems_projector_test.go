@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/trento/agent/discovery/mocks"
+	"github.com/trento-project/trento/internal/sapsystem/sapcontrol"
 	"github.com/trento-project/trento/test/helpers"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
@@ -167,6 +169,9 @@ func (s *SAPSystemsProjectorTestSuite) Test_SAPSystemDiscoveryHandler_Applicatio
 	s.Equal("3", projectedSAPSystemInstance.StartPriority)
 	s.Equal(50213, projectedSAPSystemInstance.HttpPort)
 	s.Equal(50214, projectedSAPSystemInstance.HttpsPort)
+	s.True(projectedSAPSystemInstance.HAActive)
+	s.Equal("SUSE Linux Enterprise Server for SAP Applications 15 SP3 (sap_suse_cluster_connector 3.1.2)", projectedSAPSystemInstance.HASAPInterfaceVersion)
+	s.Equal("SAPControl-HA-SUCCESS", projectedSAPSystemInstance.HAChecksState)
 }
 
 func (s *SAPSystemsProjectorTestSuite) Test_SAPSystemDiscoveryHandler_Diagnostics() {
@@ -185,3 +190,20 @@ func (s *SAPSystemsProjectorTestSuite) Test_SAPSystemDiscoveryHandler_Diagnostic
 
 	s.Equal(int64(0), result.RowsAffected)
 }
+
+func TestParseHAChecksState(t *testing.T) {
+	assert.Equal(t, "", parseHAChecksState(nil))
+	assert.Equal(t, "SAPControl-HA-SUCCESS", parseHAChecksState([]*sapcontrol.HACheck{
+		{State: sapcontrol.HAVerificationStateSUCCESS},
+		{State: sapcontrol.HAVerificationStateSUCCESS},
+	}))
+	assert.Equal(t, "SAPControl-HA-WARNING", parseHAChecksState([]*sapcontrol.HACheck{
+		{State: sapcontrol.HAVerificationStateSUCCESS},
+		{State: sapcontrol.HAVerificationStateWARNING},
+		{State: sapcontrol.HAVerificationStateSUCCESS},
+	}))
+	assert.Equal(t, "SAPControl-HA-ERROR", parseHAChecksState([]*sapcontrol.HACheck{
+		{State: sapcontrol.HAVerificationStateERROR},
+		{State: sapcontrol.HAVerificationStateWARNING},
+	}))
+}
@@ -0,0 +1,93 @@
+package datapipeline
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/internal/systemd"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+const (
+	partialSystemdUnitsHealth = "systemd_units"
+)
+
+// criticalSystemdUnits are the units the HA stack can't work without, the host is critical when any of them failed.
+// Any other failed unit puts the host in warning
+var criticalSystemdUnits = []string{
+	"pacemaker.service",
+	"corosync.service",
+	"sbd.service",
+	"sapinit.service",
+}
+
+func NewSystemdUnitsProjector(db *gorm.DB) *projector {
+	systemdUnitsProjector := NewProjector("systemd_units", db)
+
+	systemdUnitsProjector.AddHandler(SystemdDiscovery, systemdUnitsProjector_SystemdDiscoveryHandler)
+
+	return systemdUnitsProjector
+}
+
+func systemdUnitsProjector_SystemdDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+	decoder := getPayloadDecoder(dataCollectedEvent.Payload)
+
+	var discoveredUnits systemd.Units
+	if err := decoder.Decode(&discoveredUnits); err != nil {
+		log.Errorf("can't decode data: %s", err)
+		return err
+	}
+
+	var unitEntities []entities.SystemdUnit
+	for _, unit := range discoveredUnits {
+		unitEntities = append(unitEntities, entities.SystemdUnit{
+			AgentID:       dataCollectedEvent.AgentID,
+			Name:          unit.Name,
+			Description:   unit.Description,
+			LoadState:     unit.LoadState,
+			ActiveState:   unit.ActiveState,
+			SubState:      unit.SubState,
+			UnitFileState: unit.UnitFileState,
+		})
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("agent_id", dataCollectedEvent.AgentID).Delete(&entities.SystemdUnit{}).Error; err != nil {
+			return err
+		}
+		if len(unitEntities) > 0 {
+			return tx.Create(&unitEntities).Error
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ProjectHealth(db, dataCollectedEvent.AgentID, partialSystemdUnitsHealth, computeSystemdUnitsHealth(discoveredUnits))
+	if err != nil {
+		log.Errorf("can't project health: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func computeSystemdUnitsHealth(units systemd.Units) string {
+	health := models.HealthSummaryHealthPassing
+	for _, unit := range units {
+		if unit.ActiveState != systemd.ActiveStateFailed {
+			continue
+		}
+
+		if internal.Contains(criticalSystemdUnits, unit.Name) {
+			return models.HealthSummaryHealthCritical
+		}
+		health = models.HealthSummaryHealthWarning
+	}
+
+	return health
+}
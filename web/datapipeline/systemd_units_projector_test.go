@@ -0,0 +1,133 @@
+package datapipeline
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/systemd"
+	_ "github.com/trento-project/trento/test"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type SystemdUnitsProjectorTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestSystemdUnitsProjectorTestSuite(t *testing.T) {
+	suite.Run(t, new(SystemdUnitsProjectorTestSuite))
+}
+
+func (suite *SystemdUnitsProjectorTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&Subscription{}, &entities.SystemdUnit{}, &entities.HealthState{})
+}
+
+func (suite *SystemdUnitsProjectorTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(Subscription{}, entities.SystemdUnit{}, entities.HealthState{})
+}
+
+func (suite *SystemdUnitsProjectorTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+
+	suite.tx.Create(&entities.SystemdUnit{
+		AgentID:     "779cdd70-e9e2-58ca-b18a-bf3eb3f71244",
+		Name:        "sbd.service",
+		LoadState:   "loaded",
+		ActiveState: "active",
+		SubState:    "running",
+	})
+
+	suite.tx.Create(&entities.SystemdUnit{
+		AgentID:     "879cdd70-e9e2-58ca-b18a-bf3eb3f71244",
+		Name:        "pacemaker.service",
+		LoadState:   "loaded",
+		ActiveState: "active",
+		SubState:    "running",
+	})
+}
+
+func (suite *SystemdUnitsProjectorTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func loadSystemdDiscoveryEvent() *DataCollectedEvent {
+	jsonFile, err := os.Open("./test/fixtures/discovery/systemd/expected_published_systemd_discovery.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+	var dataCollectedEvent *DataCollectedEvent
+	json.Unmarshal(byteValue, &dataCollectedEvent)
+
+	return dataCollectedEvent
+}
+
+func (suite *SystemdUnitsProjectorTestSuite) Test_SystemdUnitsProjector() {
+	err := systemdUnitsProjector_SystemdDiscoveryHandler(loadSystemdDiscoveryEvent(), suite.tx)
+	suite.NoError(err)
+
+	var projectedUnits []entities.SystemdUnit
+	suite.tx.Where("agent_id", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").Order("name").Find(&projectedUnits)
+
+	suite.Len(projectedUnits, 4)
+	suite.Equal(entities.SystemdUnit{
+		AgentID:       "779cdd70-e9e2-58ca-b18a-bf3eb3f71244",
+		Name:          "corosync.service",
+		Description:   "Corosync Cluster Engine",
+		LoadState:     "loaded",
+		ActiveState:   "active",
+		SubState:      "running",
+		UnitFileState: "enabled",
+	}, projectedUnits[0])
+
+	var health entities.HealthState
+	suite.tx.Where("id = ?", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").First(&health)
+	suite.Equal(models.HealthSummaryHealthWarning, health.Health)
+	suite.JSONEq(`{"systemd_units": "warning"}`, string(health.PartialHealths))
+}
+
+func (suite *SystemdUnitsProjectorTestSuite) Test_SystemdUnitsProjectorDelete() {
+	dataCollectedEvent := loadSystemdDiscoveryEvent()
+	systemdUnitsProjector_SystemdDiscoveryHandler(dataCollectedEvent, suite.tx)
+
+	// Send a new discovery with empty data
+	dataCollectedEvent.Payload = datatypes.JSON([]byte(`[]`))
+	systemdUnitsProjector_SystemdDiscoveryHandler(dataCollectedEvent, suite.tx)
+
+	var count int64
+	suite.tx.Table("systemd_units").Where("agent_id", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").Count(&count)
+	suite.Equal(int64(0), count)
+
+	suite.tx.Table("systemd_units").Where("agent_id", "879cdd70-e9e2-58ca-b18a-bf3eb3f71244").Count(&count)
+	suite.Equal(int64(1), count)
+}
+
+func TestComputeSystemdUnitsHealth(t *testing.T) {
+	assert.Equal(t, models.HealthSummaryHealthPassing, computeSystemdUnitsHealth(systemd.Units{
+		{Name: "pacemaker.service", ActiveState: "active"},
+		{Name: "sapinit.service", ActiveState: "inactive", LoadState: "not-found"},
+	}))
+
+	assert.Equal(t, models.HealthSummaryHealthWarning, computeSystemdUnitsHealth(systemd.Units{
+		{Name: "pacemaker.service", ActiveState: "active"},
+		{Name: "prometheus-node_exporter.service", ActiveState: "failed"},
+	}))
+
+	assert.Equal(t, models.HealthSummaryHealthCritical, computeSystemdUnitsHealth(systemd.Units{
+		{Name: "prometheus-node_exporter.service", ActiveState: "failed"},
+		{Name: "sbd.service", ActiveState: "failed"},
+	}))
+
+	assert.Equal(t, models.HealthSummaryHealthPassing, computeSystemdUnitsHealth(systemd.Units{}))
+}
@@ -0,0 +1,75 @@
+package datapipeline
+
+import (
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/trento-project/trento/internal/tuning"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+)
+
+const (
+	partialTuningHealth = "tuning"
+)
+
+func NewTuningProjector(db *gorm.DB) *projector {
+	tuningProjector := NewProjector("tuning", db)
+
+	tuningProjector.AddHandler(TuningDiscovery, tuningProjector_TuningDiscoveryHandler)
+
+	return tuningProjector
+}
+
+func tuningProjector_TuningDiscoveryHandler(dataCollectedEvent *DataCollectedEvent, db *gorm.DB) error {
+	decoder := getPayloadDecoder(dataCollectedEvent.Payload)
+
+	var status tuning.Status
+	if err := decoder.Decode(&status); err != nil {
+		log.Errorf("can't decode data: %s", err)
+		return err
+	}
+
+	hostTuning := &entities.HostTuning{
+		AgentID:                  dataCollectedEvent.AgentID,
+		Tool:                     status.Tool,
+		SaptuneVersion:           status.SaptuneVersion,
+		SaptuneConfiguredVersion: status.SaptuneConfiguredVersion,
+		Solution:                 status.Solution,
+		Notes:                    status.Notes,
+		AppliedNotes:             status.AppliedNotes,
+		Compliant:                status.Compliant,
+		SapconfVersion:           status.SapconfVersion,
+		SapconfActive:            status.SapconfActive,
+		SapconfProfile:           status.SapconfProfile,
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(hostTuning).Error
+	if err != nil {
+		return err
+	}
+
+	err = ProjectHealth(db, dataCollectedEvent.AgentID, partialTuningHealth, computeTuningHealth(&status))
+	if err != nil {
+		log.Errorf("can't project health: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// computeTuningHealth puts the host in warning when it's not tuned at all,
+// or when it's tuned by saptune but the enabled notes are not compliant
+func computeTuningHealth(status *tuning.Status) string {
+	switch {
+	case status.Tool == "":
+		return models.HealthSummaryHealthWarning
+	case status.Tool == tuning.ToolSaptune && !status.Compliant:
+		return models.HealthSummaryHealthWarning
+	default:
+		return models.HealthSummaryHealthPassing
+	}
+}
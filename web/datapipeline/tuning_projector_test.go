@@ -0,0 +1,122 @@
+package datapipeline
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/internal/tuning"
+	_ "github.com/trento-project/trento/test"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type TuningProjectorTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+func TestTuningProjectorTestSuite(t *testing.T) {
+	suite.Run(t, new(TuningProjectorTestSuite))
+}
+
+func (suite *TuningProjectorTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&Subscription{}, &entities.HostTuning{}, &entities.HealthState{})
+}
+
+func (suite *TuningProjectorTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(Subscription{}, entities.HostTuning{}, entities.HealthState{})
+}
+
+func (suite *TuningProjectorTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+}
+
+func (suite *TuningProjectorTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func loadTuningDiscoveryEvent() *DataCollectedEvent {
+	jsonFile, err := os.Open("./test/fixtures/discovery/tuning/expected_published_tuning_discovery.json")
+	if err != nil {
+		panic(err)
+	}
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+	var dataCollectedEvent *DataCollectedEvent
+	json.Unmarshal(byteValue, &dataCollectedEvent)
+
+	return dataCollectedEvent
+}
+
+func (suite *TuningProjectorTestSuite) Test_TuningProjector() {
+	err := tuningProjector_TuningDiscoveryHandler(loadTuningDiscoveryEvent(), suite.tx)
+	suite.NoError(err)
+
+	var projectedTuning entities.HostTuning
+	suite.tx.Where("agent_id", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").First(&projectedTuning)
+
+	suite.Equal("saptune", projectedTuning.Tool)
+	suite.Equal("3.1.0", projectedTuning.SaptuneVersion)
+	suite.Equal("3", projectedTuning.SaptuneConfiguredVersion)
+	suite.Equal("HANA", projectedTuning.Solution)
+	suite.Equal(pq.StringArray{"941735", "1771258", "1980196"}, projectedTuning.Notes)
+	suite.Equal(pq.StringArray{"941735", "1771258"}, projectedTuning.AppliedNotes)
+	suite.False(projectedTuning.Compliant)
+
+	var health entities.HealthState
+	suite.tx.Where("id = ?", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").First(&health)
+	suite.Equal(models.HealthSummaryHealthWarning, health.Health)
+	suite.JSONEq(`{"tuning": "warning"}`, string(health.PartialHealths))
+}
+
+func (suite *TuningProjectorTestSuite) Test_TuningProjectorUpdate() {
+	dataCollectedEvent := loadTuningDiscoveryEvent()
+	tuningProjector_TuningDiscoveryHandler(dataCollectedEvent, suite.tx)
+
+	// The host is tuned by sapconf now
+	dataCollectedEvent.Payload = datatypes.JSON([]byte(`{"tool": "sapconf", "sapconf_version": "5.0.2", "sapconf_active": true, "compliant": false}`))
+	tuningProjector_TuningDiscoveryHandler(dataCollectedEvent, suite.tx)
+
+	var projectedTuning []entities.HostTuning
+	suite.tx.Where("agent_id", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").Find(&projectedTuning)
+
+	suite.Len(projectedTuning, 1)
+	suite.Equal("sapconf", projectedTuning[0].Tool)
+	suite.Equal("5.0.2", projectedTuning[0].SapconfVersion)
+	suite.Empty(projectedTuning[0].Notes)
+
+	var health entities.HealthState
+	suite.tx.Where("id = ?", "779cdd70-e9e2-58ca-b18a-bf3eb3f71244").First(&health)
+	suite.Equal(models.HealthSummaryHealthPassing, health.Health)
+}
+
+func TestComputeTuningHealth(t *testing.T) {
+	assert.Equal(t, models.HealthSummaryHealthPassing, computeTuningHealth(&tuning.Status{
+		Tool:      tuning.ToolSaptune,
+		Compliant: true,
+	}))
+
+	assert.Equal(t, models.HealthSummaryHealthWarning, computeTuningHealth(&tuning.Status{
+		Tool:      tuning.ToolSaptune,
+		Compliant: false,
+	}))
+
+	assert.Equal(t, models.HealthSummaryHealthPassing, computeTuningHealth(&tuning.Status{
+		Tool:          tuning.ToolSapconf,
+		SapconfActive: true,
+	}))
+
+	assert.Equal(t, models.HealthSummaryHealthWarning, computeTuningHealth(&tuning.Status{
+		SaptuneVersion: "3.1.0",
+	}))
+}
@@ -0,0 +1,218 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type JSONEnrollmentTokenRequest struct {
+	// Whether the token can be used to enroll only one agent
+	SingleUse bool `json:"single_use"`
+	// Seconds after which the token expires, 0 never expires
+	ExpiresIn int `json:"expires_in"`
+}
+
+type JSONEnrollRequest struct {
+	AgentID string `json:"agent_id" binding:"required"`
+	Token   string `json:"token" binding:"required"`
+}
+
+type JSONEnrollResponse struct {
+	Credential string `json:"credential"`
+}
+
+// ApiCreateEnrollmentTokenHandler godoc
+// @Summary Create a token the agents exchange for their own collector credential
+// @Accept json
+// @Produce json
+// @Param Body body JSONEnrollmentTokenRequest true "A single use token, an expiring one or both"
+// @Success 201 {object} models.EnrollmentToken
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security AdminToken
+// @Router /enrollment/tokens [post]
+func ApiCreateEnrollmentTokenHandler(enrollmentService services.EnrollmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONEnrollmentTokenRequest
+
+		err := c.BindJSON(&r)
+		if err != nil {
+			_ = c.Error(BadRequestError("unable to parse JSON body"))
+			return
+		}
+
+		if r.ExpiresIn < 0 {
+			_ = c.Error(BadRequestError("expires_in must not be negative"))
+			return
+		}
+
+		token, err := enrollmentService.CreateToken(r.SingleUse, time.Duration(r.ExpiresIn)*time.Second)
+		if errors.Is(err, services.ErrEnrollmentTokenLifecycle) {
+			_ = c.Error(BadRequestError(err.Error()))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, token)
+	}
+}
+
+// ApiListEnrollmentTokensHandler godoc
+// @Summary List the enrollment tokens
+// @Produce json
+// @Success 200 {array} models.EnrollmentToken
+// @Failure 500 {object} map[string]string
+// @Security AdminToken
+// @Router /enrollment/tokens [get]
+func ApiListEnrollmentTokensHandler(enrollmentService services.EnrollmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokens, err := enrollmentService.GetAllTokens()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if tokens == nil {
+			tokens = []*models.EnrollmentToken{}
+		}
+
+		c.JSON(http.StatusOK, tokens)
+	}
+}
+
+// ApiDeleteEnrollmentTokenHandler godoc
+// @Summary Delete an enrollment token, the agents already enrolled with it keep their credential
+// @Param id path string true "Enrollment token id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security AdminToken
+// @Router /enrollment/tokens/{id} [delete]
+func ApiDeleteEnrollmentTokenHandler(enrollmentService services.EnrollmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := enrollmentService.DeleteToken(c.Param("id"))
+		if errors.Is(err, services.ErrEnrollmentTokenNotFound) {
+			_ = c.Error(NotFoundError("could not find the enrollment token"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// ApiListEnrolledAgentsHandler godoc
+// @Summary List the enrolled agents and whether their credential was revoked
+// @Produce json
+// @Success 200 {array} models.AgentCredential
+// @Failure 500 {object} map[string]string
+// @Security AdminToken
+// @Router /enrollment/agents [get]
+func ApiListEnrolledAgentsHandler(enrollmentService services.EnrollmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credentials, err := enrollmentService.GetAllAgentCredentials()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if credentials == nil {
+			credentials = []*models.AgentCredential{}
+		}
+
+		c.JSON(http.StatusOK, credentials)
+	}
+}
+
+// ApiRevokeAgentHandler godoc
+// @Summary Revoke the collector credential of an agent, it can't publish data nor enroll again until it is reset
+// @Param id path string true "Agent id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security AdminToken
+// @Router /enrollment/agents/{id} [delete]
+func ApiRevokeAgentHandler(enrollmentService services.EnrollmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := enrollmentService.Revoke(c.Param("id"))
+		if errors.Is(err, services.ErrAgentCredentialNotFound) {
+			_ = c.Error(NotFoundError("could not find the enrolled agent"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// ApiResetAgentHandler godoc
+// @Summary Reset the collector credential of an agent, it can enroll again with a new token
+// @Param id path string true "Agent id"
+// @Success 204 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security AdminToken
+// @Router /enrollment/agents/{id}/reset [post]
+func ApiResetAgentHandler(enrollmentService services.EnrollmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := enrollmentService.Reset(c.Param("id"))
+		if errors.Is(err, services.ErrAgentCredentialNotFound) {
+			_ = c.Error(NotFoundError("could not find the enrolled agent"))
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// ApiEnrollAgentHandler exchanges an enrollment token for the credential the agent authenticates to the collector with
+func ApiEnrollAgentHandler(enrollmentService services.EnrollmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r JSONEnrollRequest
+
+		err := c.ShouldBindJSON(&r)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		credential, err := enrollmentService.Enroll(r.Token, r.AgentID)
+		switch {
+		case errors.Is(err, services.ErrInvalidEnrollmentToken):
+			_ = c.AbortWithError(http.StatusUnauthorized, err)
+			return
+		case errors.Is(err, services.ErrAgentCredentialRevoked):
+			_ = c.AbortWithError(http.StatusForbidden, err)
+			return
+		case errors.Is(err, services.ErrAgentAlreadyEnrolled):
+			_ = c.AbortWithError(http.StatusConflict, err)
+			return
+		case errors.Is(err, services.ErrInvalidAgentID):
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		case err != nil:
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, &JSONEnrollResponse{Credential: credential})
+	}
+}
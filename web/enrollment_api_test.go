@@ -0,0 +1,270 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/trento-project/trento/web/datapipeline"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+func setupEnrollmentTestConfig() *Config {
+	config := setupTestConfig()
+	config.AdminToken = "admin_token"
+	return config
+}
+
+func TestApiCreateEnrollmentTokenHandler(t *testing.T) {
+	expiresAt := time.Date(2022, 4, 2, 10, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	enrollmentService := new(services.MockEnrollmentService)
+	enrollmentService.On("CreateToken", true, 24*time.Hour).Return(&models.EnrollmentToken{
+		ID:        "token_id",
+		Token:     "secret_token",
+		SingleUse: true,
+		ExpiresAt: &expiresAt,
+		CreatedAt: createdAt,
+	}, nil)
+
+	deps := setupTestDependencies()
+	deps.enrollmentService = enrollmentService
+
+	app, err := NewAppWithDeps(setupEnrollmentTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&JSONEnrollmentTokenRequest{SingleUse: true, ExpiresIn: 86400})
+	req := httptest.NewRequest("POST", "/api/enrollment/tokens", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer admin_token")
+	req.Header.Set("Content-Type", "application/json")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 201, resp.Code)
+	assert.JSONEq(t, `{
+		"id": "token_id",
+		"token": "secret_token",
+		"single_use": true,
+		"expires_at": "2022-04-02T10:00:00Z",
+		"used_at": null,
+		"created_at": "2022-04-01T10:00:00Z"
+	}`, resp.Body.String())
+}
+
+func TestApiCreateEnrollmentTokenHandler_NeverExpiring(t *testing.T) {
+	enrollmentService := new(services.MockEnrollmentService)
+	enrollmentService.On("CreateToken", false, time.Duration(0)).Return(nil, services.ErrEnrollmentTokenLifecycle)
+
+	deps := setupTestDependencies()
+	deps.enrollmentService = enrollmentService
+
+	app, err := NewAppWithDeps(setupEnrollmentTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/enrollment/tokens", bytes.NewBufferString("{}"))
+	req.Header.Set("Authorization", "Bearer admin_token")
+	req.Header.Set("Content-Type", "application/json")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestApiRevokeAgentHandler(t *testing.T) {
+	enrollmentService := new(services.MockEnrollmentService)
+	enrollmentService.On("Revoke", "agent_id").Return(nil)
+	enrollmentService.On("Revoke", "unknown_agent_id").Return(services.ErrAgentCredentialNotFound)
+
+	deps := setupTestDependencies()
+	deps.enrollmentService = enrollmentService
+
+	app, err := NewAppWithDeps(setupEnrollmentTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/enrollment/agents/agent_id", nil)
+	req.Header.Set("Authorization", "Bearer admin_token")
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("DELETE", "/api/enrollment/agents/unknown_agent_id", nil)
+	req.Header.Set("Authorization", "Bearer admin_token")
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiResetAgentHandler(t *testing.T) {
+	enrollmentService := new(services.MockEnrollmentService)
+	enrollmentService.On("Reset", "agent_id").Return(nil)
+	enrollmentService.On("Reset", "unknown_agent_id").Return(services.ErrAgentCredentialNotFound)
+
+	deps := setupTestDependencies()
+	deps.enrollmentService = enrollmentService
+
+	app, err := NewAppWithDeps(setupEnrollmentTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/enrollment/agents/agent_id/reset", nil)
+	req.Header.Set("Authorization", "Bearer admin_token")
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/enrollment/agents/unknown_agent_id/reset", nil)
+	req.Header.Set("Authorization", "Bearer admin_token")
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
+
+func TestApiEnrollAgentHandler(t *testing.T) {
+	enrollmentService := new(services.MockEnrollmentService)
+	enrollmentService.On("Enroll", "valid_token", "agent_id").Return("agent_secret", nil)
+	enrollmentService.On("Enroll", "expired_token", "agent_id").Return("", services.ErrInvalidEnrollmentToken)
+	enrollmentService.On("Enroll", "valid_token", "revoked_agent_id").Return("", services.ErrAgentCredentialRevoked)
+	enrollmentService.On("Enroll", "valid_token", "enrolled_agent_id").Return("", services.ErrAgentAlreadyEnrolled)
+	enrollmentService.On("Enroll", "valid_token", "invalid_agent_id").Return("", services.ErrInvalidAgentID)
+
+	deps := setupTestDependencies()
+	deps.enrollmentService = enrollmentService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		request      JSONEnrollRequest
+		expectedCode int
+	}{
+		{JSONEnrollRequest{AgentID: "agent_id", Token: "valid_token"}, 200},
+		{JSONEnrollRequest{AgentID: "agent_id", Token: "expired_token"}, 401},
+		{JSONEnrollRequest{AgentID: "revoked_agent_id", Token: "valid_token"}, 403},
+		{JSONEnrollRequest{AgentID: "enrolled_agent_id", Token: "valid_token"}, 409},
+		{JSONEnrollRequest{AgentID: "invalid_agent_id", Token: "valid_token"}, 400},
+		{JSONEnrollRequest{AgentID: "agent_id"}, 400},
+	}
+
+	for _, c := range cases {
+		resp := httptest.NewRecorder()
+		body, _ := json.Marshal(&c.request)
+		req := httptest.NewRequest("POST", "/api/enroll", bytes.NewBuffer(body))
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, c.expectedCode, resp.Code)
+		if c.expectedCode == 200 {
+			assert.JSONEq(t, `{"credential": "agent_secret"}`, resp.Body.String())
+		}
+	}
+}
+
+func TestAgentAuthMiddleware(t *testing.T) {
+	enrollmentService := new(services.MockEnrollmentService)
+	enrollmentService.On("Authenticate", "agent_id", "agent_secret").Return(true, nil)
+	enrollmentService.On("Authenticate", "agent_id", "wrong_secret").Return(false, nil)
+
+	collectorService := new(services.MockCollectorService)
+	collectorService.On("StoreEvent", mock.Anything).Return(nil)
+
+	hostsService := new(services.MockHostsService)
+	hostsService.On("Heartbeat", "agent_id", mock.AnythingOfType("time.Time")).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.enrollmentService = enrollmentService
+	deps.collectorService = collectorService
+	deps.hostsService = hostsService
+
+	config := setupTestConfig()
+	config.EnableAgentAuth = true
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		url          string
+		agentID      string
+		secret       string
+		expectedCode int
+	}{
+		{"/api/collect", "", "", 401},
+		{"/api/collect", "agent_id", "wrong_secret", 401},
+		{"/api/collect", "agent_id", "agent_secret", 202},
+		{"/api/hosts/agent_id/heartbeat", "agent_id", "agent_secret", 204},
+		{"/api/hosts/other_agent_id/heartbeat", "agent_id", "agent_secret", 403},
+	}
+
+	for _, c := range cases {
+		resp := httptest.NewRecorder()
+		body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+			AgentID:       "agent_id",
+			DiscoveryType: "discovery",
+			Payload:       []byte("{}"),
+		})
+		req := httptest.NewRequest("POST", c.url, bytes.NewBuffer(body))
+		if c.agentID != "" {
+			req.SetBasicAuth(c.agentID, c.secret)
+		}
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, c.expectedCode, resp.Code, c.url)
+	}
+}
+
+func TestApiCollectDataHandler_OtherAgent(t *testing.T) {
+	enrollmentService := new(services.MockEnrollmentService)
+	enrollmentService.On("Authenticate", "agent_id", "agent_secret").Return(true, nil)
+
+	deps := setupTestDependencies()
+	deps.enrollmentService = enrollmentService
+	deps.collectorService = new(services.MockCollectorService)
+
+	config := setupTestConfig()
+	config.EnableAgentAuth = true
+
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	body, _ := json.Marshal(&datapipeline.DataCollectedEvent{
+		AgentID:       "other_agent_id",
+		DiscoveryType: "discovery",
+		Payload:       []byte("{}"),
+	})
+	req := httptest.NewRequest("POST", "/api/collect", bytes.NewBuffer(body))
+	req.SetBasicAuth("agent_id", "agent_secret")
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 403, resp.Code)
+}
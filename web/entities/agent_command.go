@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// AgentCommand is queued for an agent, which picks it up with the pending ones after a heartbeat
+type AgentCommand struct {
+	ID            string `gorm:"primaryKey"`
+	AgentID       string `gorm:"index"`
+	Type          string
+	DiscoveryType string
+	Error         string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	PickedUpAt    *time.Time
+	CompletedAt   *time.Time
+}
+
+func (c *AgentCommand) ToModel() *models.AgentCommand {
+	return &models.AgentCommand{
+		ID:            c.ID,
+		AgentID:       c.AgentID,
+		Type:          c.Type,
+		DiscoveryType: c.DiscoveryType,
+		Status:        c.status(),
+		Error:         c.Error,
+		CreatedAt:     c.CreatedAt,
+		PickedUpAt:    c.PickedUpAt,
+		CompletedAt:   c.CompletedAt,
+	}
+}
+
+func (c *AgentCommand) status() string {
+	switch {
+	case c.CompletedAt != nil && c.Error != "":
+		return models.AgentCommandFailed
+	case c.CompletedAt != nil:
+		return models.AgentCommandCompleted
+	case c.PickedUpAt != nil:
+		return models.AgentCommandPickedUp
+	case time.Now().After(c.ExpiresAt):
+		return models.AgentCommandExpired
+	default:
+		return models.AgentCommandQueued
+	}
+}
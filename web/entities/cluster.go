@@ -3,6 +3,7 @@ package entities
 import (
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/trento-project/trento/web/models"
 	"gorm.io/datatypes"
 )
@@ -11,7 +12,7 @@ type Cluster struct {
 	ID              string `gorm:"primaryKey"`
 	Name            string
 	ClusterType     string
-	SID             string `gorm:"column:sid"`
+	SIDs            pq.StringArray `gorm:"column:sids;type:text[]"`
 	ResourcesNumber int
 	HostsNumber     int
 	Health          *HealthState  `gorm:"foreignkey:id"`
@@ -22,39 +23,158 @@ type Cluster struct {
 }
 
 type HANAClusterDetails struct {
-	SystemReplicationMode          string             `json:"system_replication_mode"`
-	SystemReplicationOperationMode string             `json:"system_replication_operation_mode"`
-	SecondarySyncState             string             `json:"secondary_sync_state"`
-	SRHealthState                  string             `json:"sr_health_state"`
-	CIBLastWritten                 time.Time          `json:"cib_last_written"`
-	FencingType                    string             `json:"fencing_type"`
-	StoppedResources               []*ClusterResource `json:"stopped_resources"`
-	Nodes                          []*HANAClusterNode `json:"nodes"`
-	SBDDevices                     []*SBDDevice       `json:"sbd_devices"`
+	SystemReplicationMode          string                   `json:"system_replication_mode"`
+	SystemReplicationOperationMode string                   `json:"system_replication_operation_mode"`
+	SecondarySyncState             string                   `json:"secondary_sync_state"`
+	SRHealthState                  string                   `json:"sr_health_state"`
+	CIBLastWritten                 time.Time                `json:"cib_last_written"`
+	FencingType                    string                   `json:"fencing_type"`
+	FencingResources               []*FencingResource       `json:"fencing_resources"`
+	StoppedResources               []*ClusterResource       `json:"stopped_resources"`
+	LocationConstraints            []*LocationConstraint    `json:"location_constraints"`
+	Nodes                          []*HANAClusterNode       `json:"nodes"`
+	SBDDevices                     []*SBDDevice             `json:"sbd_devices"`
+	Corosync                       *CorosyncDetails         `json:"corosync"`
+	SystemReplications             []*HANASystemReplication `json:"system_replications"`
+}
+
+type HANASystemReplication struct {
+	SID                string `json:"sid"`
+	Mode               string `json:"mode"`
+	OperationMode      string `json:"operation_mode"`
+	SecondarySyncState string `json:"secondary_sync_state"`
+	SRHealthState      string `json:"sr_health_state"`
+}
+
+// GenericClusterDetails contains the details shared by all the cluster types,
+// used for the clusters not running any known SAP workload
+type GenericClusterDetails struct {
+	CIBLastWritten      time.Time             `json:"cib_last_written"`
+	FencingType         string                `json:"fencing_type"`
+	FencingResources    []*FencingResource    `json:"fencing_resources"`
+	StoppedResources    []*ClusterResource    `json:"stopped_resources"`
+	LocationConstraints []*LocationConstraint `json:"location_constraints"`
+	Nodes               []*HANAClusterNode    `json:"nodes"`
+	SBDDevices          []*SBDDevice          `json:"sbd_devices"`
+	Corosync            *CorosyncDetails      `json:"corosync"`
+}
+
+type ASCSERSClusterDetails struct {
+	SID                 string                `json:"sid"`
+	EnsaVersion         string                `json:"ensa_version"`
+	CIBLastWritten      time.Time             `json:"cib_last_written"`
+	FencingType         string                `json:"fencing_type"`
+	FencingResources    []*FencingResource    `json:"fencing_resources"`
+	StoppedResources    []*ClusterResource    `json:"stopped_resources"`
+	LocationConstraints []*LocationConstraint `json:"location_constraints"`
+	Instances           []*ASCSERSInstance    `json:"instances"`
+	Nodes               []*HANAClusterNode    `json:"nodes"`
+	SBDDevices          []*SBDDevice          `json:"sbd_devices"`
+	Corosync            *CorosyncDetails      `json:"corosync"`
+}
+
+type ASCSERSInstance struct {
+	Role            string               `json:"role"`
+	InstanceName    string               `json:"instance_name"`
+	InstanceNumber  string               `json:"instance_number"`
+	VirtualHostname string               `json:"virtual_hostname"`
+	ResourceID      string               `json:"resource_id"`
+	Node            string               `json:"node"`
+	Status          string               `json:"status"`
+	VirtualIPs      []string             `json:"virtual_ips"`
+	Filesystems     []*ClusterFilesystem `json:"filesystems"`
+}
+
+type ClusterFilesystem struct {
+	ResourceID string `json:"resource_id"`
+	Device     string `json:"device"`
+	Directory  string `json:"directory"`
+	FSType     string `json:"fstype"`
 }
 
 type ClusterResource struct {
-	ID        string `json:"id"`
-	Type      string `json:"type"`
-	Role      string `json:"role"`
-	Status    string `json:"status"`
-	FailCount int    `json:"fail_count"`
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Role               string `json:"role"`
+	Status             string `json:"status"`
+	FailCount          int    `json:"fail_count"`
+	MigrationThreshold int    `json:"migration_threshold"`
 }
 
 type HANAClusterNode struct {
-	Name       string             `json:"name"`
-	Site       string             `json:"site"`
-	Attributes map[string]string  `json:"attributes"`
-	Resources  []*ClusterResource `json:"resources"`
-	VirtualIPs []string           `json:"virtual_ips"`
-	HANAStatus string             `json:"hana_status"`
+	Name        string             `json:"name"`
+	Site        string             `json:"site"`
+	Attributes  map[string]string  `json:"attributes"`
+	Resources   []*ClusterResource `json:"resources"`
+	VirtualIPs  []string           `json:"virtual_ips"`
+	HANAStatus  string             `json:"hana_status"`
+	Online      bool               `json:"online"`
+	Standby     bool               `json:"standby"`
+	Maintenance bool               `json:"maintenance"`
+	Unclean     bool               `json:"unclean"`
+	Pending     bool               `json:"pending"`
+	Shutdown    bool               `json:"shutdown"`
+}
+
+// LocationConstraint is a location constraint left behind by a manual resource migration,
+// i.e. by crm resource move (cli-prefer-*) or crm resource ban (cli-ban-*)
+type LocationConstraint struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Resource string `json:"resource"`
+	Node     string `json:"node"`
+	Role     string `json:"role"`
+	Score    string `json:"score"`
+}
+
+// FencingResource is a stonith resource configured in the CIB. Its credentials are redacted
+type FencingResource struct {
+	ID                 string            `json:"id"`
+	Agent              string            `json:"agent"`
+	Status             string            `json:"status"`
+	Disabled           bool              `json:"disabled"`
+	InstanceAttributes map[string]string `json:"instance_attributes"`
+	MetaAttributes     map[string]string `json:"meta_attributes"`
 }
 
 type SBDDevice struct {
-	Device string `json:"device"`
+	Device          string     `json:"device"`
+	Status          string     `json:"status"`
+	TimeoutWatchdog int        `json:"timeout_watchdog"`
+	TimeoutMsgwait  int        `json:"timeout_msgwait"`
+	Nodes           []*SBDNode `json:"nodes"`
+}
+
+type SBDNode struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
 	Status string `json:"status"`
 }
 
+type CorosyncDetails struct {
+	Transport     string          `json:"transport"`
+	RRPMode       string          `json:"rrp_mode"`
+	Token         int             `json:"token"`
+	Consensus     int             `json:"consensus"`
+	ExpectedVotes int             `json:"expected_votes"`
+	TwoNode       bool            `json:"two_node"`
+	Nodes         []*CorosyncNode `json:"nodes"`
+	Rings         []*CorosyncRing `json:"rings"`
+}
+
+type CorosyncNode struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses"`
+}
+
+type CorosyncRing struct {
+	ID      int    `json:"id"`
+	Address string `json:"address"`
+	Status  string `json:"status"`
+	Faulty  bool   `json:"faulty"`
+}
+
 func (c *Cluster) ToModel() *models.Cluster {
 	// TODO: move to Tags entity when we will have it
 	var tags []string
@@ -71,7 +191,7 @@ func (c *Cluster) ToModel() *models.Cluster {
 		ID:              c.ID,
 		Name:            c.Name,
 		ClusterType:     c.ClusterType,
-		SID:             c.SID,
+		SIDs:            c.SIDs,
 		ResourcesNumber: c.ResourcesNumber,
 		HostsNumber:     c.HostsNumber,
 		Health:          health,
@@ -85,6 +205,16 @@ func (h *HANAClusterDetails) ToModel() *models.HANAClusterDetails {
 		stoppedResources = append(stoppedResources, r.ToModel())
 	}
 
+	var locationConstraints []*models.LocationConstraint
+	for _, l := range h.LocationConstraints {
+		locationConstraints = append(locationConstraints, l.ToModel())
+	}
+
+	var fencingResources []*models.FencingResource
+	for _, f := range h.FencingResources {
+		fencingResources = append(fencingResources, f.ToModel())
+	}
+
 	var nodes []*models.HANAClusterNode
 	for _, n := range h.Nodes {
 		nodes = append(nodes, n.ToModel())
@@ -95,6 +225,16 @@ func (h *HANAClusterDetails) ToModel() *models.HANAClusterDetails {
 		sbdDevices = append(sbdDevices, s.ToModel())
 	}
 
+	var corosync *models.CorosyncDetails
+	if h.Corosync != nil {
+		corosync = h.Corosync.ToModel()
+	}
+
+	var systemReplications []*models.HANASystemReplication
+	for _, r := range h.SystemReplications {
+		systemReplications = append(systemReplications, r.ToModel())
+	}
+
 	return &models.HANAClusterDetails{
 		SystemReplicationMode:          h.SystemReplicationMode,
 		SystemReplicationOperationMode: h.SystemReplicationOperationMode,
@@ -102,26 +242,225 @@ func (h *HANAClusterDetails) ToModel() *models.HANAClusterDetails {
 		SRHealthState:                  h.SRHealthState,
 		CIBLastWritten:                 h.CIBLastWritten,
 		FencingType:                    h.FencingType,
+		FencingResources:               fencingResources,
 		StoppedResources:               stoppedResources,
+		LocationConstraints:            locationConstraints,
 		Nodes:                          nodes,
 		SBDDevices:                     sbdDevices,
+		Corosync:                       corosync,
+		SystemReplications:             systemReplications,
+	}
+}
+
+func (r *HANASystemReplication) ToModel() *models.HANASystemReplication {
+	return &models.HANASystemReplication{
+		SID:                r.SID,
+		Mode:               r.Mode,
+		OperationMode:      r.OperationMode,
+		SecondarySyncState: r.SecondarySyncState,
+		SRHealthState:      r.SRHealthState,
+	}
+}
+
+func (d *GenericClusterDetails) ToModel() *models.GenericClusterDetails {
+	var stoppedResources []*models.ClusterResource
+	for _, r := range d.StoppedResources {
+		stoppedResources = append(stoppedResources, r.ToModel())
+	}
+
+	var locationConstraints []*models.LocationConstraint
+	for _, l := range d.LocationConstraints {
+		locationConstraints = append(locationConstraints, l.ToModel())
+	}
+
+	var fencingResources []*models.FencingResource
+	for _, f := range d.FencingResources {
+		fencingResources = append(fencingResources, f.ToModel())
+	}
+
+	var nodes []*models.HANAClusterNode
+	for _, n := range d.Nodes {
+		nodes = append(nodes, n.ToModel())
+	}
+
+	var sbdDevices []*models.SBDDevice
+	for _, s := range d.SBDDevices {
+		sbdDevices = append(sbdDevices, s.ToModel())
+	}
+
+	var corosync *models.CorosyncDetails
+	if d.Corosync != nil {
+		corosync = d.Corosync.ToModel()
+	}
+
+	return &models.GenericClusterDetails{
+		CIBLastWritten:      d.CIBLastWritten,
+		FencingType:         d.FencingType,
+		FencingResources:    fencingResources,
+		StoppedResources:    stoppedResources,
+		LocationConstraints: locationConstraints,
+		Nodes:               nodes,
+		SBDDevices:          sbdDevices,
+		Corosync:            corosync,
+	}
+}
+
+func (d *ASCSERSClusterDetails) ToModel() *models.ASCSERSClusterDetails {
+	var stoppedResources []*models.ClusterResource
+	for _, r := range d.StoppedResources {
+		stoppedResources = append(stoppedResources, r.ToModel())
+	}
+
+	var locationConstraints []*models.LocationConstraint
+	for _, l := range d.LocationConstraints {
+		locationConstraints = append(locationConstraints, l.ToModel())
+	}
+
+	var instances []*models.ASCSERSInstance
+	for _, i := range d.Instances {
+		instances = append(instances, i.ToModel())
+	}
+
+	var fencingResources []*models.FencingResource
+	for _, f := range d.FencingResources {
+		fencingResources = append(fencingResources, f.ToModel())
+	}
+
+	var nodes []*models.HANAClusterNode
+	for _, n := range d.Nodes {
+		nodes = append(nodes, n.ToModel())
+	}
+
+	var sbdDevices []*models.SBDDevice
+	for _, s := range d.SBDDevices {
+		sbdDevices = append(sbdDevices, s.ToModel())
+	}
+
+	var corosync *models.CorosyncDetails
+	if d.Corosync != nil {
+		corosync = d.Corosync.ToModel()
+	}
+
+	return &models.ASCSERSClusterDetails{
+		SID:                 d.SID,
+		EnsaVersion:         d.EnsaVersion,
+		CIBLastWritten:      d.CIBLastWritten,
+		FencingType:         d.FencingType,
+		FencingResources:    fencingResources,
+		StoppedResources:    stoppedResources,
+		LocationConstraints: locationConstraints,
+		Instances:           instances,
+		Nodes:               nodes,
+		SBDDevices:          sbdDevices,
+		Corosync:            corosync,
+	}
+}
+
+func (i *ASCSERSInstance) ToModel() *models.ASCSERSInstance {
+	var filesystems []*models.ClusterFilesystem
+	for _, f := range i.Filesystems {
+		filesystems = append(filesystems, &models.ClusterFilesystem{
+			ResourceID: f.ResourceID,
+			Device:     f.Device,
+			Directory:  f.Directory,
+			FSType:     f.FSType,
+		})
+	}
+
+	return &models.ASCSERSInstance{
+		Role:            i.Role,
+		InstanceName:    i.InstanceName,
+		InstanceNumber:  i.InstanceNumber,
+		VirtualHostname: i.VirtualHostname,
+		ResourceID:      i.ResourceID,
+		Node:            i.Node,
+		Status:          i.Status,
+		VirtualIPs:      i.VirtualIPs,
+		Filesystems:     filesystems,
 	}
 }
 
 func (r *ClusterResource) ToModel() *models.ClusterResource {
 	return &models.ClusterResource{
-		ID:        r.ID,
-		Type:      r.Type,
-		Role:      r.Role,
-		Status:    r.Status,
-		FailCount: r.FailCount,
+		ID:                 r.ID,
+		Type:               r.Type,
+		Role:               r.Role,
+		Status:             r.Status,
+		FailCount:          r.FailCount,
+		MigrationThreshold: r.MigrationThreshold,
+	}
+}
+
+func (l *LocationConstraint) ToModel() *models.LocationConstraint {
+	return &models.LocationConstraint{
+		ID:       l.ID,
+		Type:     l.Type,
+		Resource: l.Resource,
+		Node:     l.Node,
+		Role:     l.Role,
+		Score:    l.Score,
+	}
+}
+
+func (f *FencingResource) ToModel() *models.FencingResource {
+	return &models.FencingResource{
+		ID:                 f.ID,
+		Agent:              f.Agent,
+		Status:             f.Status,
+		Disabled:           f.Disabled,
+		InstanceAttributes: f.InstanceAttributes,
+		MetaAttributes:     f.MetaAttributes,
 	}
 }
 
 func (s *SBDDevice) ToModel() *models.SBDDevice {
+	var nodes []*models.SBDNode
+	for _, n := range s.Nodes {
+		nodes = append(nodes, &models.SBDNode{
+			ID:     n.ID,
+			Name:   n.Name,
+			Status: n.Status,
+		})
+	}
+
 	return &models.SBDDevice{
-		Device: s.Device,
-		Status: s.Status,
+		Device:          s.Device,
+		Status:          s.Status,
+		TimeoutWatchdog: s.TimeoutWatchdog,
+		TimeoutMsgwait:  s.TimeoutMsgwait,
+		Nodes:           nodes,
+	}
+}
+
+func (c *CorosyncDetails) ToModel() *models.CorosyncDetails {
+	var nodes []*models.CorosyncNode
+	for _, n := range c.Nodes {
+		nodes = append(nodes, &models.CorosyncNode{
+			ID:        n.ID,
+			Name:      n.Name,
+			Addresses: n.Addresses,
+		})
+	}
+
+	var rings []*models.CorosyncRing
+	for _, r := range c.Rings {
+		rings = append(rings, &models.CorosyncRing{
+			ID:      r.ID,
+			Address: r.Address,
+			Status:  r.Status,
+			Faulty:  r.Faulty,
+		})
+	}
+
+	return &models.CorosyncDetails{
+		Transport:     c.Transport,
+		RRPMode:       c.RRPMode,
+		Token:         c.Token,
+		Consensus:     c.Consensus,
+		ExpectedVotes: c.ExpectedVotes,
+		TwoNode:       c.TwoNode,
+		Nodes:         nodes,
+		Rings:         rings,
 	}
 }
 
@@ -132,11 +471,17 @@ func (n *HANAClusterNode) ToModel() *models.HANAClusterNode {
 	}
 
 	return &models.HANAClusterNode{
-		Name:       n.Name,
-		Site:       n.Site,
-		Attributes: n.Attributes,
-		Resources:  resources,
-		VirtualIPs: n.VirtualIPs,
-		HANAStatus: n.HANAStatus,
+		Name:        n.Name,
+		Site:        n.Site,
+		Attributes:  n.Attributes,
+		Resources:   resources,
+		VirtualIPs:  n.VirtualIPs,
+		HANAStatus:  n.HANAStatus,
+		Online:      n.Online,
+		Standby:     n.Standby,
+		Maintenance: n.Maintenance,
+		Unclean:     n.Unclean,
+		Pending:     n.Pending,
+		Shutdown:    n.Shutdown,
 	}
 }
@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/trento-project/trento/web/models"
+)
+
+// EnrollmentToken is exchanged by the agents for their own credential. Only the token hash is stored
+type EnrollmentToken struct {
+	ID        string `gorm:"primaryKey"`
+	TokenHash string `gorm:"uniqueIndex"`
+	SingleUse bool
+	ExpiresAt *time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// AgentCredential authenticates an agent to the collector. Only the secret hash is stored
+type AgentCredential struct {
+	AgentID    string `gorm:"primaryKey"`
+	SecretHash string
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+func (t *EnrollmentToken) ToModel() *models.EnrollmentToken {
+	return &models.EnrollmentToken{
+		ID:        t.ID,
+		SingleUse: t.SingleUse,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+func (c *AgentCredential) ToModel() *models.AgentCredential {
+	return &models.AgentCredential{
+		AgentID:   c.AgentID,
+		CreatedAt: c.CreatedAt,
+		RevokedAt: c.RevokedAt,
+	}
+}
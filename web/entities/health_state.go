@@ -1,6 +1,9 @@
 package entities
 
 import (
+	"encoding/json"
+
+	"github.com/trento-project/trento/web/models"
 	"gorm.io/datatypes"
 )
 
@@ -8,11 +11,47 @@ type HealthState struct {
 	ID             string `gorm:"primaryKey"`
 	Health         string
 	PartialHealths datatypes.JSON
+	Reasons        datatypes.JSON
 }
 
 // PartialHealths is something like
 // {"config_checks": "passing", "hana_sr_health": "passing"}
 
+// Reasons explain why a partial health is not passing, when the projector knows it, e.g.
+// {"sbd_health": "device /dev/sdb is unhealthy"}
+
 // The Health and PartialHealths are changed upon events:
 // config_checks when we receive new check results
 // hana_sr_health when we discover new cluster data
+// azure_scheduled_events when we discover new cloud data of the hosts
+
+func (h *HealthState) ToModel() (*models.HealthState, error) {
+	var partialHealths map[string]string
+	var reasons map[string]string
+
+	err := json.Unmarshal(h.PartialHealths, &partialHealths)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(h.Reasons) > 0 {
+		err = json.Unmarshal(h.Reasons, &reasons)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	healthState := &models.HealthState{
+		Health:         h.Health,
+		PartialHealths: make(map[string]*models.PartialHealth),
+	}
+
+	for partialType, health := range partialHealths {
+		healthState.PartialHealths[partialType] = &models.PartialHealth{
+			Health: health,
+			Reason: reasons[partialType],
+		}
+	}
+
+	return healthState, nil
+}
@@ -20,10 +20,30 @@ type Host struct {
 	SAPSystemInstances SAPSystemInstances `gorm:"foreignkey:AgentID"`
 	AgentVersion       string
 	Heartbeat          *HostHeartbeat    `gorm:"foreignKey:AgentID"`
+	Health             *HealthState      `gorm:"foreignKey:ID;references:AgentID"`
+	SystemdUnits       SystemdUnits      `gorm:"foreignKey:AgentID"`
+	Tuning             *HostTuning       `gorm:"foreignKey:AgentID"`
 	Subscription       *SlesSubscription `gorm:"foreignKey:AgentID"`
 	Tags               []*models.Tag     `gorm:"polymorphic:Resource;polymorphicValue:hosts"`
 	UpdatedAt          time.Time
 	CloudData          datatypes.JSON
+	// Last time the agent ID was reported by different hosts
+	IdentityConflictAt *time.Time
+	Identities         []*HostIdentity `gorm:"foreignKey:AgentID"`
+}
+
+// IdentityConflictWindow is the time an agent ID reported by different hosts stays in conflict.
+// It spans a few refresh intervals of the agents, as unchanged discoveries are published only once per interval
+const IdentityConflictWindow = time.Hour
+
+// HostIdentity is a hostname reported by an agent, along with its latest IP addresses.
+// Different identities reported in turn by the same agent ID reveal cloned machine IDs
+type HostIdentity struct {
+	AgentID        string `gorm:"primaryKey"`
+	Identity       string `gorm:"primaryKey"`
+	Hostname       string
+	IPAddresses    pq.StringArray `gorm:"type:text[]"`
+	LastReportedAt time.Time
 }
 
 type HostHeartbeat struct {
@@ -32,14 +52,45 @@ type HostHeartbeat struct {
 }
 
 type AzureCloudData struct {
-	VMName          string `json:"vmname"`
-	ResourceGroup   string `json:"resource_group"`
-	Location        string `json:"location"`
-	VMSize          string `json:"vmsize"`
-	DataDisksNumber int    `json:"data_disks_number"`
-	Offer           string `json:"offer"`
-	SKU             string `json:"sku"`
-	AdminUsername   string `json:"admin_username"`
+	VMName          string                `json:"vmname"`
+	ResourceGroup   string                `json:"resource_group"`
+	Location        string                `json:"location"`
+	VMSize          string                `json:"vmsize"`
+	DataDisksNumber int                   `json:"data_disks_number"`
+	Offer           string                `json:"offer"`
+	SKU             string                `json:"sku"`
+	AdminUsername   string                `json:"admin_username"`
+	ScheduledEvents []AzureScheduledEvent `json:"scheduled_events"`
+}
+
+type AzureScheduledEvent struct {
+	ID                string `json:"id"`
+	Type              string `json:"type"`
+	Status            string `json:"status"`
+	NotBefore         string `json:"not_before"`
+	Description       string `json:"description"`
+	DurationInSeconds int    `json:"duration_in_seconds"`
+}
+
+type AWSCloudData struct {
+	InstanceID       string   `json:"instance_id"`
+	InstanceType     string   `json:"instance_type"`
+	AccountID        string   `json:"account_id"`
+	Region           string   `json:"region"`
+	AvailabilityZone string   `json:"availability_zone"`
+	ImageID          string   `json:"image_id"`
+	PrivateIP        string   `json:"private_ip"`
+	EBSVolumes       []string `json:"ebs_volumes"`
+}
+
+type GCPCloudData struct {
+	InstanceName string   `json:"instance_name"`
+	ProjectID    string   `json:"project_id"`
+	Zone         string   `json:"zone"`
+	MachineType  string   `json:"machine_type"`
+	Image        string   `json:"image"`
+	DisksNumber  int      `json:"disks_number"`
+	Networks     []string `json:"networks"`
 }
 
 func (h *Host) ToModel() *models.Host {
@@ -49,16 +100,46 @@ func (h *Host) ToModel() *models.Host {
 		tags = append(tags, tag.Value)
 	}
 
+	var tuning *models.HostTuning
+	if h.Tuning != nil {
+		tuning = h.Tuning.ToModel()
+	}
+
 	return &models.Host{
-		ID:            h.AgentID,
-		Name:          h.Name,
-		IPAddresses:   h.IPAddresses,
-		CloudProvider: h.CloudProvider,
-		ClusterID:     h.ClusterID,
-		ClusterName:   h.ClusterName,
-		ClusterType:   h.ClusterType,
-		AgentVersion:  h.AgentVersion,
-		Tags:          tags,
-		SAPSystems:    h.SAPSystemInstances.ToModel(),
+		ID:               h.AgentID,
+		Name:             h.Name,
+		IPAddresses:      h.IPAddresses,
+		CloudProvider:    h.CloudProvider,
+		ClusterID:        h.ClusterID,
+		ClusterName:      h.ClusterName,
+		ClusterType:      h.ClusterType,
+		AgentVersion:     h.AgentVersion,
+		Tags:             tags,
+		SAPSystems:       h.SAPSystemInstances.ToModel(),
+		SystemdUnits:     h.SystemdUnits.ToModel(),
+		Tuning:           tuning,
+		IdentityConflict: h.identityConflictToModel(),
+	}
+}
+
+// identityConflictToModel returns the identity conflict of the host, unless it's older than the conflict window
+func (h *Host) identityConflictToModel() *models.HostIdentityConflict {
+	if h.IdentityConflictAt == nil || time.Since(*h.IdentityConflictAt) > IdentityConflictWindow {
+		return nil
+	}
+
+	var identities []*models.HostIdentity
+	for _, identity := range h.Identities {
+		identities = append(identities, &models.HostIdentity{
+			Hostname:       identity.Hostname,
+			IPAddresses:    identity.IPAddresses,
+			LastReportedAt: identity.LastReportedAt,
+		})
+	}
+
+	return &models.HostIdentityConflict{
+		AgentID:    h.AgentID,
+		DetectedAt: *h.IdentityConflictAt,
+		Identities: identities,
 	}
 }
@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/trento-project/trento/web/models"
+)
+
+type HostTuning struct {
+	AgentID                  string `gorm:"primaryKey"`
+	Tool                     string
+	SaptuneVersion           string
+	SaptuneConfiguredVersion string
+	Solution                 string
+	Notes                    pq.StringArray `gorm:"type:text[]"`
+	AppliedNotes             pq.StringArray `gorm:"type:text[]"`
+	Compliant                bool
+	SapconfVersion           string
+	SapconfActive            bool
+	SapconfProfile           string
+	UpdatedAt                time.Time
+}
+
+func (t *HostTuning) ToModel() *models.HostTuning {
+	return &models.HostTuning{
+		Tool:                     t.Tool,
+		SaptuneVersion:           t.SaptuneVersion,
+		SaptuneConfiguredVersion: t.SaptuneConfiguredVersion,
+		Solution:                 t.Solution,
+		Notes:                    t.Notes,
+		AppliedNotes:             t.AppliedNotes,
+		Compliant:                t.Compliant,
+		SapconfVersion:           t.SapconfVersion,
+		SapconfActive:            t.SapconfActive,
+		SapconfProfile:           t.SapconfProfile,
+	}
+}
@@ -27,6 +27,9 @@ type SAPSystemInstance struct {
 	DBName                  string
 	DBAddress               string
 	Tenants                 pq.StringArray `gorm:"type:text[]"`
+	HAActive                bool           `gorm:"column:ha_active"`
+	HASAPInterfaceVersion   string         `gorm:"column:ha_sap_interface_version"`
+	HAChecksState           string         `gorm:"column:ha_checks_state"`
 	Host                    *Host          `gorm:"foreignKey:AgentID"`
 	UpdatedAt               time.Time
 	Tags                    []*models.Tag `gorm:"foreignKey:ResourceID"`
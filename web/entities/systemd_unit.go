@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"github.com/trento-project/trento/web/models"
+)
+
+type SystemdUnit struct {
+	AgentID       string `gorm:"primaryKey"`
+	Name          string `gorm:"primaryKey"`
+	Description   string
+	LoadState     string
+	ActiveState   string
+	SubState      string
+	UnitFileState string
+}
+
+type SystemdUnits []*SystemdUnit
+
+func (u *SystemdUnit) ToModel() *models.SystemdUnit {
+	return &models.SystemdUnit{
+		Name:          u.Name,
+		Description:   u.Description,
+		LoadState:     u.LoadState,
+		ActiveState:   u.ActiveState,
+		SubState:      u.SubState,
+		UnitFileState: u.UnitFileState,
+	}
+}
+
+func (units SystemdUnits) ToModel() []*models.SystemdUnit {
+	var modeledUnits []*models.SystemdUnit
+	for _, u := range units {
+		modeledUnits = append(modeledUnits, u.ToModel())
+	}
+
+	return modeledUnits
+}
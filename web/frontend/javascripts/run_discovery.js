@@ -0,0 +1,145 @@
+import React, { useState, useEffect, useCallback } from 'react';
+import ReactDOM from 'react-dom';
+import { get, post } from 'axios';
+import Button from 'react-bootstrap/Button';
+import Spinner from 'react-bootstrap/Spinner';
+
+import { logError } from '@lib/log';
+import Dropdown, { DropdownItem } from '@components/Dropdown';
+import { showSuccessToast, showErrorToast } from '@components/Toast';
+
+const pollingInterval = 2000;
+
+const hostDiscoveries = [
+  { id: 'host_discovery', label: 'Host' },
+  { id: 'sap_system_discovery', label: 'SAP systems' },
+  { id: 'subscription_discovery', label: 'Subscriptions' },
+  { id: 'systemd_discovery', label: 'Systemd units' },
+  { id: 'tuning_discovery', label: 'Tuning' },
+  { id: 'cloud_discovery', label: 'Cloud' },
+];
+
+const clusterDiscovery = 'ha_cluster_discovery';
+
+const pendingStatuses = ['queued', 'picked_up'];
+
+const statusLabels = {
+  queued: 'Queued',
+  picked_up: 'Running',
+};
+
+const isPending = ({ status }) => pendingStatuses.includes(status);
+
+const reportOutcome = (commands) => {
+  const failed = commands.filter(({ status }) => status === 'failed');
+  const expired = commands.filter(({ status }) => status === 'expired');
+
+  if (failed.length > 0) {
+    showErrorToast({
+      content: `Discovery failed: ${failed.map(({ error }) => error).join(', ')}`,
+    });
+  } else if (expired.length > 0) {
+    showErrorToast({
+      content: 'The agent did not pick up the discovery in time.',
+    });
+  } else {
+    showSuccessToast({
+      content: 'Discovery completed, reload the page to see the results.',
+    });
+  }
+};
+
+const RunDiscoveryButton = ({ resource, resourceId }) => {
+  const [commands, setCommands] = useState([]);
+
+  const running = commands.some(isPending);
+
+  useEffect(() => {
+    if (!running) {
+      return;
+    }
+
+    const timer = setTimeout(() => {
+      Promise.all(commands.map(({ id }) => get(`/api/commands/${id}`)))
+        .then((responses) => {
+          const updatedCommands = responses.map(({ data }) => data);
+          setCommands(updatedCommands);
+          if (!updatedCommands.some(isPending)) {
+            reportOutcome(updatedCommands);
+          }
+        })
+        .catch((error) => {
+          logError(error);
+          setCommands([]);
+          showErrorToast({
+            content: 'Error fetching the discovery status.',
+          });
+        });
+    }, pollingInterval);
+
+    return () => clearTimeout(timer);
+  }, [commands, running]);
+
+  const runDiscovery = useCallback(
+    (discoveryType) => {
+      post(`/api/${resource}/${resourceId}/commands`, {
+        discovery_type: discoveryType,
+      })
+        .then(({ data }) => {
+          setCommands(Array.isArray(data) ? data : [data]);
+        })
+        .catch((error) => {
+          logError(error);
+          showErrorToast({
+            content: 'Error queueing the discovery, please retry.',
+          });
+        });
+    },
+    [resource, resourceId]
+  );
+
+  if (running) {
+    const status = commands.every(({ status }) => status === 'queued')
+      ? 'queued'
+      : 'picked_up';
+
+    return (
+      <Button variant="secondary" size="sm" disabled>
+        <Spinner animation="border" role="status" as="span" size="sm" />{' '}
+        {statusLabels[status]}
+      </Button>
+    );
+  }
+
+  if (resource === 'clusters') {
+    return (
+      <Button
+        variant="secondary"
+        size="sm"
+        onClick={() => runDiscovery(clusterDiscovery)}
+      >
+        <i className="eos-icons eos-18">refresh</i>Discover now
+      </Button>
+    );
+  }
+
+  return (
+    <Dropdown className="d-inline-block" label="Discover now">
+      {hostDiscoveries.map(({ id, label }) => (
+        <DropdownItem key={id} onClick={() => runDiscovery(id)}>
+          {label}
+        </DropdownItem>
+      ))}
+    </Dropdown>
+  );
+};
+
+const container = document.getElementById('run-discovery-button');
+
+ReactDOM.render(
+  <RunDiscoveryButton
+    resource={container.dataset.resource}
+    resourceId={container.dataset.id}
+  />,
+  container
+);
@@ -10,6 +10,7 @@ const config = {
     check_results: './javascripts/check_results.js',
     cluster_check_settings: './javascripts/cluster_check_settings.js',
     homepage: './javascripts/homepage.js',
+    run_discovery: './javascripts/run_discovery.js',
   },
   output: {
     path: path.resolve(__dirname, 'assets/js'),
@@ -3,6 +3,7 @@ package web
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -93,7 +94,24 @@ func ApiHostHeartbeatHandler(hostService services.HostsService) gin.HandlerFunc
 	return func(c *gin.Context) {
 		agentID := c.Param("id")
 
-		err := hostService.Heartbeat(agentID)
+		// Agents send the heartbeat time only when replaying spooled heartbeats
+		var heartbeat struct {
+			Timestamp time.Time `json:"timestamp"`
+		}
+		if c.Request.ContentLength != 0 {
+			err := c.BindJSON(&heartbeat)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		timestamp, err := reportedAt(heartbeat.Timestamp)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		err = hostService.Heartbeat(agentID, timestamp)
 		if err != nil {
 			_ = c.Error(err)
 			return
@@ -0,0 +1,94 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+// ApiHostSystemdUnitsHandler godoc
+// @Summary Retrieve the state of the systemd units discovered on a host
+// @Accept json
+// @Produce json
+// @Param id path string true "Host id"
+// @Success 200 {array} models.SystemdUnit
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/systemd_units [get]
+func ApiHostSystemdUnitsHandler(hostsService services.HostsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		host, err := hostsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		units := host.SystemdUnits
+		if units == nil {
+			units = []*models.SystemdUnit{}
+		}
+
+		c.JSON(http.StatusOK, units)
+	}
+}
+
+// ApiHostTuningHandler godoc
+// @Summary Retrieve the SAP tuning state of a host, made by saptune or sapconf
+// @Accept json
+// @Produce json
+// @Param id path string true "Host id"
+// @Success 200 {object} models.HostTuning
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /hosts/{id}/tuning [get]
+func ApiHostTuningHandler(hostsService services.HostsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		host, err := hostsService.GetByID(id)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		if host == nil {
+			_ = c.Error(NotFoundError("could not find host"))
+			return
+		}
+
+		if host.Tuning == nil {
+			_ = c.Error(NotFoundError("the tuning state of the host was not discovered yet"))
+			return
+		}
+
+		c.JSON(http.StatusOK, host.Tuning)
+	}
+}
+
+// ApiHostsIdentityConflictsHandler godoc
+// @Summary Retrieve the agent IDs reported by different hosts, e.g. hosts cloned with the same machine ID
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.HostIdentityConflict
+// @Failure 500 {object} map[string]string
+// @Router /hosts/identity_conflicts [get]
+func ApiHostsIdentityConflictsHandler(hostsService services.HostsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conflicts, err := hostsService.GetIdentityConflicts()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, conflicts)
+	}
+}
@@ -0,0 +1,159 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/web/models"
+	"github.com/trento-project/trento/web/services"
+)
+
+type HostsApiTestCase struct {
+	suite.Suite
+	mockHostsService *services.MockHostsService
+	config           *Config
+	deps             Dependencies
+}
+
+func TestHostsApiTestCase(t *testing.T) {
+	suite.Run(t, new(HostsApiTestCase))
+}
+
+func (suite *HostsApiTestCase) SetupTest() {
+	suite.mockHostsService = new(services.MockHostsService)
+	suite.config = setupTestConfig()
+	suite.deps = setupTestDependencies()
+	suite.deps.hostsService = suite.mockHostsService
+}
+
+func (suite *HostsApiTestCase) getSystemdUnits(id string) *httptest.ResponseRecorder {
+	app, err := NewAppWithDeps(suite.config, suite.deps)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/"+id+"/systemd_units", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func (suite *HostsApiTestCase) getTuning(id string) *httptest.ResponseRecorder {
+	app, err := NewAppWithDeps(suite.config, suite.deps)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/"+id+"/tuning", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func (suite *HostsApiTestCase) getIdentityConflicts() *httptest.ResponseRecorder {
+	app, err := NewAppWithDeps(suite.config, suite.deps)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/hosts/identity_conflicts", nil)
+	req.Header.Set("Accept", "application/json")
+	app.webEngine.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func (suite *HostsApiTestCase) Test_SystemdUnits() {
+	suite.mockHostsService.On("GetByID", "1").Return(hostListFixture()[0], nil)
+
+	resp := suite.getSystemdUnits("1")
+
+	suite.Equal(200, resp.Code)
+	suite.JSONEq(`[
+		{"name": "pacemaker.service", "description": "Pacemaker High Availability Cluster Manager",
+		 "load_state": "loaded", "active_state": "active", "sub_state": "running", "unit_file_state": "enabled"},
+		{"name": "sbd.service", "description": "Shared-storage based fencing daemon",
+		 "load_state": "loaded", "active_state": "failed", "sub_state": "failed", "unit_file_state": "enabled"},
+		{"name": "sapinit.service", "description": "",
+		 "load_state": "not-found", "active_state": "inactive", "sub_state": "dead", "unit_file_state": ""}
+	]`, resp.Body.String())
+}
+
+func (suite *HostsApiTestCase) Test_SystemdUnitsEmpty() {
+	suite.mockHostsService.On("GetByID", "2").Return(&models.Host{ID: "2"}, nil)
+
+	resp := suite.getSystemdUnits("2")
+
+	suite.Equal(200, resp.Code)
+	suite.JSONEq(`[]`, resp.Body.String())
+}
+
+func (suite *HostsApiTestCase) Test_SystemdUnitsHostNotFound() {
+	suite.mockHostsService.On("GetByID", "missing").Return(nil, nil)
+
+	resp := suite.getSystemdUnits("missing")
+
+	suite.Equal(404, resp.Code)
+}
+
+func (suite *HostsApiTestCase) Test_Tuning() {
+	suite.mockHostsService.On("GetByID", "1").Return(hostListFixture()[0], nil)
+
+	resp := suite.getTuning("1")
+
+	suite.Equal(200, resp.Code)
+	suite.JSONEq(`{
+		"tool": "saptune", "saptune_version": "3.1.0", "saptune_configured_version": "3", "solution": "HANA",
+		"notes": ["941735", "1771258", "1980196"], "applied_notes": ["941735", "1771258"], "compliant": false,
+		"sapconf_version": "", "sapconf_active": false, "sapconf_profile": ""
+	}`, resp.Body.String())
+}
+
+func (suite *HostsApiTestCase) Test_TuningNotDiscovered() {
+	suite.mockHostsService.On("GetByID", "2").Return(&models.Host{ID: "2"}, nil)
+
+	resp := suite.getTuning("2")
+
+	suite.Equal(404, resp.Code)
+}
+
+func (suite *HostsApiTestCase) Test_IdentityConflicts() {
+	detectedAt := time.Date(2021, 11, 01, 10, 30, 00, 0, time.UTC)
+	suite.mockHostsService.On("GetIdentityConflicts").Return([]*models.HostIdentityConflict{
+		{
+			AgentID:    "1",
+			DetectedAt: detectedAt,
+			Identities: []*models.HostIdentity{
+				{Hostname: "host1", IPAddresses: []string{"10.74.1.5"}, LastReportedAt: detectedAt},
+				{Hostname: "host1-clone", IPAddresses: []string{"10.74.1.6"}, LastReportedAt: detectedAt.Add(-time.Minute)},
+			},
+		},
+	}, nil)
+
+	resp := suite.getIdentityConflicts()
+
+	suite.Equal(200, resp.Code)
+	suite.JSONEq(`[{
+		"agent_id": "1", "detected_at": "2021-11-01T10:30:00Z",
+		"identities": [
+			{"hostname": "host1", "ip_addresses": ["10.74.1.5"], "last_reported_at": "2021-11-01T10:30:00Z"},
+			{"hostname": "host1-clone", "ip_addresses": ["10.74.1.6"], "last_reported_at": "2021-11-01T10:29:00Z"}
+		]
+	}]`, resp.Body.String())
+}
+
+func (suite *HostsApiTestCase) Test_IdentityConflictsEmpty() {
+	suite.mockHostsService.On("GetIdentityConflicts").Return([]*models.HostIdentityConflict{}, nil)
+
+	resp := suite.getIdentityConflicts()
+
+	suite.Equal(200, resp.Code)
+	suite.JSONEq(`[]`, resp.Body.String())
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -31,6 +33,40 @@ func hostListFixture() models.HostList {
 			AgentVersion: "v1",
 			Tags:         []string{"tag1"},
 			Health:       "passing",
+			AgentHealth:  "passing",
+			SystemdUnits: []*models.SystemdUnit{
+				{
+					Name:          "pacemaker.service",
+					Description:   "Pacemaker High Availability Cluster Manager",
+					LoadState:     "loaded",
+					ActiveState:   "active",
+					SubState:      "running",
+					UnitFileState: "enabled",
+				},
+				{
+					Name:          "sbd.service",
+					Description:   "Shared-storage based fencing daemon",
+					LoadState:     "loaded",
+					ActiveState:   "failed",
+					SubState:      "failed",
+					UnitFileState: "enabled",
+				},
+				{
+					Name:        "sapinit.service",
+					LoadState:   "not-found",
+					ActiveState: "inactive",
+					SubState:    "dead",
+				},
+			},
+			Tuning: &models.HostTuning{
+				Tool:                     "saptune",
+				SaptuneVersion:           "3.1.0",
+				SaptuneConfiguredVersion: "3",
+				Solution:                 "HANA",
+				Notes:                    []string{"941735", "1771258", "1980196"},
+				AppliedNotes:             []string{"941735", "1771258"},
+				Compliant:                false,
+			},
 			CloudData: models.AzureCloudData{
 				VMName:          "host1",
 				ResourceGroup:   "carbonara-resourcegroup",
@@ -40,6 +76,15 @@ func hostListFixture() models.HostList {
 				Offer:           "sales",
 				SKU:             "skeks",
 				AdminUsername:   "toor",
+				ScheduledEvents: []models.AzureScheduledEvent{
+					{
+						ID:                "C7061BAC-AFDC-4513-B24B-AA5F13A16123",
+						Type:              "Freeze",
+						Status:            "Scheduled",
+						NotBefore:         "Mon, 11 Apr 2022 10:00:00 GMT",
+						DurationInSeconds: 5,
+					},
+				},
 			},
 		},
 		{
@@ -57,6 +102,33 @@ func hostListFixture() models.HostList {
 			AgentVersion: "v1",
 			Tags:         []string{"tag2"},
 			Health:       "warning",
+			AgentHealth:  "critical",
+			IdentityConflict: &models.HostIdentityConflict{
+				AgentID:    "2",
+				DetectedAt: time.Date(2021, 11, 01, 10, 30, 00, 0, time.UTC),
+				Identities: []*models.HostIdentity{
+					{
+						Hostname:       "host2",
+						IPAddresses:    []string{"192.168.1.2"},
+						LastReportedAt: time.Date(2021, 11, 01, 10, 30, 00, 0, time.UTC),
+					},
+					{
+						Hostname:       "host2-clone",
+						IPAddresses:    []string{"192.168.1.4", "10.0.1.4"},
+						LastReportedAt: time.Date(2021, 11, 01, 10, 20, 00, 0, time.UTC),
+					},
+				},
+			},
+			CloudData: models.AWSCloudData{
+				InstanceID:       "i-0123456789abcdef0",
+				InstanceType:     "r5b.4xlarge",
+				AccountID:        "123456789012",
+				Region:           "eu-central-1",
+				AvailabilityZone: "eu-central-1a",
+				ImageID:          "ami-0a1b2c3d4e5f67890",
+				PrivateIP:        "10.0.1.10",
+				EBSVolumes:       []string{"sdb", "sdc"},
+			},
 		},
 		{
 			ID:            "1",
@@ -73,6 +145,16 @@ func hostListFixture() models.HostList {
 			AgentVersion: "v1",
 			Tags:         []string{"tag3"},
 			Health:       "critical",
+			AgentHealth:  "critical",
+			CloudData: models.GCPCloudData{
+				InstanceName: "host3",
+				ProjectID:    "sap-project",
+				Zone:         "europe-west1-b",
+				MachineType:  "n1-highmem-32",
+				Image:        "sles-15-sp1-sap-v20220126",
+				DisksNumber:  3,
+				Networks:     []string{"network"},
+			},
 		},
 	}
 }
@@ -128,6 +210,8 @@ func TestHostListNextHandler(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile("<select name=sids.*>.*PRD.*QAS.*DEV.*</select>"), minified)
 	assert.Regexp(t, regexp.MustCompile(".*check_circle.*<td .*>.*host1.*</td><td>192.168.1.1</td><td>.*azure.*</td><td>.*databases/sap_system_id_1.*PRD.*</td><td>v1</td><td .*>.*<input.*value=tag1.*>.*</td>"), minified)
 	assert.Regexp(t, regexp.MustCompile(".*warning.*<td .*>.*host2.*</td><td>192.168.1.2</td><td>.*aws.*</td><td>.*sapsystems/sap_system_id_2.*QAS.*</td><td>v1</td><td .*>.*<input.*value=tag2.*>.*</td>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<a href=/hosts/2>host2</a> <span class=\"badge badge-pill badge-danger\">identity conflict</span>"), minified)
+	assert.NotRegexp(t, regexp.MustCompile("host1</a><span[^>]*>identity conflict"), minified)
 	assert.Regexp(t, regexp.MustCompile(".*error.*<td .*>.*host3.*</td><td>192.168.1.3</td><td>.*gcp.*</td><td>.*sapsystems/sap_system_id_3.*DEV.*</td><td>v1</td><td .*>.*<input.*value=tag3.*>.*</td>"), minified)
 }
 
@@ -135,7 +219,7 @@ func TestApiHostHeartbeat(t *testing.T) {
 	agentID := "agent_id"
 
 	mockHostsService := new(services.MockHostsService)
-	mockHostsService.On("Heartbeat", agentID).Return(nil)
+	mockHostsService.On("Heartbeat", agentID, mock.AnythingOfType("time.Time")).Return(nil)
 
 	deps := setupTestDependencies()
 	deps.hostsService = mockHostsService
@@ -154,6 +238,69 @@ func TestApiHostHeartbeat(t *testing.T) {
 	assert.Equal(t, 204, resp.Code)
 }
 
+func TestApiHostHeartbeatReplayed(t *testing.T) {
+	agentID := "agent_id"
+	timestamp := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("Heartbeat", agentID, timestamp).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	url := fmt.Sprintf("/api/hosts/%s/heartbeat", agentID)
+	req := httptest.NewRequest("POST", url, strings.NewReader(fmt.Sprintf(`{"timestamp": "%s"}`, timestamp.Format(time.RFC3339))))
+
+	app.collectorEngine.ServeHTTP(resp, req)
+
+	assert.Equal(t, 204, resp.Code)
+	mockHostsService.AssertExpectations(t)
+}
+
+func TestApiHostHeartbeatSkewedClock(t *testing.T) {
+	agentID := "agent_id"
+
+	mockHostsService := new(services.MockHostsService)
+	mockHostsService.On("Heartbeat", agentID, mock.MatchedBy(func(timestamp time.Time) bool {
+		return !timestamp.After(time.Now())
+	})).Return(nil)
+
+	deps := setupTestDependencies()
+	deps.hostsService = mockHostsService
+
+	app, err := NewAppWithDeps(setupTestConfig(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		timestamp    time.Time
+		expectedCode int
+	}{
+		{time.Now().Add(time.Minute), 204},
+		{time.Now().Add(time.Hour), 400},
+		{time.Now().Add(-30 * 24 * time.Hour), 400},
+	}
+
+	for _, c := range cases {
+		resp := httptest.NewRecorder()
+		url := fmt.Sprintf("/api/hosts/%s/heartbeat", agentID)
+		req := httptest.NewRequest("POST", url, strings.NewReader(fmt.Sprintf(`{"timestamp": "%s"}`, c.timestamp.Format(time.RFC3339))))
+
+		app.collectorEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, c.expectedCode, resp.Code, c.timestamp)
+	}
+
+	mockHostsService.AssertNumberOfCalls(t, "Heartbeat", 1)
+}
+
 func TestHostHandler(t *testing.T) {
 	subscriptionsMocks := new(services.MockSubscriptionsService)
 	mockHostsService := new(services.MockHostsService)
@@ -216,14 +363,24 @@ func TestHostHandler(t *testing.T) {
 
 	assert.Equal(t, 200, resp.Code)
 	assert.Contains(t, minified, "Host details")
+	assert.Regexp(t, regexp.MustCompile(`<span id=run-discovery-button data-resource=hosts data-id=[^>]+></span>`), minified)
 
 	assert.Regexp(t, regexp.MustCompile("<span.*>host2</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<div class=\"alert alert-danger\" role=alert><span.*>identity conflict</span> The agent ID 2 is reported by different hosts"), minified)
+	assert.Regexp(t, regexp.MustCompile("<li>host2 \\(192.168.1.2\\), last reported at 2021-11-01 10:30:00 UTC</li>"+
+		"<li>host2-clone \\(192.168.1.4, 10.0.1.4\\), last reported at 2021-11-01 10:20:00 UTC</li>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<a.*sapsystems/sap_system_id_2.*>QAS</a>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<span.*>v1</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<td>Trento agent</td><td><span.*>not running</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<td>Node exporter</td><td><span.*>running</span>"), minified)
 	assert.Regexp(t, regexp.MustCompile("<td>Other exporter</td><td><span.*>not running</span>"), minified)
 
+	// Cloud details
+	assert.Regexp(t, regexp.MustCompile("<strong>Instance ID:</strong><br><span.*>i-0123456789abcdef0</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Region:</strong><br><span.*>eu-central-1 \\(eu-central-1a\\)</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>AMI:</strong><br><span.*>ami-0a1b2c3d4e5f67890</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>EBS volumes:</strong><br><span.*>sdb, sdc</span>"), minified)
+
 	// Subscriptions
 	assert.Regexp(t, regexp.MustCompile(
 		"<td>SLES_SAP</td><td>x64_84</td><td>15.2</td><td>internal</td><td>Registered</td>"+
@@ -303,6 +460,73 @@ func TestHostHandlerAzure(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile(
 		"<td>sle-module-desktop-applications</td><td>x64_84</td><td>15.2</td><td></td>"+
 			"<td>Registered</td><td></td><td></td><td></td>"), minified)
+
+	// Systemd units
+	assert.Regexp(t, regexp.MustCompile(
+		"<td>pacemaker.service</td><td>Pacemaker High Availability Cluster Manager</td><td>enabled</td>"+
+			"<td><span class=\"badge badge-pill badge-primary\">active \\(running\\)</span></td>"), minified)
+	assert.Regexp(t, regexp.MustCompile(
+		"<td>sbd.service</td><td>Shared-storage based fencing daemon</td><td>enabled</td>"+
+			"<td><span class=\"badge badge-pill badge-danger\">failed \\(failed\\)</span></td>"), minified)
+	assert.Regexp(t, regexp.MustCompile(
+		"<td>sapinit.service</td><td></td><td>not installed</td>"+
+			"<td><span class=\"badge badge-pill badge-secondary\">inactive \\(dead\\)</span></td>"), minified)
+
+	// SAP tuning
+	assert.Contains(t, minified, "The host is not compliant with the enabled saptune notes")
+	assert.Regexp(t, regexp.MustCompile("<strong>Tuned by:</strong><br><span.*>saptune</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Solution:</strong><br><span.*>HANA</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Enabled notes:</strong><br><span.*>941735, 1771258, 1980196</span>"), minified)
+
+	// Scheduled events
+	assert.Contains(t, minified, "scheduled maintenance events impacting this virtual machine")
+	assert.Regexp(t, regexp.MustCompile(
+		"<td>C7061BAC-AFDC-4513-B24B-AA5F13A16123</td><td>Freeze</td><td>Scheduled</td>"+
+			"<td>Mon, 11 Apr 2022 10:00:00 GMT</td><td>5</td><td></td>"), minified)
+}
+
+func TestHostHandlerGcp(t *testing.T) {
+	subscriptionsMocks := new(services.MockSubscriptionsService)
+	mockHostsService := new(services.MockHostsService)
+
+	subscriptionsMocks.On("GetHostSubscriptions", "3").Return([]*models.SlesSubscription{}, nil)
+	subscriptionsMocks.On("IsTrentoPremium").Return(true, nil)
+	mockHostsService.On("GetByID", "3").Return(hostListFixture()[2], nil)
+	mockHostsService.On("GetExportersState", "host3").Return(map[string]string{}, nil)
+
+	deps := setupTestDependencies()
+	deps.subscriptionsService = subscriptionsMocks
+	deps.hostsService = mockHostsService
+
+	config := setupTestConfig()
+	app, err := NewAppWithDeps(config, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hosts/3", nil)
+	req.Header.Set("Accept", "text/html")
+
+	app.webEngine.ServeHTTP(resp, req)
+
+	m := minify.New()
+	m.AddFunc("text/html", html.Minify)
+	m.Add("text/html", &html.Minifier{
+		KeepDefaultAttrVals: true,
+		KeepEndTags:         true,
+	})
+	minified, err := m.String("text/html", resp.Body.String())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Regexp(t, regexp.MustCompile("<strong>Project ID:</strong><br><span.*>sap-project</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Zone:</strong><br><span.*>europe-west1-b</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Machine type:</strong><br><span.*>n1-highmem-32</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Disks number:</strong><br><span.*>3</span>"), minified)
+	assert.Regexp(t, regexp.MustCompile("<strong>Networks:</strong><br><span.*>network</span>"), minified)
 }
 
 func TestHostHandler404Error(t *testing.T) {
@@ -1,7 +1,12 @@
 package web
 
 import (
+	"compress/gzip"
+	"crypto/subtle"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -28,3 +33,114 @@ func EulaMiddleware(premiumDetection services.PremiumDetectionService) gin.Handl
 		c.Next()
 	}
 }
+
+// errRequestBodyTooLarge is returned while reading a request body exceeding the size limit
+var errRequestBodyTooLarge = errors.New("request body too large")
+
+// DecompressRequestMiddleware transparently decompresses the gzip encoded request bodies,
+// advertising the support to the clients with the Accept-Encoding response header.
+// The size limit applies to the decompressed body, reading past it returns errRequestBodyTooLarge
+func DecompressRequestMiddleware(maxBodySize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Accept-Encoding", "gzip")
+
+		switch c.GetHeader("Content-Encoding") {
+		case "", "identity":
+		case "gzip":
+			reader, err := gzip.NewReader(c.Request.Body)
+			if err != nil {
+				_ = c.AbortWithError(http.StatusBadRequest, err)
+				return
+			}
+			defer reader.Close()
+
+			c.Request.Body = reader
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.ContentLength = -1
+		default:
+			c.AbortWithStatus(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		c.Request.Body = &limitedBody{ReadCloser: c.Request.Body, remaining: maxBodySize}
+		c.Next()
+	}
+}
+
+// authenticatedAgentKey is the context key of the agent ID authenticated by AgentAuthMiddleware
+const authenticatedAgentKey = "authenticated_agent_id"
+
+// AgentAuthMiddleware authenticates the agents with the HTTP basic authentication, where the username is
+// the agent ID and the password the credential obtained with an enrollment token.
+// The requests about a specific agent, e.g. the heartbeats, are accepted only from that agent
+func AgentAuthMiddleware(enrollmentService services.EnrollmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agentID, secret, ok := c.Request.BasicAuth()
+		if !ok {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		authenticated, err := enrollmentService.Authenticate(agentID, secret)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		if !authenticated {
+			log.Warnf("Agent %s failed to authenticate", agentID)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		if id := c.Param("id"); id != "" && id != agentID {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		c.Set(authenticatedAgentKey, agentID)
+		c.Next()
+	}
+}
+
+// AdminAuthMiddleware requires the admin token as a bearer token, so that only the administrators can
+// create enrollment tokens or revoke and reset the agent credentials.
+// Without an admin token configured, every request is forbidden
+func AdminAuthMiddleware(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminToken == "" {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			log.Warnf("Unauthorized request to %s", c.Request.URL.Path)
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		return n, errRequestBodyTooLarge
+	}
+	b.remaining -= int64(n)
+
+	return n, err
+}
@@ -67,3 +67,43 @@ func TestEulaMiddlewareError(t *testing.T) {
 
 	assert.Equal(t, 500, resp.Code)
 }
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	enrollmentService := new(services.MockEnrollmentService)
+	enrollmentService.On("GetAllAgentCredentials").Return(nil, nil)
+
+	cases := []struct {
+		adminToken    string
+		authorization string
+		expectedCode  int
+	}{
+		{"admin_token", "Bearer admin_token", 200},
+		{"admin_token", "Bearer wrong_token", 401},
+		{"admin_token", "", 401},
+		{"", "Bearer ", 403},
+		{"", "", 403},
+	}
+
+	for _, c := range cases {
+		deps := setupTestDependencies()
+		deps.enrollmentService = enrollmentService
+
+		config := setupTestConfig()
+		config.AdminToken = c.adminToken
+
+		app, err := NewAppWithDeps(config, deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/enrollment/agents", nil)
+		if c.authorization != "" {
+			req.Header.Set("Authorization", c.authorization)
+		}
+
+		app.webEngine.ServeHTTP(resp, req)
+
+		assert.Equal(t, c.expectedCode, resp.Code, c.authorization)
+	}
+}
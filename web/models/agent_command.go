@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	// AgentCommandRunDiscovery asks the agent to run a discovery right away
+	AgentCommandRunDiscovery = "run_discovery"
+
+	AgentCommandQueued    = "queued"
+	AgentCommandPickedUp  = "picked_up"
+	AgentCommandCompleted = "completed"
+	AgentCommandFailed    = "failed"
+	AgentCommandExpired   = "expired"
+)
+
+type AgentCommand struct {
+	ID            string     `json:"id"`
+	AgentID       string     `json:"agent_id"`
+	Type          string     `json:"type"`
+	DiscoveryType string     `json:"discovery_type"`
+	Status        string     `json:"status"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	PickedUpAt    *time.Time `json:"picked_up_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+}
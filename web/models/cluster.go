@@ -5,6 +5,7 @@ import "time"
 const (
 	ClusterTypeHANAScaleUp  = "HANA scale-up"
 	ClusterTypeHANAScaleOut = "HANA scale-out"
+	ClusterTypeASCSERS      = "ASCS/ERS"
 	ClusterTypeUnknown      = "Unknown"
 	HANAStatusPrimary       = "Primary"
 	HANAStatusSecondary     = "Secondary"
@@ -17,13 +18,29 @@ const (
 	// https://github.com/SUSE/SAPHanaSR/blob/master/ra/SAPHana#L1171
 	HANASrHealthOK = "4"
 	HANASrSyncSOK  = "SOK"
+	// Standalone enqueue server versions, see SAP note 2630416
+	EnsaVersion1    = "ENSA1"
+	EnsaVersion2    = "ENSA2"
+	ASCSERSRoleASCS = "ASCS"
+	ASCSERSRoleERS  = "ERS"
+	// Pacemaker node states, as reported by crm_mon
+	ClusterNodeStateOnline      = "online"
+	ClusterNodeStateOffline     = "offline"
+	ClusterNodeStateStandby     = "standby"
+	ClusterNodeStateMaintenance = "maintenance"
+	ClusterNodeStateUnclean     = "unclean"
+	ClusterNodeStatePending     = "pending"
+	ClusterNodeStateShutdown    = "shutdown"
+	// Location constraints created by crm resource move and crm resource ban
+	LocationConstraintTypeMove = "move"
+	LocationConstraintTypeBan  = "ban"
 )
 
 type Cluster struct {
 	ID              string
 	Name            string
 	ClusterType     string
-	SID             string
+	SIDs            []string
 	ResourcesNumber int
 	HostsNumber     int
 	Health          string
@@ -45,17 +62,83 @@ type HANAClusterDetails struct {
 	SRHealthState                  string
 	CIBLastWritten                 time.Time
 	FencingType                    string
+	FencingResources               []*FencingResource
 	StoppedResources               []*ClusterResource
+	LocationConstraints            []*LocationConstraint
 	Nodes                          ClusterNodes
 	SBDDevices                     []*SBDDevice
+	Corosync                       *CorosyncDetails
+	SystemReplications             []*HANASystemReplication
+}
+
+type HANASystemReplication struct {
+	SID                string
+	Mode               string
+	OperationMode      string
+	SecondarySyncState string
+	SRHealthState      string
+}
+
+type GenericClusterDetails struct {
+	CIBLastWritten      time.Time
+	FencingType         string
+	FencingResources    []*FencingResource
+	StoppedResources    []*ClusterResource
+	LocationConstraints []*LocationConstraint
+	Nodes               ClusterNodes
+	SBDDevices          []*SBDDevice
+	Corosync            *CorosyncDetails
+}
+
+type ASCSERSClusterDetails struct {
+	SID                 string
+	EnsaVersion         string
+	CIBLastWritten      time.Time
+	FencingType         string
+	FencingResources    []*FencingResource
+	StoppedResources    []*ClusterResource
+	LocationConstraints []*LocationConstraint
+	Instances           []*ASCSERSInstance
+	Nodes               ClusterNodes
+	SBDDevices          []*SBDDevice
+	Corosync            *CorosyncDetails
+}
+
+type ASCSERSInstance struct {
+	Role            string
+	InstanceName    string
+	InstanceNumber  string
+	VirtualHostname string
+	ResourceID      string
+	Node            string
+	HostID          string
+	Status          string
+	VirtualIPs      []string
+	Filesystems     []*ClusterFilesystem
+	HAConnector     *SAPHAConnector
+}
+
+type ClusterFilesystem struct {
+	ResourceID string
+	Device     string
+	Directory  string
+	FSType     string
+}
+
+// SAPHAConnector is the state of the SAP HA interface of an instance, as reported by sapstartsrv
+type SAPHAConnector struct {
+	Active              bool
+	SAPInterfaceVersion string
+	ChecksState         string
 }
 
 type ClusterResource struct {
-	ID        string
-	Type      string
-	Role      string
-	Status    string
-	FailCount int
+	ID                 string
+	Type               string
+	Role               string
+	Status             string
+	FailCount          int
+	MigrationThreshold int
 }
 
 type HANAClusterNode struct {
@@ -68,13 +151,101 @@ type HANAClusterNode struct {
 	HANAStatus  string
 	Attributes  map[string]string
 	Resources   []*ClusterResource
+	Online      bool
+	Standby     bool
+	Maintenance bool
+	Unclean     bool
+	Pending     bool
+	Shutdown    bool
+}
+
+// PacemakerState returns the most relevant state of the node from the Pacemaker point of view
+func (n *HANAClusterNode) PacemakerState() string {
+	switch {
+	case n.Unclean:
+		return ClusterNodeStateUnclean
+	case !n.Online:
+		return ClusterNodeStateOffline
+	case n.Pending:
+		return ClusterNodeStatePending
+	case n.Shutdown:
+		return ClusterNodeStateShutdown
+	case n.Standby:
+		return ClusterNodeStateStandby
+	case n.Maintenance:
+		return ClusterNodeStateMaintenance
+	default:
+		return ClusterNodeStateOnline
+	}
+}
+
+type LocationConstraint struct {
+	ID       string
+	Type     string
+	Resource string
+	Node     string
+	Role     string
+	Score    string
+}
+
+type FencingResource struct {
+	ID                 string
+	Agent              string
+	Status             string
+	Disabled           bool
+	InstanceAttributes map[string]string
+	MetaAttributes     map[string]string
 }
 
 type SBDDevice struct {
-	Device string
+	Device          string
+	Status          string
+	TimeoutWatchdog int
+	TimeoutMsgwait  int
+	Nodes           []*SBDNode
+}
+
+type SBDNode struct {
+	ID     int
+	Name   string
 	Status string
 }
 
+type CorosyncDetails struct {
+	Transport     string
+	RRPMode       string
+	Token         int
+	Consensus     int
+	ExpectedVotes int
+	TwoNode       bool
+	Nodes         []*CorosyncNode
+	Rings         []*CorosyncRing
+}
+
+type CorosyncNode struct {
+	ID        int
+	Name      string
+	Addresses []string
+}
+
+type CorosyncRing struct {
+	ID      int
+	Address string
+	Status  string
+	Faulty  bool
+}
+
+// HasFaultyRings returns true when any of the corosync rings/links is not working properly
+func (c *CorosyncDetails) HasFaultyRings() bool {
+	for _, r := range c.Rings {
+		if r.Faulty {
+			return true
+		}
+	}
+
+	return false
+}
+
 type ClusterNodes []*HANAClusterNode
 
 func (n ClusterNodes) GroupBySite() map[string]ClusterNodes {
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type EnrollmentToken struct {
+	ID string `json:"id"`
+	// The token is returned only once, when it's created
+	Token     string     `json:"token,omitempty"`
+	SingleUse bool       `json:"single_use"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type AgentCredential struct {
+	AgentID   string     `json:"agent_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
@@ -17,3 +17,13 @@ type SAPSystemHealthSummary struct {
 	DatabaseHealth  string `json:"database_health"`
 	HostsHealth     string `json:"hosts_health"`
 }
+
+type HealthState struct {
+	Health         string                    `json:"health"`
+	PartialHealths map[string]*PartialHealth `json:"partial_healths"`
+}
+
+type PartialHealth struct {
+	Health string `json:"health"`
+	Reason string `json:"reason,omitempty"`
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/trento-project/trento/internal/cloud"
 )
 
@@ -19,6 +21,7 @@ type Host struct {
 	ID            string
 	Name          string
 	Health        string
+	AgentHealth   string
 	IPAddresses   []string
 	CloudProvider string
 	ClusterID     string
@@ -28,17 +31,64 @@ type Host struct {
 	AgentVersion  string
 	Tags          []string
 	CloudData     interface{}
+	SystemdUnits  []*SystemdUnit
+	Tuning        *HostTuning
+	// Set when the agent ID is reported by different hosts, e.g. cloned from an image with the same machine ID
+	IdentityConflict *HostIdentityConflict
+}
+
+type HostIdentityConflict struct {
+	AgentID    string          `json:"agent_id"`
+	DetectedAt time.Time       `json:"detected_at"`
+	Identities []*HostIdentity `json:"identities"`
+}
+
+type HostIdentity struct {
+	Hostname       string    `json:"hostname"`
+	IPAddresses    []string  `json:"ip_addresses"`
+	LastReportedAt time.Time `json:"last_reported_at"`
 }
 
 type AzureCloudData struct {
-	VMName          string `json:"vmname"`
-	ResourceGroup   string `json:"resource_group"`
-	Location        string `json:"location"`
-	VMSize          string `json:"vmsize"`
-	DataDisksNumber int    `json:"data_disks_number"`
-	Offer           string `json:"offer"`
-	SKU             string `json:"sku"`
-	AdminUsername   string `json:"admin_username"`
+	VMName          string                `json:"vmname"`
+	ResourceGroup   string                `json:"resource_group"`
+	Location        string                `json:"location"`
+	VMSize          string                `json:"vmsize"`
+	DataDisksNumber int                   `json:"data_disks_number"`
+	Offer           string                `json:"offer"`
+	SKU             string                `json:"sku"`
+	AdminUsername   string                `json:"admin_username"`
+	ScheduledEvents []AzureScheduledEvent `json:"scheduled_events"`
+}
+
+type AzureScheduledEvent struct {
+	ID                string `json:"id"`
+	Type              string `json:"type"`
+	Status            string `json:"status"`
+	NotBefore         string `json:"not_before"`
+	Description       string `json:"description"`
+	DurationInSeconds int    `json:"duration_in_seconds"`
+}
+
+type AWSCloudData struct {
+	InstanceID       string   `json:"instance_id"`
+	InstanceType     string   `json:"instance_type"`
+	AccountID        string   `json:"account_id"`
+	Region           string   `json:"region"`
+	AvailabilityZone string   `json:"availability_zone"`
+	ImageID          string   `json:"image_id"`
+	PrivateIP        string   `json:"private_ip"`
+	EBSVolumes       []string `json:"ebs_volumes"`
+}
+
+type GCPCloudData struct {
+	InstanceName string   `json:"instance_name"`
+	ProjectID    string   `json:"project_id"`
+	Zone         string   `json:"zone"`
+	MachineType  string   `json:"machine_type"`
+	Image        string   `json:"image"`
+	DisksNumber  int      `json:"disks_number"`
+	Networks     []string `json:"networks"`
 }
 
 type HostList []*Host
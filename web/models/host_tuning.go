@@ -0,0 +1,29 @@
+package models
+
+const (
+	TuningToolSaptune = "saptune"
+	TuningToolSapconf = "sapconf"
+)
+
+type HostTuning struct {
+	Tool                     string   `json:"tool"`
+	SaptuneVersion           string   `json:"saptune_version"`
+	SaptuneConfiguredVersion string   `json:"saptune_configured_version"`
+	Solution                 string   `json:"solution"`
+	Notes                    []string `json:"notes"`
+	AppliedNotes             []string `json:"applied_notes"`
+	Compliant                bool     `json:"compliant"`
+	SapconfVersion           string   `json:"sapconf_version"`
+	SapconfActive            bool     `json:"sapconf_active"`
+	SapconfProfile           string   `json:"sapconf_profile"`
+}
+
+// IsTuned returns true when the host is tuned either by saptune or sapconf
+func (t *HostTuning) IsTuned() bool {
+	return t.Tool != ""
+}
+
+// IsSaptune returns true when the host is tuned by saptune
+func (t *HostTuning) IsSaptune() bool {
+	return t.Tool == TuningToolSaptune
+}
@@ -0,0 +1,23 @@
+package models
+
+const (
+	SystemdUnitActiveStateFailed = "failed"
+	SystemdUnitLoadStateNotFound = "not-found"
+)
+
+type SystemdUnit struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	LoadState     string `json:"load_state"`
+	ActiveState   string `json:"active_state"`
+	SubState      string `json:"sub_state"`
+	UnitFileState string `json:"unit_file_state"`
+}
+
+func (u *SystemdUnit) IsFailed() bool {
+	return u.ActiveState == SystemdUnitActiveStateFailed
+}
+
+func (u *SystemdUnit) IsInstalled() bool {
+	return u.LoadState != SystemdUnitLoadStateNotFound
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Time after which a command not picked up by its agent is not delivered anymore
+	agentCommandTTL = 10 * time.Minute
+	// Number of most recent commands listed for an agent
+	maxListedAgentCommands = 50
+)
+
+var (
+	ErrAgentCommandNotFound  = errors.New("agent command not found")
+	ErrAgentCommandNoTargets = errors.New("no host to queue the agent command for")
+)
+
+//go:generate mockery --name=AgentCommandsService --inpackage --filename=agent_commands_mock.go
+
+// AgentCommandsService queues the commands the agents pick up after their heartbeat,
+// tracking when they were picked up and completed
+type AgentCommandsService interface {
+	QueueHostDiscovery(agentID string, discoveryType string) (*models.AgentCommand, error)
+	QueueClusterDiscovery(clusterID string, discoveryType string) ([]*models.AgentCommand, error)
+	GetByID(id string) (*models.AgentCommand, error)
+	GetAllByAgentID(agentID string) ([]*models.AgentCommand, error)
+	PickUpPending(agentID string) ([]*models.AgentCommand, error)
+	Complete(agentID string, id string, errorMessage string) error
+}
+
+type agentCommandsService struct {
+	db *gorm.DB
+}
+
+func NewAgentCommandsService(db *gorm.DB) *agentCommandsService {
+	return &agentCommandsService{db: db}
+}
+
+// QueueHostDiscovery asks the agent of the host to run the discovery right away
+func (s *agentCommandsService) QueueHostDiscovery(agentID string, discoveryType string) (*models.AgentCommand, error) {
+	commands, err := s.queueDiscovery(s.db.Where("agent_id", agentID), discoveryType)
+	if err != nil {
+		return nil, err
+	}
+
+	return commands[0], nil
+}
+
+// QueueClusterDiscovery asks the agents of all the cluster nodes to run the discovery right away
+func (s *agentCommandsService) QueueClusterDiscovery(clusterID string, discoveryType string) ([]*models.AgentCommand, error) {
+	return s.queueDiscovery(s.db.Where("cluster_id", clusterID), discoveryType)
+}
+
+// queueDiscovery queues the discovery for the agents of the hosts matched by the query.
+// An agent already having the same discovery queued gets no new command, the queued one is returned instead
+func (s *agentCommandsService) queueDiscovery(hostsQuery *gorm.DB, discoveryType string) ([]*models.AgentCommand, error) {
+	var agentIDs []string
+	err := hostsQuery.Model(&entities.Host{}).Order("agent_id").Pluck("agent_id", &agentIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(agentIDs) == 0 {
+		return nil, ErrAgentCommandNoTargets
+	}
+
+	var modeledCommands []*models.AgentCommand
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, agentID := range agentIDs {
+			var command entities.AgentCommand
+			err := tx.Where("agent_id = ? AND type = ? AND discovery_type = ?", agentID, models.AgentCommandRunDiscovery, discoveryType).
+				Where("picked_up_at IS NULL AND expires_at > ?", now).
+				First(&command).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				command = entities.AgentCommand{
+					ID:            uuid.New().String(),
+					AgentID:       agentID,
+					Type:          models.AgentCommandRunDiscovery,
+					DiscoveryType: discoveryType,
+					CreatedAt:     now,
+					ExpiresAt:     now.Add(agentCommandTTL),
+				}
+				err = tx.Create(&command).Error
+			}
+			if err != nil {
+				return err
+			}
+
+			modeledCommands = append(modeledCommands, command.ToModel())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return modeledCommands, nil
+}
+
+func (s *agentCommandsService) GetByID(id string) (*models.AgentCommand, error) {
+	var command entities.AgentCommand
+	err := s.db.Where("id", id).First(&command).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAgentCommandNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return command.ToModel(), nil
+}
+
+// GetAllByAgentID returns the most recent commands of the agent, newest first
+func (s *agentCommandsService) GetAllByAgentID(agentID string) ([]*models.AgentCommand, error) {
+	var commands []entities.AgentCommand
+	err := s.db.Where("agent_id", agentID).
+		Order("created_at DESC").
+		Limit(maxListedAgentCommands).
+		Find(&commands).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var modeledCommands []*models.AgentCommand
+	for _, c := range commands {
+		modeledCommands = append(modeledCommands, c.ToModel())
+	}
+
+	return modeledCommands, nil
+}
+
+// PickUpPending returns the commands queued for the agent and not expired, marking them as picked up
+// so they are delivered only once
+func (s *agentCommandsService) PickUpPending(agentID string) ([]*models.AgentCommand, error) {
+	var modeledCommands []*models.AgentCommand
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var commands []entities.AgentCommand
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("agent_id = ? AND picked_up_at IS NULL AND expires_at > ?", agentID, now).
+			Order("created_at").
+			Find(&commands).Error
+		if err != nil {
+			return err
+		}
+
+		for _, c := range commands {
+			c.PickedUpAt = &now
+			if err := tx.Model(&c).Update("picked_up_at", now).Error; err != nil {
+				return err
+			}
+			modeledCommands = append(modeledCommands, c.ToModel())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return modeledCommands, nil
+}
+
+// Complete records the completion of a command picked up by the agent, an error message marks it as failed
+func (s *agentCommandsService) Complete(agentID string, id string, errorMessage string) error {
+	result := s.db.Model(&entities.AgentCommand{}).
+		Where("id = ? AND agent_id = ? AND picked_up_at IS NOT NULL", id, agentID).
+		Updates(map[string]interface{}{
+			"completed_at": time.Now(),
+			"error":        errorMessage,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrAgentCommandNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+)
+
+type AgentCommandsServiceTestSuite struct {
+	suite.Suite
+	db                   *gorm.DB
+	tx                   *gorm.DB
+	agentCommandsService *agentCommandsService
+}
+
+func TestAgentCommandsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AgentCommandsServiceTestSuite))
+}
+
+func (suite *AgentCommandsServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.AgentCommand{}, &entities.Host{})
+}
+
+func (suite *AgentCommandsServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.AgentCommand{}, &entities.Host{})
+}
+
+func (suite *AgentCommandsServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.agentCommandsService = NewAgentCommandsService(suite.tx)
+
+	suite.tx.Create(&[]entities.Host{
+		{AgentID: "1", Name: "node1", ClusterID: "cluster_id"},
+		{AgentID: "2", Name: "node2", ClusterID: "cluster_id"},
+		{AgentID: "3", Name: "host3"},
+	})
+}
+
+func (suite *AgentCommandsServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *AgentCommandsServiceTestSuite) TestAgentCommandsService_QueueHostDiscovery() {
+	command, err := suite.agentCommandsService.QueueHostDiscovery("3", "host_discovery")
+
+	suite.NoError(err)
+	suite.Equal("3", command.AgentID)
+	suite.Equal(models.AgentCommandRunDiscovery, command.Type)
+	suite.Equal("host_discovery", command.DiscoveryType)
+	suite.Equal(models.AgentCommandQueued, command.Status)
+
+	queuedAgain, err := suite.agentCommandsService.QueueHostDiscovery("3", "host_discovery")
+
+	suite.NoError(err)
+	suite.Equal(command.ID, queuedAgain.ID)
+
+	other, err := suite.agentCommandsService.QueueHostDiscovery("3", "cloud_discovery")
+
+	suite.NoError(err)
+	suite.NotEqual(command.ID, other.ID)
+}
+
+func (suite *AgentCommandsServiceTestSuite) TestAgentCommandsService_QueueHostDiscovery_UnknownHost() {
+	_, err := suite.agentCommandsService.QueueHostDiscovery("unknown", "host_discovery")
+
+	suite.ErrorIs(err, ErrAgentCommandNoTargets)
+}
+
+func (suite *AgentCommandsServiceTestSuite) TestAgentCommandsService_QueueClusterDiscovery() {
+	commands, err := suite.agentCommandsService.QueueClusterDiscovery("cluster_id", "ha_cluster_discovery")
+
+	suite.NoError(err)
+	suite.Len(commands, 2)
+	suite.Equal("1", commands[0].AgentID)
+	suite.Equal("2", commands[1].AgentID)
+
+	_, err = suite.agentCommandsService.QueueClusterDiscovery("unknown", "ha_cluster_discovery")
+
+	suite.ErrorIs(err, ErrAgentCommandNoTargets)
+}
+
+func (suite *AgentCommandsServiceTestSuite) TestAgentCommandsService_PickUpAndComplete() {
+	command, _ := suite.agentCommandsService.QueueHostDiscovery("3", "host_discovery")
+	failing, _ := suite.agentCommandsService.QueueHostDiscovery("3", "cloud_discovery")
+
+	err := suite.agentCommandsService.Complete("3", command.ID, "")
+	suite.ErrorIs(err, ErrAgentCommandNotFound)
+
+	pending, err := suite.agentCommandsService.PickUpPending("3")
+
+	suite.NoError(err)
+	suite.Len(pending, 2)
+	suite.Equal(command.ID, pending[0].ID)
+	suite.Equal(models.AgentCommandPickedUp, pending[0].Status)
+
+	pending, err = suite.agentCommandsService.PickUpPending("3")
+
+	suite.NoError(err)
+	suite.Empty(pending)
+
+	suite.NoError(suite.agentCommandsService.Complete("3", command.ID, ""))
+	suite.NoError(suite.agentCommandsService.Complete("3", failing.ID, "discovery failed"))
+	suite.ErrorIs(suite.agentCommandsService.Complete("1", command.ID, ""), ErrAgentCommandNotFound)
+
+	completed, err := suite.agentCommandsService.GetByID(command.ID)
+
+	suite.NoError(err)
+	suite.Equal(models.AgentCommandCompleted, completed.Status)
+	suite.NotNil(completed.PickedUpAt)
+	suite.NotNil(completed.CompletedAt)
+
+	failed, err := suite.agentCommandsService.GetByID(failing.ID)
+
+	suite.NoError(err)
+	suite.Equal(models.AgentCommandFailed, failed.Status)
+	suite.Equal("discovery failed", failed.Error)
+}
+
+func (suite *AgentCommandsServiceTestSuite) TestAgentCommandsService_PickUpPending_Expired() {
+	suite.tx.Create(&entities.AgentCommand{
+		ID:            "expired",
+		AgentID:       "3",
+		Type:          models.AgentCommandRunDiscovery,
+		DiscoveryType: "host_discovery",
+		CreatedAt:     time.Now().Add(-time.Hour),
+		ExpiresAt:     time.Now().Add(-time.Minute),
+	})
+
+	pending, err := suite.agentCommandsService.PickUpPending("3")
+
+	suite.NoError(err)
+	suite.Empty(pending)
+
+	expired, err := suite.agentCommandsService.GetByID("expired")
+
+	suite.NoError(err)
+	suite.Equal(models.AgentCommandExpired, expired.Status)
+
+	command, err := suite.agentCommandsService.QueueHostDiscovery("3", "host_discovery")
+
+	suite.NoError(err)
+	suite.NotEqual("expired", command.ID)
+}
+
+func (suite *AgentCommandsServiceTestSuite) TestAgentCommandsService_GetAllByAgentID() {
+	suite.tx.Create(&[]entities.AgentCommand{
+		{ID: "old", AgentID: "3", CreatedAt: time.Now().Add(-time.Hour), ExpiresAt: time.Now()},
+		{ID: "new", AgentID: "3", CreatedAt: time.Now(), ExpiresAt: time.Now()},
+		{ID: "other", AgentID: "1", CreatedAt: time.Now(), ExpiresAt: time.Now()},
+	})
+
+	commands, err := suite.agentCommandsService.GetAllByAgentID("3")
+
+	suite.NoError(err)
+	suite.Len(commands, 2)
+	suite.Equal("new", commands[0].ID)
+	suite.Equal("old", commands[1].ID)
+
+	_, err = suite.agentCommandsService.GetByID("unknown")
+
+	suite.ErrorIs(err, ErrAgentCommandNotFound)
+}
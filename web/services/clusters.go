@@ -24,6 +24,7 @@ type ClustersService interface {
 	GetAllTags() ([]string, error)
 	GetAllClustersSettings() (models.ClustersSettings, error)
 	GetClusterSettingsByID(id string) (*models.ClusterSettings, error)
+	GetHealthByID(id string) (*models.HealthState, error)
 }
 
 type ClustersFilter struct {
@@ -66,7 +67,7 @@ func (s *clustersService) GetAll(filter *ClustersFilter, page *Page) (models.Clu
 		}
 
 		if len(filter.SIDs) > 0 {
-			db = db.Where("sid IN (?)", filter.SIDs)
+			db = db.Where("sids && ?", pq.Array(filter.SIDs))
 		}
 
 		if len(filter.Tags) > 0 {
@@ -129,13 +130,59 @@ func (s *clustersService) GetByID(clusterID string) (*models.Cluster, error) {
 		s.enrichClusterNodes(detail.Nodes, cluster.ID, cluster.Hosts)
 		s.enrichCluster(clusterModel)
 		clusterModel.Details = detail
+	case models.ClusterTypeASCSERS:
+		var clusterDetailASCSERS entities.ASCSERSClusterDetails
+
+		err := json.Unmarshal(cluster.Details, &clusterDetailASCSERS)
+		if err != nil {
+			return nil, err
+		}
+
+		detail := clusterDetailASCSERS.ToModel()
+		s.enrichClusterNodes(detail.Nodes, cluster.ID, cluster.Hosts)
+		err = s.enrichASCSERSInstances(detail.Instances, detail.SID, cluster.Hosts)
+		if err != nil {
+			return nil, err
+		}
+		s.enrichCluster(clusterModel)
+		clusterModel.Details = detail
 	default:
-		clusterModel.Details = nil
+		if len(cluster.Details) == 0 {
+			clusterModel.Details = nil
+			break
+		}
+
+		var clusterDetailGeneric entities.GenericClusterDetails
+
+		err := json.Unmarshal(cluster.Details, &clusterDetailGeneric)
+		if err != nil {
+			return nil, err
+		}
+
+		detail := clusterDetailGeneric.ToModel()
+		s.enrichClusterNodes(detail.Nodes, cluster.ID, cluster.Hosts)
+		s.enrichCluster(clusterModel)
+		clusterModel.Details = detail
 	}
 
 	return clusterModel, nil
 }
 
+// GetHealthByID returns the health of a cluster along with its partial healths and their reasons
+func (s *clustersService) GetHealthByID(id string) (*models.HealthState, error) {
+	var healthState entities.HealthState
+
+	err := s.db.Where("id = ?", id).First(&healthState).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return healthState.ToModel()
+}
+
 func (s *clustersService) GetCount() (int, error) {
 	var count int64
 	err := s.db.Model(&entities.Cluster{}).Count(&count).Error
@@ -175,20 +222,19 @@ func (s *clustersService) GetAllClusterTypes() ([]string, error) {
 }
 
 func (s *clustersService) GetAllSIDs() ([]string, error) {
-	var sids pq.StringArray
+	var sids []string
 
-	err := s.db.Model(&entities.Cluster{}).
-		Distinct().
-		Where("sid IS NOT NULL AND sid <> ''").
-		Order("sid").
-		Pluck("sid", &sids).
+	// A cluster can manage multiple SAP systems, e.g. in a cost-optimized scenario
+	err := s.db.
+		Raw("SELECT DISTINCT unnest(sids) AS sid FROM clusters ORDER BY sid").
+		Scan(&sids).
 		Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	return []string(sids), nil
+	return sids, nil
 }
 
 func (s *clustersService) GetAllTags() ([]string, error) {
@@ -357,3 +403,49 @@ func (s *clustersService) enrichClusterNodes(nodes []*models.HANAClusterNode, cl
 		}
 	}
 }
+
+// enrichASCSERSInstances adds the host and the SAP HA interface state, as discovered on the cluster hosts, to the ASCS/ERS instances
+func (s *clustersService) enrichASCSERSInstances(instances []*models.ASCSERSInstance, sid string, hosts []*entities.Host) error {
+	var agentIDs []string
+	for _, host := range hosts {
+		agentIDs = append(agentIDs, host.AgentID)
+	}
+
+	var sapInstances []*entities.SAPSystemInstance
+	err := s.db.
+		Where("sid = ? AND agent_id IN (?)", sid, agentIDs).
+		Find(&sapInstances).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		for _, host := range hosts {
+			if instance.Node == host.Name {
+				instance.HostID = host.AgentID
+				break
+			}
+		}
+
+		for _, sapInstance := range sapInstances {
+			if sapInstance.InstanceNumber != instance.InstanceNumber {
+				continue
+			}
+
+			instance.HAConnector = &models.SAPHAConnector{
+				Active:              sapInstance.HAActive,
+				SAPInterfaceVersion: sapInstance.HASAPInterfaceVersion,
+				ChecksState:         sapInstance.HAChecksState,
+			}
+
+			// Prefer the data discovered on the node running the instance
+			if sapInstance.AgentID == instance.HostID {
+				break
+			}
+		}
+	}
+
+	return nil
+}
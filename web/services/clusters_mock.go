@@ -216,3 +216,26 @@ func (_m *MockClustersService) GetCount() (int, error) {
 
 	return r0, r1
 }
+
+// GetHealthByID provides a mock function with given fields: id
+func (_m *MockClustersService) GetHealthByID(id string) (*models.HealthState, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.HealthState
+	if rf, ok := ret.Get(0).(func(string) *models.HealthState); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.HealthState)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
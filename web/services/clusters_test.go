@@ -30,6 +30,7 @@ func (suite *ClustersServiceTestSuite) SetupSuite() {
 	suite.db.AutoMigrate(
 		entities.Cluster{}, entities.Host{}, models.Tag{}, models.SelectedChecks{},
 		models.ConnectionSettings{}, entities.ChecksResult{}, entities.HealthState{},
+		entities.SAPSystemInstance{},
 	)
 	loadClustersFixtures(suite.db)
 }
@@ -38,6 +39,7 @@ func (suite *ClustersServiceTestSuite) TearDownSuite() {
 	suite.db.Migrator().DropTable(
 		entities.Cluster{}, entities.Host{}, models.Tag{}, models.SelectedChecks{},
 		models.ConnectionSettings{}, entities.ChecksResult{}, entities.HealthState{},
+		entities.SAPSystemInstance{},
 	)
 }
 
@@ -66,7 +68,7 @@ func loadClustersFixtures(db *gorm.DB) {
 		ID:              "1",
 		Name:            "cluster1",
 		ClusterType:     models.ClusterTypeHANAScaleUp,
-		SID:             "DEV",
+		SIDs:            []string{"DEV"},
 		ResourcesNumber: 10,
 		HostsNumber:     2,
 		Tags: []*models.Tag{
@@ -92,7 +94,7 @@ func loadClustersFixtures(db *gorm.DB) {
 		ID:              "2",
 		Name:            "cluster2",
 		ClusterType:     models.ClusterTypeHANAScaleOut,
-		SID:             "QAS",
+		SIDs:            []string{"QAS"},
 		ResourcesNumber: 11,
 		HostsNumber:     2,
 		Tags: []*models.Tag{
@@ -121,7 +123,7 @@ func loadClustersFixtures(db *gorm.DB) {
 		ID:              "3",
 		Name:            "cluster3",
 		ClusterType:     models.ClusterTypeUnknown,
-		SID:             "PRD",
+		SIDs:            []string{"PRD", "QAS"},
 		ResourcesNumber: 3,
 		HostsNumber:     5,
 		Tags: []*models.Tag{
@@ -144,11 +146,13 @@ func loadClustersFixtures(db *gorm.DB) {
 		},
 	})
 
-	partialHealths1, _ := json.Marshal(map[string]string{"hana_sr_health": "passing"})
+	partialHealths1, _ := json.Marshal(map[string]string{"hana_sr_health": "passing", "sbd_health": "passing"})
+	reasons1, _ := json.Marshal(map[string]string{"sbd_health": "node host2 is missing from the slots of the SBD device /dev/sda"})
 	db.Create(&entities.HealthState{
 		ID:             "1",
 		Health:         "passing",
 		PartialHealths: partialHealths1,
+		Reasons:        reasons1,
 	})
 
 	partialHealths2, _ := json.Marshal(map[string]string{"hana_sr_health": "warning"})
@@ -178,7 +182,7 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetAll() {
 			ID:              "1",
 			Name:            "cluster1",
 			ClusterType:     models.ClusterTypeHANAScaleUp,
-			SID:             "DEV",
+			SIDs:            []string{"DEV"},
 			ResourcesNumber: 10,
 			HostsNumber:     2,
 			Health:          models.CheckPassing,
@@ -191,7 +195,7 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetAll() {
 			ID:              "2",
 			Name:            "cluster2",
 			ClusterType:     models.ClusterTypeHANAScaleOut,
-			SID:             "QAS",
+			SIDs:            []string{"QAS"},
 			ResourcesNumber: 11,
 			HostsNumber:     2,
 			Health:          models.CheckWarning,
@@ -204,7 +208,7 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetAll() {
 			ID:              "3",
 			Name:            "cluster3",
 			ClusterType:     models.ClusterTypeUnknown,
-			SID:             "PRD",
+			SIDs:            []string{"PRD", "QAS"},
 			ResourcesNumber: 3,
 			HostsNumber:     5,
 			Health:          models.CheckCritical,
@@ -270,6 +274,21 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetAll_Filter() {
 	suite.Equal(clusters[0].ID, "1")
 	suite.Equal([]string{"tag1"}, clusters[0].Tags)
 }
+
+func (suite *ClustersServiceTestSuite) TestClustersService_GetAll_FilterMultipleSIDs() {
+	suite.checksService.On("GetAggregatedChecksResultByCluster", "2").Return(&models.AggregatedCheckData{WarningCount: 1}, nil)
+	suite.checksService.On("GetAggregatedChecksResultByCluster", "3").Return(&models.AggregatedCheckData{CriticalCount: 1}, nil)
+
+	clusters, _ := suite.clustersService.GetAll(&ClustersFilter{
+		SIDs: []string{"QAS"},
+	}, nil)
+
+	suite.Equal(2, len(clusters))
+	suite.Equal("2", clusters[0].ID)
+	suite.Equal("3", clusters[1].ID)
+	suite.Equal([]string{"PRD", "QAS"}, clusters[1].SIDs)
+}
+
 func (suite *ClustersServiceTestSuite) TestClustersService_GetByID() {
 	suite.checksService.On("GetAggregatedChecksResultByCluster", "1").Return(&models.AggregatedCheckData{PassingCount: 1}, nil)
 	suite.checksService.On("GetAggregatedChecksResultByHost", "1").Return(map[string]*models.AggregatedCheckData{
@@ -291,6 +310,99 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetByID() {
 		},
 	}, cluster.Details.(*models.HANAClusterDetails))
 }
+func (suite *ClustersServiceTestSuite) TestClustersService_GetByID_ASCSERS() {
+	details, _ := json.Marshal(&entities.ASCSERSClusterDetails{
+		SID:         "NWP",
+		EnsaVersion: models.EnsaVersion2,
+		Instances: []*entities.ASCSERSInstance{
+			{
+				Role:           models.ASCSERSRoleASCS,
+				InstanceNumber: "00",
+				Node:           "host10",
+				Status:         "active",
+			},
+			{
+				Role:           models.ASCSERSRoleERS,
+				InstanceNumber: "10",
+				Node:           "host11",
+				Status:         "active",
+			},
+		},
+		Nodes: []*entities.HANAClusterNode{
+			{Name: "host10"},
+			{Name: "host11"},
+		},
+	})
+
+	suite.tx.Create(&entities.Cluster{
+		ID:          "10",
+		Name:        "netweaver_cluster",
+		ClusterType: models.ClusterTypeASCSERS,
+		SIDs:        []string{"NWP"},
+		Hosts: []*entities.Host{
+			{AgentID: "10", ClusterID: "10", Name: "host10"},
+			{AgentID: "11", ClusterID: "10", Name: "host11"},
+		},
+		Details: details,
+	})
+	suite.tx.Create(&entities.SAPSystemInstance{
+		ID:                    "nwp",
+		AgentID:               "11",
+		SID:                   "NWP",
+		InstanceNumber:        "00",
+		HAActive:              false,
+		HASAPInterfaceVersion: "stale",
+	})
+	suite.tx.Create(&entities.SAPSystemInstance{
+		ID:                    "nwp",
+		AgentID:               "10",
+		SID:                   "NWP",
+		InstanceNumber:        "00",
+		HAActive:              true,
+		HASAPInterfaceVersion: "sap_suse_cluster_connector 3.1.2",
+		HAChecksState:         "SAPControl-HA-SUCCESS",
+	})
+
+	suite.checksService.On("GetAggregatedChecksResultByCluster", "10").Return(&models.AggregatedCheckData{}, nil)
+	suite.checksService.On("GetAggregatedChecksResultByHost", "10").Return(map[string]*models.AggregatedCheckData{}, nil)
+
+	cluster, err := suite.clustersService.GetByID("10")
+
+	suite.NoError(err)
+	details10 := cluster.Details.(*models.ASCSERSClusterDetails)
+	suite.Equal(models.EnsaVersion2, details10.EnsaVersion)
+	suite.Equal("10", details10.Instances[0].HostID)
+	suite.Equal(&models.SAPHAConnector{
+		Active:              true,
+		SAPInterfaceVersion: "sap_suse_cluster_connector 3.1.2",
+		ChecksState:         "SAPControl-HA-SUCCESS",
+	}, details10.Instances[0].HAConnector)
+	suite.Equal("11", details10.Instances[1].HostID)
+	suite.Nil(details10.Instances[1].HAConnector)
+	suite.Equal("10", details10.Nodes[0].HostID)
+}
+
+func (suite *ClustersServiceTestSuite) TestClustersService_GetHealthByID() {
+	health, err := suite.clustersService.GetHealthByID("1")
+
+	suite.NoError(err)
+	suite.Equal(&models.HealthState{
+		Health: models.HealthSummaryHealthPassing,
+		PartialHealths: map[string]*models.PartialHealth{
+			"hana_sr_health": {Health: models.HealthSummaryHealthPassing},
+			"sbd_health": {
+				Health: models.HealthSummaryHealthPassing,
+				Reason: "node host2 is missing from the slots of the SBD device /dev/sda",
+			},
+		},
+	}, health)
+
+	health, err = suite.clustersService.GetHealthByID("not_there")
+
+	suite.NoError(err)
+	suite.Nil(health)
+}
+
 func (suite *ClustersServiceTestSuite) TestClustersService_GetByID_NotFound() {
 	cluster, err := suite.clustersService.GetByID("not_there")
 
@@ -324,7 +436,7 @@ func (suite *ClustersServiceTestSuite) TestClustersService_GetAllTags() {
 
 func (suite *ClustersServiceTestSuite) TestClustersService_GetAllSIDs() {
 	sids, _ := suite.clustersService.GetAllSIDs()
-	suite.ElementsMatch([]string{"DEV", "QAS", "PRD"}, sids)
+	suite.Equal([]string{"DEV", "PRD", "QAS"}, sids)
 }
 
 func (suite *ClustersServiceTestSuite) TestClustersService_GetAllClustersSettingsReturnsNoSettings() {
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/trento-project/trento/web/entities"
+	"github.com/trento-project/trento/web/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Size in bytes of the random enrollment tokens and agent secrets
+const secretSize = 32
+
+var (
+	ErrInvalidEnrollmentToken   = errors.New("the enrollment token is not valid, expired or already used")
+	ErrEnrollmentTokenNotFound  = errors.New("enrollment token not found")
+	ErrAgentCredentialRevoked   = errors.New("the agent credential was revoked")
+	ErrAgentCredentialNotFound  = errors.New("the agent is not enrolled")
+	ErrEnrollmentTokenLifecycle = errors.New("an enrollment token must be single use or expire")
+	ErrAgentAlreadyEnrolled     = errors.New("the agent is already enrolled, its credential must be reset to enroll it again")
+	ErrInvalidAgentID           = errors.New("the agent ID must be a UUID")
+)
+
+//go:generate mockery --name=EnrollmentService --inpackage --filename=enrollment_mock.go
+
+// EnrollmentService manages the enrollment tokens the agents exchange for their own credential,
+// used to authenticate them to the collector
+type EnrollmentService interface {
+	CreateToken(singleUse bool, expiresIn time.Duration) (*models.EnrollmentToken, error)
+	GetAllTokens() ([]*models.EnrollmentToken, error)
+	DeleteToken(id string) error
+	Enroll(token string, agentID string) (string, error)
+	Authenticate(agentID string, secret string) (bool, error)
+	GetAllAgentCredentials() ([]*models.AgentCredential, error)
+	Revoke(agentID string) error
+	Reset(agentID string) error
+}
+
+type enrollmentService struct {
+	db *gorm.DB
+}
+
+func NewEnrollmentService(db *gorm.DB) *enrollmentService {
+	return &enrollmentService{db: db}
+}
+
+// CreateToken creates a token usable only once, expiring after the given duration, or both.
+// A zero duration never expires
+func (s *enrollmentService) CreateToken(singleUse bool, expiresIn time.Duration) (*models.EnrollmentToken, error) {
+	if !singleUse && expiresIn <= 0 {
+		return nil, ErrEnrollmentTokenLifecycle
+	}
+
+	token, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	enrollmentToken := entities.EnrollmentToken{
+		ID:        uuid.New().String(),
+		TokenHash: hashSecret(token),
+		SingleUse: singleUse,
+	}
+	if expiresIn > 0 {
+		expiresAt := time.Now().Add(expiresIn)
+		enrollmentToken.ExpiresAt = &expiresAt
+	}
+
+	if err := s.db.Create(&enrollmentToken).Error; err != nil {
+		return nil, err
+	}
+
+	modeledToken := enrollmentToken.ToModel()
+	modeledToken.Token = token
+
+	return modeledToken, nil
+}
+
+func (s *enrollmentService) GetAllTokens() ([]*models.EnrollmentToken, error) {
+	var tokens []entities.EnrollmentToken
+	if err := s.db.Order("created_at").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	var modeledTokens []*models.EnrollmentToken
+	for _, t := range tokens {
+		modeledTokens = append(modeledTokens, t.ToModel())
+	}
+
+	return modeledTokens, nil
+}
+
+func (s *enrollmentService) DeleteToken(id string) error {
+	result := s.db.Delete(&entities.EnrollmentToken{}, "id", id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrEnrollmentTokenNotFound
+	}
+
+	return nil
+}
+
+// Enroll consumes the enrollment token and returns a new secret the agent authenticates with.
+// An agent already enrolled can't enroll again, otherwise anyone holding a token could take over its identity:
+// its credential must be reset first
+func (s *enrollmentService) Enroll(token string, agentID string) (string, error) {
+	if _, err := uuid.Parse(agentID); err != nil {
+		return "", ErrInvalidAgentID
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var enrollmentToken entities.EnrollmentToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash", hashSecret(token)).
+			First(&enrollmentToken).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidEnrollmentToken
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if enrollmentToken.ExpiresAt != nil && now.After(*enrollmentToken.ExpiresAt) {
+			return ErrInvalidEnrollmentToken
+		}
+		if enrollmentToken.SingleUse && enrollmentToken.UsedAt != nil {
+			return ErrInvalidEnrollmentToken
+		}
+
+		var credential entities.AgentCredential
+		err = tx.Where("agent_id", agentID).First(&credential).Error
+		switch {
+		case err == nil && credential.RevokedAt != nil:
+			return ErrAgentCredentialRevoked
+		case err == nil:
+			return ErrAgentAlreadyEnrolled
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		err = tx.Model(&enrollmentToken).Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		// Concurrent enrollments of the same agent fail on the primary key, the first one wins
+		err = tx.Create(&entities.AgentCredential{
+			AgentID:    agentID,
+			SecretHash: hashSecret(secret),
+			CreatedAt:  now,
+		}).Error
+		if err != nil {
+			return ErrAgentAlreadyEnrolled
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// Authenticate tells whether the secret belongs to an enrolled agent whose credential was not revoked
+func (s *enrollmentService) Authenticate(agentID string, secret string) (bool, error) {
+	var credential entities.AgentCredential
+	err := s.db.Where("agent_id", agentID).First(&credential).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if credential.RevokedAt != nil {
+		return false, nil
+	}
+
+	valid := subtle.ConstantTimeCompare([]byte(credential.SecretHash), []byte(hashSecret(secret))) == 1
+
+	return valid, nil
+}
+
+func (s *enrollmentService) GetAllAgentCredentials() ([]*models.AgentCredential, error) {
+	var credentials []entities.AgentCredential
+	if err := s.db.Order("agent_id").Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+
+	var modeledCredentials []*models.AgentCredential
+	for _, c := range credentials {
+		modeledCredentials = append(modeledCredentials, c.ToModel())
+	}
+
+	return modeledCredentials, nil
+}
+
+// Revoke prevents the agent from authenticating and enrolling again, until its credential is reset
+func (s *enrollmentService) Revoke(agentID string) error {
+	result := s.db.Model(&entities.AgentCredential{}).
+		Where("agent_id", agentID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrAgentCredentialNotFound
+	}
+
+	return nil
+}
+
+// Reset deletes the agent credential, revoked or not, so the agent can enroll again with a new token
+func (s *enrollmentService) Reset(agentID string) error {
+	result := s.db.Where("agent_id", agentID).Delete(&entities.AgentCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrAgentCredentialNotFound
+	}
+
+	return nil
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
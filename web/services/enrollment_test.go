@@ -0,0 +1,200 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/trento/test/helpers"
+	"github.com/trento-project/trento/web/entities"
+	"gorm.io/gorm"
+)
+
+const (
+	enrolledAgentID = "779cdd70-e9e2-58ca-b18a-bf3eb3f71244"
+	otherAgentID    = "9cd46919-5f19-59aa-993e-cf3736c71053"
+)
+
+type EnrollmentServiceTestSuite struct {
+	suite.Suite
+	db                *gorm.DB
+	tx                *gorm.DB
+	enrollmentService *enrollmentService
+}
+
+func TestEnrollmentServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(EnrollmentServiceTestSuite))
+}
+
+func (suite *EnrollmentServiceTestSuite) SetupSuite() {
+	suite.db = helpers.SetupTestDatabase(suite.T())
+
+	suite.db.AutoMigrate(&entities.EnrollmentToken{}, &entities.AgentCredential{})
+}
+
+func (suite *EnrollmentServiceTestSuite) TearDownSuite() {
+	suite.db.Migrator().DropTable(&entities.EnrollmentToken{}, &entities.AgentCredential{})
+}
+
+func (suite *EnrollmentServiceTestSuite) SetupTest() {
+	suite.tx = suite.db.Begin()
+	suite.enrollmentService = NewEnrollmentService(suite.tx)
+}
+
+func (suite *EnrollmentServiceTestSuite) TearDownTest() {
+	suite.tx.Rollback()
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_CreateToken() {
+	token, err := suite.enrollmentService.CreateToken(true, time.Hour)
+
+	suite.NoError(err)
+	suite.NotEmpty(token.Token)
+	suite.True(token.SingleUse)
+	suite.WithinDuration(time.Now().Add(time.Hour), *token.ExpiresAt, time.Minute)
+
+	tokens, err := suite.enrollmentService.GetAllTokens()
+
+	suite.NoError(err)
+	suite.Len(tokens, 1)
+	suite.Equal(token.ID, tokens[0].ID)
+	suite.Empty(tokens[0].Token)
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_CreateToken_NeverExpiring() {
+	_, err := suite.enrollmentService.CreateToken(false, 0)
+
+	suite.ErrorIs(err, ErrEnrollmentTokenLifecycle)
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_Enroll() {
+	token, _ := suite.enrollmentService.CreateToken(false, time.Hour)
+
+	secret, err := suite.enrollmentService.Enroll(token.Token, enrolledAgentID)
+	suite.NoError(err)
+
+	authenticated, err := suite.enrollmentService.Authenticate(enrolledAgentID, secret)
+	suite.NoError(err)
+	suite.True(authenticated)
+
+	authenticated, err = suite.enrollmentService.Authenticate(enrolledAgentID, "wrong_secret")
+	suite.NoError(err)
+	suite.False(authenticated)
+
+	authenticated, err = suite.enrollmentService.Authenticate(otherAgentID, secret)
+	suite.NoError(err)
+	suite.False(authenticated)
+
+	// Expiring tokens can be used by many agents
+	_, err = suite.enrollmentService.Enroll(token.Token, otherAgentID)
+	suite.NoError(err)
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_Enroll_AlreadyEnrolled() {
+	token, _ := suite.enrollmentService.CreateToken(true, 0)
+	secret, _ := suite.enrollmentService.Enroll(token.Token, enrolledAgentID)
+
+	otherToken, _ := suite.enrollmentService.CreateToken(true, 0)
+	_, err := suite.enrollmentService.Enroll(otherToken.Token, enrolledAgentID)
+	suite.ErrorIs(err, ErrAgentAlreadyEnrolled)
+
+	// The credential is kept and the token is not consumed
+	authenticated, err := suite.enrollmentService.Authenticate(enrolledAgentID, secret)
+	suite.NoError(err)
+	suite.True(authenticated)
+
+	_, err = suite.enrollmentService.Enroll(otherToken.Token, otherAgentID)
+	suite.NoError(err)
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_Enroll_InvalidAgentID() {
+	token, _ := suite.enrollmentService.CreateToken(true, 0)
+
+	_, err := suite.enrollmentService.Enroll(token.Token, "not_a_uuid")
+	suite.ErrorIs(err, ErrInvalidAgentID)
+
+	credentials, err := suite.enrollmentService.GetAllAgentCredentials()
+	suite.NoError(err)
+	suite.Empty(credentials)
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_Enroll_SingleUse() {
+	token, _ := suite.enrollmentService.CreateToken(true, 0)
+
+	_, err := suite.enrollmentService.Enroll(token.Token, enrolledAgentID)
+	suite.NoError(err)
+
+	_, err = suite.enrollmentService.Enroll(token.Token, otherAgentID)
+	suite.ErrorIs(err, ErrInvalidEnrollmentToken)
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_Enroll_Expired() {
+	expiresAt := time.Now().Add(-time.Minute)
+	suite.tx.Create(&entities.EnrollmentToken{
+		ID:        "expired",
+		TokenHash: hashSecret("expired_token"),
+		ExpiresAt: &expiresAt,
+	})
+
+	_, err := suite.enrollmentService.Enroll("expired_token", enrolledAgentID)
+	suite.ErrorIs(err, ErrInvalidEnrollmentToken)
+
+	_, err = suite.enrollmentService.Enroll("unknown_token", enrolledAgentID)
+	suite.ErrorIs(err, ErrInvalidEnrollmentToken)
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_Revoke() {
+	token, _ := suite.enrollmentService.CreateToken(false, time.Hour)
+	secret, _ := suite.enrollmentService.Enroll(token.Token, enrolledAgentID)
+
+	err := suite.enrollmentService.Revoke(enrolledAgentID)
+	suite.NoError(err)
+
+	authenticated, err := suite.enrollmentService.Authenticate(enrolledAgentID, secret)
+	suite.NoError(err)
+	suite.False(authenticated)
+
+	_, err = suite.enrollmentService.Enroll(token.Token, enrolledAgentID)
+	suite.ErrorIs(err, ErrAgentCredentialRevoked)
+
+	credentials, err := suite.enrollmentService.GetAllAgentCredentials()
+	suite.NoError(err)
+	suite.Len(credentials, 1)
+	suite.NotNil(credentials[0].RevokedAt)
+
+	err = suite.enrollmentService.Revoke("unknown_agent_id")
+	suite.ErrorIs(err, ErrAgentCredentialNotFound)
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_Reset() {
+	token, _ := suite.enrollmentService.CreateToken(false, time.Hour)
+	secret, _ := suite.enrollmentService.Enroll(token.Token, enrolledAgentID)
+	suite.enrollmentService.Revoke(enrolledAgentID)
+
+	err := suite.enrollmentService.Reset(enrolledAgentID)
+	suite.NoError(err)
+
+	authenticated, err := suite.enrollmentService.Authenticate(enrolledAgentID, secret)
+	suite.NoError(err)
+	suite.False(authenticated)
+
+	newSecret, err := suite.enrollmentService.Enroll(token.Token, enrolledAgentID)
+	suite.NoError(err)
+
+	authenticated, err = suite.enrollmentService.Authenticate(enrolledAgentID, newSecret)
+	suite.NoError(err)
+	suite.True(authenticated)
+
+	err = suite.enrollmentService.Reset(otherAgentID)
+	suite.ErrorIs(err, ErrAgentCredentialNotFound)
+}
+
+func (suite *EnrollmentServiceTestSuite) TestEnrollmentService_DeleteToken() {
+	token, _ := suite.enrollmentService.CreateToken(true, 0)
+
+	err := suite.enrollmentService.DeleteToken(token.ID)
+	suite.NoError(err)
+
+	err = suite.enrollmentService.DeleteToken(token.ID)
+	suite.ErrorIs(err, ErrEnrollmentTokenNotFound)
+}
@@ -49,7 +49,7 @@ func (s *healthSummaryService) GetHealthSummary() (models.HealthSummary, error)
 
 		clusters, err := s.clustersService.GetAll(&ClustersFilter{
 			ID:          clusterIDs,
-			ClusterType: []string{models.ClusterTypeHANAScaleUp},
+			ClusterType: []string{models.ClusterTypeHANAScaleUp, models.ClusterTypeASCSERS},
 		}, nil)
 		if err != nil {
 			return nil, err
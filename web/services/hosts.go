@@ -9,6 +9,7 @@ import (
 	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/trento/internal"
+	"github.com/trento-project/trento/internal/cloud"
 	"github.com/trento-project/trento/web/entities"
 	"github.com/trento-project/trento/web/models"
 	"gorm.io/gorm"
@@ -29,8 +30,9 @@ type HostsService interface {
 	GetCount() (int, error)
 	GetAllSIDs() ([]string, error)
 	GetAllTags() ([]string, error)
-	Heartbeat(agentID string) error
+	Heartbeat(agentID string, timestamp time.Time) error
 	GetExportersState(hostname string) (map[string]string, error)
+	GetIdentityConflicts() ([]*models.HostIdentityConflict, error)
 }
 
 type HostsFilter struct {
@@ -55,17 +57,17 @@ func (s *hostsService) GetAll(filter *HostsFilter, page *Page) (models.HostList,
 
 	// Filter the hosts by Health
 	if filter != nil && len(filter.Health) > 0 {
-		var heartbeats []entities.HostHeartbeat
+		var healthHosts []entities.Host
 
-		err := s.db.Find(&heartbeats).Error
+		err := s.db.Select("agent_id").Preload("Heartbeat").Preload("Health").Find(&healthHosts).Error
 		if err != nil {
 			return nil, err
 		}
 
-		for _, hearbeat := range heartbeats {
-			hearbeatHealth := computeHearbeatHealth(&hearbeat)
-			if internal.Contains(filter.Health, hearbeatHealth) {
-				healthFilteredHosts = append(healthFilteredHosts, hearbeat.AgentID)
+		for _, healthHost := range healthHosts {
+			hostHealth := computeHealth(&healthHost)
+			if internal.Contains(filter.Health, hostHealth) {
+				healthFilteredHosts = append(healthFilteredHosts, healthHost.AgentID)
 			}
 		}
 	}
@@ -75,8 +77,10 @@ func (s *hostsService) GetAll(filter *HostsFilter, page *Page) (models.HostList,
 		Scopes(Paginate(page)).
 		Preload("Tags").
 		Preload("Heartbeat").
+		Preload("Health").
 		Preload("SAPSystemInstances").
-		Preload("SAPSystemInstances.Host")
+		Preload("SAPSystemInstances.Host").
+		Preload("Identities", orderIdentities)
 
 	if filter != nil {
 		if len(filter.ID) > 0 {
@@ -84,9 +88,13 @@ func (s *hostsService) GetAll(filter *HostsFilter, page *Page) (models.HostList,
 		}
 
 		if len(filter.SIDs) > 0 {
-			db = db.Where("agent_id IN (?)", s.db.Model(&entities.SAPSystemInstance{}).
-				Select("agent_id").
-				Where("sid IN ?", filter.SIDs),
+			db = db.Where("agent_id IN (?) OR cluster_id IN (?)",
+				s.db.Model(&entities.SAPSystemInstance{}).
+					Select("agent_id").
+					Where("sid IN ?", filter.SIDs),
+				s.db.Model(&entities.Cluster{}).
+					Select("id").
+					Where("sids && ?", pq.Array(filter.SIDs)),
 			)
 		}
 
@@ -123,7 +131,13 @@ func (s *hostsService) GetByID(id string) (*models.Host, error) {
 	err := s.db.
 		Where("agent_id = ?", id).
 		Preload("Heartbeat").
+		Preload("Health").
 		Preload("SAPSystemInstances").
+		Preload("SystemdUnits", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name")
+		}).
+		Preload("Tuning").
+		Preload("Identities", orderIdentities).
 		First(&host).
 		Error
 
@@ -134,25 +148,65 @@ func (s *hostsService) GetByID(id string) (*models.Host, error) {
 		return nil, err
 	}
 
-	hostHealth := computeHealth(&host)
 	modeledHost := host.ToModel()
-	modeledHost.Health = hostHealth
+	modeledHost.Health = computeHealth(&host)
+	modeledHost.AgentHealth = computeHearbeatHealth(host.Heartbeat)
 
-	if modeledHost.CloudProvider == "azure" {
+	switch modeledHost.CloudProvider {
+	case cloud.Azure:
 		var cloudData models.AzureCloudData
 		json.Unmarshal(host.CloudData, &cloudData)
 		modeledHost.CloudData = cloudData
+	case cloud.Aws:
+		var cloudData models.AWSCloudData
+		json.Unmarshal(host.CloudData, &cloudData)
+		modeledHost.CloudData = cloudData
+	case cloud.Gcp:
+		var cloudData models.GCPCloudData
+		json.Unmarshal(host.CloudData, &cloudData)
+		modeledHost.CloudData = cloudData
 	}
 
 	return modeledHost, nil
 }
 
+// GetIdentityConflicts returns the agent IDs reported by different hosts within the identity conflict window
+func (s *hostsService) GetIdentityConflicts() ([]*models.HostIdentityConflict, error) {
+	var hosts []entities.Host
+
+	err := s.db.
+		Where("identity_conflict_at > ?", time.Now().Add(-entities.IdentityConflictWindow)).
+		Preload("Identities", orderIdentities).
+		Order("identity_conflict_at DESC").
+		Find(&hosts).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []*models.HostIdentityConflict{}
+	for _, h := range hosts {
+		conflict := h.ToModel().IdentityConflict
+		if conflict != nil {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	return conflicts, nil
+}
+
+func orderIdentities(db *gorm.DB) *gorm.DB {
+	return db.Order("last_reported_at DESC")
+}
+
 func (s *hostsService) GetAllBySAPSystemID(id string) (models.HostList, error) {
 	var hosts []entities.Host
 
 	err := s.db.
 		Order("name").
 		Preload("Heartbeat").
+		Preload("Health").
 		Preload("SAPSystemInstances").
 		Joins("JOIN sap_system_instances ON sap_system_instances.agent_id = hosts.agent_id").
 		Where("sap_system_instances.id = ?", id).
@@ -182,21 +236,25 @@ func (s *hostsService) GetCount() (int, error) {
 }
 
 func (s *hostsService) GetAllSIDs() ([]string, error) {
-	var sids pq.StringArray
+	var sids []string
 
+	// The SIDs of a host are the ones of its SAP instances and the ones managed by its cluster
 	err := s.db.
-		Model(&entities.Host{}).
-		Order("sap_system_instances.sid").
-		Joins("JOIN sap_system_instances ON sap_system_instances.agent_id = hosts.agent_id AND sid IS NOT NULL").
-		Distinct().
-		Pluck("sap_system_instances.sid", &sids).
+		Raw(`SELECT sid FROM (
+			SELECT sap_system_instances.sid AS sid FROM hosts
+			JOIN sap_system_instances ON sap_system_instances.agent_id = hosts.agent_id AND sid IS NOT NULL
+			UNION
+			SELECT unnest(clusters.sids) AS sid FROM hosts
+			JOIN clusters ON clusters.id = hosts.cluster_id
+		) AS host_sids ORDER BY sid`).
+		Scan(&sids).
 		Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	return []string(sids), nil
+	return sids, nil
 }
 
 func (s *hostsService) GetAllTags() ([]string, error) {
@@ -217,16 +275,26 @@ func (s *hostsService) GetAllTags() ([]string, error) {
 	return tags, nil
 }
 
-func (s *hostsService) Heartbeat(agentID string) error {
+// Heartbeat records a heartbeat of the agent happened at the given time.
+// Heartbeats replayed by the agent never move the last heartbeat back in time,
+// and a heartbeat in the future counts as now, so that the host doesn't look alive after the agent stopped
+func (s *hostsService) Heartbeat(agentID string, timestamp time.Time) error {
+	if now := time.Now(); timestamp.After(now) {
+		timestamp = now
+	}
+
 	heartbeat := &entities.HostHeartbeat{
-		AgentID: agentID,
+		AgentID:   agentID,
+		UpdatedAt: timestamp,
 	}
 
 	return s.db.Clauses(clause.OnConflict{
 		Columns: []clause.Column{
 			{Name: "agent_id"},
 		},
-		DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"updated_at": gorm.Expr("GREATEST(host_heartbeats.updated_at, excluded.updated_at)"),
+		}),
 	}).Create(heartbeat).Error
 }
 
@@ -268,8 +336,22 @@ func (s *hostsService) GetExportersState(hostname string) (map[string]string, er
 	return jobsState, nil
 }
 
+// computeHealth combines the heartbeat health with the health projected from the discoveries,
+// e.g. a host receiving heartbeats is in warning if it has pending scheduled events
 func computeHealth(host *entities.Host) string {
-	return computeHearbeatHealth(host.Heartbeat)
+	health := computeHearbeatHealth(host.Heartbeat)
+	if health != models.HostHealthPassing || host.Health == nil {
+		return health
+	}
+
+	switch host.Health.Health {
+	case models.HealthSummaryHealthCritical:
+		return models.HostHealthCritical
+	case models.HealthSummaryHealthWarning:
+		return models.HostHealthWarning
+	default:
+		return health
+	}
 }
 
 func computeHearbeatHealth(hearbeat *entities.HostHeartbeat) string {
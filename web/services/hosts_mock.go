@@ -5,6 +5,8 @@ package services
 import (
 	mock "github.com/stretchr/testify/mock"
 	models "github.com/trento-project/trento/web/models"
+
+	time "time"
 )
 
 // MockHostsService is an autogenerated mock type for the HostsService type
@@ -171,13 +173,36 @@ func (_m *MockHostsService) GetExportersState(hostname string) (map[string]strin
 	return r0, r1
 }
 
-// Heartbeat provides a mock function with given fields: agentID
-func (_m *MockHostsService) Heartbeat(agentID string) error {
-	ret := _m.Called(agentID)
+// GetIdentityConflicts provides a mock function with given fields:
+func (_m *MockHostsService) GetIdentityConflicts() ([]*models.HostIdentityConflict, error) {
+	ret := _m.Called()
+
+	var r0 []*models.HostIdentityConflict
+	if rf, ok := ret.Get(0).(func() []*models.HostIdentityConflict); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.HostIdentityConflict)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Heartbeat provides a mock function with given fields: agentID, timestamp
+func (_m *MockHostsService) Heartbeat(agentID string, timestamp time.Time) error {
+	ret := _m.Called(agentID, timestamp)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(agentID)
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(agentID, timestamp)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -89,17 +89,31 @@ func TestHostsServiceTestSuite(t *testing.T) {
 func (suite *HostsServiceTestSuite) SetupSuite() {
 	suite.db = helpers.SetupTestDatabase(suite.T())
 
-	suite.db.AutoMigrate(&entities.Host{}, &entities.HostHeartbeat{}, &entities.SAPSystemInstance{}, &models.Tag{})
+	suite.db.AutoMigrate(&entities.Host{}, &entities.HostHeartbeat{}, &entities.SAPSystemInstance{}, &entities.HealthState{},
+		&entities.SystemdUnit{}, &entities.HostTuning{}, &models.Tag{}, &entities.Cluster{}, &entities.HostIdentity{})
 	hosts := hostsFixtures()
 	err := suite.db.Create(&hosts).Error
 	suite.NoError(err)
+
+	// cost-optimized cluster running a non-replicated PRD system besides the QAS instances
+	err = suite.db.Create(&entities.Cluster{
+		ID:   "cluster_id_2",
+		Name: "cluster_2",
+		SIDs: pq.StringArray{"PRD", "QAS"},
+	}).Error
+	suite.NoError(err)
 }
 
 func (suite *HostsServiceTestSuite) TearDownSuite() {
 	suite.db.Migrator().DropTable(&entities.Host{},
 		&entities.HostHeartbeat{},
 		&entities.SAPSystemInstance{},
-		&models.Tag{})
+		&entities.HealthState{},
+		&entities.SystemdUnit{},
+		&entities.HostTuning{},
+		&models.Tag{},
+		&entities.Cluster{},
+		&entities.HostIdentity{})
 }
 
 func (suite *HostsServiceTestSuite) SetupTest() {
@@ -196,6 +210,18 @@ func (suite *HostsServiceTestSuite) TestHostsService_GetAll_Filters() {
 	suite.Equal("1", hosts[0].ID)
 }
 
+func (suite *HostsServiceTestSuite) TestHostsService_GetAll_FilterClusterSIDs() {
+	timeSince = func(_ time.Time) time.Duration {
+		return time.Duration(0)
+	}
+
+	hosts, _ := suite.hostsService.GetAll(&HostsFilter{
+		SIDs: []string{"PRD"},
+	}, nil)
+	suite.Equal(1, len(hosts))
+	suite.Equal("2", hosts[0].ID)
+}
+
 func (suite *HostsServiceTestSuite) TestHostsService_GetByID() {
 	host, _ := suite.hostsService.GetByID("1")
 	suite.Equal("host1", host.Name)
@@ -207,6 +233,41 @@ func (suite *HostsServiceTestSuite) TestHostsService_GetByID_NotFound() {
 	suite.Nil(host)
 }
 
+func (suite *HostsServiceTestSuite) TestHostsService_GetIdentityConflicts() {
+	detectedAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Microsecond)
+
+	err := suite.tx.Model(&entities.Host{}).Where("agent_id = ?", "1").Update("identity_conflict_at", detectedAt).Error
+	suite.NoError(err)
+	err = suite.tx.Model(&entities.Host{}).Where("agent_id = ?", "2").
+		Update("identity_conflict_at", time.Now().Add(-2*entities.IdentityConflictWindow)).Error
+	suite.NoError(err)
+	err = suite.tx.Create([]*entities.HostIdentity{
+		{AgentID: "1", Identity: "host1/10.74.1.5", Hostname: "host1",
+			IPAddresses: pq.StringArray{"10.74.1.5"}, LastReportedAt: detectedAt},
+		{AgentID: "1", Identity: "host1-clone/10.74.1.6", Hostname: "host1-clone",
+			IPAddresses: pq.StringArray{"10.74.1.6"}, LastReportedAt: detectedAt.Add(-time.Minute)},
+	}).Error
+	suite.NoError(err)
+
+	conflicts, err := suite.hostsService.GetIdentityConflicts()
+	suite.NoError(err)
+
+	suite.Equal(1, len(conflicts))
+	suite.Equal("1", conflicts[0].AgentID)
+	suite.True(detectedAt.Equal(conflicts[0].DetectedAt))
+	suite.Equal(2, len(conflicts[0].Identities))
+	suite.Equal("host1", conflicts[0].Identities[0].Hostname)
+	suite.Equal("host1-clone", conflicts[0].Identities[1].Hostname)
+
+	host, err := suite.hostsService.GetByID("1")
+	suite.NoError(err)
+	suite.NotNil(host.IdentityConflict)
+
+	host, err = suite.hostsService.GetByID("2")
+	suite.NoError(err)
+	suite.Nil(host.IdentityConflict)
+}
+
 func (suite *HostsServiceTestSuite) TestHostsService_GetAllBySAPSystemID() {
 	hosts, _ := suite.hostsService.GetAllBySAPSystemID("sap_system_id_2")
 	suite.Equal(1, len(hosts))
@@ -227,11 +288,11 @@ func (suite *HostsServiceTestSuite) TestHostsService_GetAllTags() {
 
 func (suite *HostsServiceTestSuite) TestHostsService_GetAllSIDs() {
 	hosts, _ := suite.hostsService.GetAllSIDs()
-	suite.ElementsMatch([]string{"DEV", "QAS"}, hosts)
+	suite.Equal([]string{"DEV", "PRD", "QAS"}, hosts)
 }
 
 func (suite *HostsServiceTestSuite) TestHostsService_Heartbeat() {
-	err := suite.hostsService.Heartbeat("1")
+	err := suite.hostsService.Heartbeat("1", time.Now())
 	suite.NoError(err)
 
 	var heartbeat entities.HostHeartbeat
@@ -239,6 +300,28 @@ func (suite *HostsServiceTestSuite) TestHostsService_Heartbeat() {
 	suite.Equal("1", heartbeat.AgentID)
 }
 
+func (suite *HostsServiceTestSuite) TestHostsService_HeartbeatReplayed() {
+	latest := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	err := suite.hostsService.Heartbeat("1", latest)
+	suite.NoError(err)
+	err = suite.hostsService.Heartbeat("1", latest.Add(-time.Minute))
+	suite.NoError(err)
+
+	var heartbeat entities.HostHeartbeat
+	suite.tx.First(&heartbeat)
+	suite.True(latest.Equal(heartbeat.UpdatedAt))
+}
+
+func (suite *HostsServiceTestSuite) TestHostsService_HeartbeatInTheFuture() {
+	err := suite.hostsService.Heartbeat("1", time.Now().Add(time.Hour))
+	suite.NoError(err)
+
+	var heartbeat entities.HostHeartbeat
+	suite.tx.First(&heartbeat)
+	suite.False(heartbeat.UpdatedAt.After(time.Now()))
+}
+
 func (suite *HostsServiceTestSuite) TestHostsService_computeHealth() {
 	host := hostsFixtures()[0]
 
@@ -256,6 +339,24 @@ func (suite *HostsServiceTestSuite) TestHostsService_computeHealth() {
 	suite.Equal(models.HostHealthUnknown, computeHealth(&host))
 }
 
+func (suite *HostsServiceTestSuite) TestHostsService_computeHealthWithProjectedHealth() {
+	host := hostsFixtures()[0]
+	host.Health = &entities.HealthState{
+		ID:     host.AgentID,
+		Health: models.HealthSummaryHealthWarning,
+	}
+
+	timeSince = func(_ time.Time) time.Duration {
+		return time.Duration(0)
+	}
+	suite.Equal(models.HostHealthWarning, computeHealth(&host))
+
+	timeSince = func(_ time.Time) time.Duration {
+		return time.Duration(HeartbeatTreshold + 1)
+	}
+	suite.Equal(models.HostHealthCritical, computeHealth(&host))
+}
+
 func (suite *HostsServiceTestSuite) TestHostsService_GetExportersState() {
 	exporterStates := prometheusModel.Vector{
 		&prometheusModel.Sample{